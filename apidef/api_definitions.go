@@ -31,6 +31,7 @@ type IdExtractorSource string
 type IdExtractorType string
 type AuthTypeEnum string
 type RoutingTriggerOnType string
+type AuthCompositionMode string
 
 const (
 	NoAction EndpointMethodAction = "no_action"
@@ -52,10 +53,12 @@ const (
 	HeaderSource      IdExtractorSource = "header"
 	QuerystringSource IdExtractorSource = "querystring"
 	FormSource        IdExtractorSource = "form"
+	CookieSource      IdExtractorSource = "cookie"
 
-	ValueExtractor IdExtractorType = "value"
-	XPathExtractor IdExtractorType = "xpath"
-	RegexExtractor IdExtractorType = "regex"
+	ValueExtractor    IdExtractorType = "value"
+	XPathExtractor    IdExtractorType = "xpath"
+	RegexExtractor    IdExtractorType = "regex"
+	JWTClaimExtractor IdExtractorType = "jwtclaim"
 
 	// For multi-type auth
 	AuthToken     AuthTypeEnum = "auth_token"
@@ -64,8 +67,14 @@ const (
 	JWTClaim      AuthTypeEnum = "jwt_claim"
 	OIDCUser      AuthTypeEnum = "oidc_user"
 	OAuthKey      AuthTypeEnum = "oauth_key"
+	MutualTLS     AuthTypeEnum = "mutual_tls"
+	TrustedHeader AuthTypeEnum = "trusted_header"
 	UnsetAuth     AuthTypeEnum = ""
 
+	// For auth composition
+	CompositionAND AuthCompositionMode = "and"
+	CompositionOR  AuthCompositionMode = "or"
+
 	// For routing triggers
 	All    RoutingTriggerOnType = "all"
 	Any    RoutingTriggerOnType = "any"
@@ -88,6 +97,21 @@ type CacheMeta struct {
 	Method        string `bson:"method" json:"method"`
 	Path          string `bson:"path" json:"path"`
 	CacheKeyRegex string `bson:"cache_key_regex" json:"cache_key_regex"`
+	// CacheTTL overrides CacheOptions.CacheTimeout for this endpoint when
+	// set to a positive value, e.g. so an expensive virtual endpoint can be
+	// cached for longer than the API's default.
+	CacheTTL int64 `bson:"cache_ttl" json:"cache_ttl"`
+	// CacheByFields lists the JSON body fields (dot notation), or query
+	// parameter names when CacheFieldsSource is "query", to build the
+	// cache key from instead of the raw request body/query string. The
+	// listed fields are extracted, sorted by name, and combined, so
+	// requests that only differ in field order or in fields that aren't
+	// listed here still share a cache entry. Ignored when empty.
+	CacheByFields []string `bson:"cache_by_fields" json:"cache_by_fields"`
+	// CacheFieldsSource selects where CacheByFields values are read from:
+	// "body" (default) parses the request body as JSON, "query" reads URL
+	// query parameters.
+	CacheFieldsSource string `bson:"cache_fields_source" json:"cache_fields_source"`
 }
 
 type RequestInputType string
@@ -111,6 +135,15 @@ type TransformJQMeta struct {
 	Method string `bson:"method" json:"method"`
 }
 
+// TransformJSONataMeta describes a JSONata expression to run against the
+// request or response body, mirroring TransformJQMeta so integration teams
+// that author their mapping specs in JSONata can pick it per path.
+type TransformJSONataMeta struct {
+	Expression string `bson:"expression" json:"expression"`
+	Path       string `bson:"path" json:"path"`
+	Method     string `bson:"method" json:"method"`
+}
+
 type HeaderInjectionMeta struct {
 	DeleteHeaders []string          `bson:"delete_headers" json:"delete_headers"`
 	AddHeaders    map[string]string `bson:"add_headers" json:"add_headers"`
@@ -119,6 +152,46 @@ type HeaderInjectionMeta struct {
 	ActOnResponse bool              `bson:"act_on" json:"act_on"`
 }
 
+// ResponseTransformStepType selects which transform a ResponseTransformStep
+// runs.
+type ResponseTransformStepType string
+
+const (
+	ResponseTransformStepTemplate ResponseTransformStepType = "template"
+	ResponseTransformStepJQ       ResponseTransformStepType = "jq"
+	ResponseTransformStepJSONata  ResponseTransformStepType = "jsonata"
+	ResponseTransformStepHeader   ResponseTransformStepType = "header"
+)
+
+// ResponseTransformStep is one stage of a ResponseTransformChainMeta. Steps
+// run in the order they're declared, each one working on the body/headers
+// left behind by the step before it, instead of a path only being able to
+// have one entry per transform type.
+type ResponseTransformStep struct {
+	Type ResponseTransformStepType `bson:"type" json:"type"`
+
+	// TemplateData is used when Type is "template".
+	TemplateData TemplateData `bson:"template_data,omitempty" json:"template_data,omitempty"`
+	// JQFilter is used when Type is "jq".
+	JQFilter string `bson:"jq_filter,omitempty" json:"jq_filter,omitempty"`
+	// JSONataExpression is used when Type is "jsonata".
+	JSONataExpression string `bson:"jsonata_expression,omitempty" json:"jsonata_expression,omitempty"`
+	// DeleteHeaders and AddHeaders are used when Type is "header".
+	DeleteHeaders []string          `bson:"delete_headers,omitempty" json:"delete_headers,omitempty"`
+	AddHeaders    map[string]string `bson:"add_headers,omitempty" json:"add_headers,omitempty"`
+}
+
+// ResponseTransformChainMeta runs an ordered list of response transforms
+// against a single path - e.g. a JQ filter, then a template, then a header
+// rewrite - so they can be composed instead of having to cram everything
+// into one TemplateMeta because only one entry per transform type can match
+// a given path.
+type ResponseTransformChainMeta struct {
+	Steps  []ResponseTransformStep `bson:"steps" json:"steps"`
+	Path   string                  `bson:"path" json:"path"`
+	Method string                  `bson:"method" json:"method"`
+}
+
 type HardTimeoutMeta struct {
 	Path    string `bson:"path" json:"path"`
 	Method  string `bson:"method" json:"method"`
@@ -135,6 +208,14 @@ type InternalMeta struct {
 	Method string `bson:"method" json:"method"`
 }
 
+// AllowedMethodsMeta restricts a path to a fixed set of HTTP methods,
+// returning a 405 with an Allow header for anything else, instead of
+// requiring a white/black list entry per disallowed method.
+type AllowedMethodsMeta struct {
+	Path    string   `bson:"path" json:"path"`
+	Methods []string `bson:"methods" json:"methods"`
+}
+
 type RequestSizeMeta struct {
 	Path      string `bson:"path" json:"path"`
 	Method    string `bson:"method" json:"method"`
@@ -147,6 +228,35 @@ type CircuitBreakerMeta struct {
 	ThresholdPercent     float64 `bson:"threshold_percent" json:"threshold_percent"`
 	Samples              int64   `bson:"samples" json:"samples"`
 	ReturnToServiceAfter int     `bson:"return_to_service_after" json:"return_to_service_after"`
+	// ReturnToServiceJitterPercent adds up to this percentage of random
+	// jitter to ReturnToServiceAfter, so many gateway nodes that tripped
+	// at the same time don't all half-open probe the target simultaneously.
+	ReturnToServiceJitterPercent int `bson:"return_to_service_jitter_percent" json:"return_to_service_jitter_percent"`
+	// LatencyThresholdMs, when set, counts a response slower than this as
+	// a breaker failure even if its status code was successful.
+	LatencyThresholdMs int64 `bson:"latency_threshold_ms" json:"latency_threshold_ms"`
+	// PerTargetBreaker maintains a separate breaker per upstream host when
+	// load balancing, so one bad target is ejected on its own instead of
+	// tripping the breaker for every target on the path.
+	PerTargetBreaker bool `bson:"per_target_breaker" json:"per_target_breaker"`
+	// FallbackResponse configures what the gateway returns to the client
+	// while this path's breaker is open, instead of a bare 503.
+	FallbackResponse CircuitBreakerFallback `bson:"fallback_response" json:"fallback_response"`
+}
+
+// CircuitBreakerFallback describes the response to serve while a circuit
+// breaker is open, so browser-facing APIs can degrade gracefully instead
+// of surfacing a raw 503 to the end user.
+type CircuitBreakerFallback struct {
+	// Mode selects the fallback behaviour: "static" returns Body/StatusCode
+	// as-is, "cache" replays the last successful upstream response seen for
+	// this target, and "redirect" sends the client to RedirectURL. Any
+	// other value (including empty) keeps the default bare 503.
+	Mode        string `bson:"mode" json:"mode"`
+	StatusCode  int    `bson:"status_code" json:"status_code"`
+	Body        string `bson:"body" json:"body"`
+	ContentType string `bson:"content_type" json:"content_type"`
+	RedirectURL string `bson:"redirect_url" json:"redirect_url"`
 }
 
 type StringRegexMap struct {
@@ -203,30 +313,217 @@ type ValidatePathMeta struct {
 	SchemaCache gojsonschema.JSONLoader `bson:"-" json:"-"`
 	// Allows override of default 422 Unprocessible Entity response code for validation errors.
 	ErrorResponseCode int `bson:"error_response_code" json:"error_response_code"`
+	// CoerceNumericStrings, when true, converts string values in the
+	// request body to numbers before validation, so clients that only
+	// send string-typed fields don't fail schema checks on numeric ones.
+	CoerceNumericStrings bool `bson:"coerce_numeric_strings" json:"coerce_numeric_strings"`
+	// CoerceBooleanStrings does the same coercion for "true"/"false"
+	// strings against boolean fields.
+	CoerceBooleanStrings bool `bson:"coerce_boolean_strings" json:"coerce_boolean_strings"`
 }
 
 type ExtendedPathsSet struct {
-	Ignored                 []EndPointMeta        `bson:"ignored" json:"ignored,omitempty"`
-	WhiteList               []EndPointMeta        `bson:"white_list" json:"white_list,omitempty"`
-	BlackList               []EndPointMeta        `bson:"black_list" json:"black_list,omitempty"`
-	Cached                  []string              `bson:"cache" json:"cache,omitempty"`
-	AdvanceCacheConfig      []CacheMeta           `bson:"advance_cache_config" json:"advance_cache_config,omitempty"`
-	Transform               []TemplateMeta        `bson:"transform" json:"transform,omitempty"`
-	TransformResponse       []TemplateMeta        `bson:"transform_response" json:"transform_response,omitempty"`
-	TransformJQ             []TransformJQMeta     `bson:"transform_jq" json:"transform_jq,omitempty"`
-	TransformJQResponse     []TransformJQMeta     `bson:"transform_jq_response" json:"transform_jq_response,omitempty"`
-	TransformHeader         []HeaderInjectionMeta `bson:"transform_headers" json:"transform_headers,omitempty"`
-	TransformResponseHeader []HeaderInjectionMeta `bson:"transform_response_headers" json:"transform_response_headers,omitempty"`
-	HardTimeouts            []HardTimeoutMeta     `bson:"hard_timeouts" json:"hard_timeouts,omitempty"`
-	CircuitBreaker          []CircuitBreakerMeta  `bson:"circuit_breakers" json:"circuit_breakers,omitempty"`
-	URLRewrite              []URLRewriteMeta      `bson:"url_rewrites" json:"url_rewrites,omitempty"`
-	Virtual                 []VirtualMeta         `bson:"virtual" json:"virtual,omitempty"`
-	SizeLimit               []RequestSizeMeta     `bson:"size_limits" json:"size_limits,omitempty"`
-	MethodTransforms        []MethodTransformMeta `bson:"method_transforms" json:"method_transforms,omitempty"`
-	TrackEndpoints          []TrackEndpointMeta   `bson:"track_endpoints" json:"track_endpoints,omitempty"`
-	DoNotTrackEndpoints     []TrackEndpointMeta   `bson:"do_not_track_endpoints" json:"do_not_track_endpoints,omitempty"`
-	ValidateJSON            []ValidatePathMeta    `bson:"validate_json" json:"validate_json,omitempty"`
-	Internal                []InternalMeta        `bson:"internal" json:"internal,omitempty"`
+	Ignored                       []EndPointMeta               `bson:"ignored" json:"ignored,omitempty"`
+	WhiteList                     []EndPointMeta               `bson:"white_list" json:"white_list,omitempty"`
+	BlackList                     []EndPointMeta               `bson:"black_list" json:"black_list,omitempty"`
+	Cached                        []string                     `bson:"cache" json:"cache,omitempty"`
+	AdvanceCacheConfig            []CacheMeta                  `bson:"advance_cache_config" json:"advance_cache_config,omitempty"`
+	Transform                     []TemplateMeta               `bson:"transform" json:"transform,omitempty"`
+	TransformResponse             []TemplateMeta               `bson:"transform_response" json:"transform_response,omitempty"`
+	TransformJQ                   []TransformJQMeta            `bson:"transform_jq" json:"transform_jq,omitempty"`
+	TransformJQResponse           []TransformJQMeta            `bson:"transform_jq_response" json:"transform_jq_response,omitempty"`
+	TransformJSONata              []TransformJSONataMeta       `bson:"transform_jsonata" json:"transform_jsonata,omitempty"`
+	TransformJSONataResponse      []TransformJSONataMeta       `bson:"transform_jsonata_response" json:"transform_jsonata_response,omitempty"`
+	TransformHeader               []HeaderInjectionMeta        `bson:"transform_headers" json:"transform_headers,omitempty"`
+	TransformResponseHeader       []HeaderInjectionMeta        `bson:"transform_response_headers" json:"transform_response_headers,omitempty"`
+	TransformResponseChain        []ResponseTransformChainMeta `bson:"transform_response_chain" json:"transform_response_chain,omitempty"`
+	HardTimeouts                  []HardTimeoutMeta            `bson:"hard_timeouts" json:"hard_timeouts,omitempty"`
+	CircuitBreaker                []CircuitBreakerMeta         `bson:"circuit_breakers" json:"circuit_breakers,omitempty"`
+	URLRewrite                    []URLRewriteMeta             `bson:"url_rewrites" json:"url_rewrites,omitempty"`
+	Virtual                       []VirtualMeta                `bson:"virtual" json:"virtual,omitempty"`
+	SizeLimit                     []RequestSizeMeta            `bson:"size_limits" json:"size_limits,omitempty"`
+	MethodTransforms              []MethodTransformMeta        `bson:"method_transforms" json:"method_transforms,omitempty"`
+	TrackEndpoints                []TrackEndpointMeta          `bson:"track_endpoints" json:"track_endpoints,omitempty"`
+	DoNotTrackEndpoints           []TrackEndpointMeta          `bson:"do_not_track_endpoints" json:"do_not_track_endpoints,omitempty"`
+	ValidateJSON                  []ValidatePathMeta           `bson:"validate_json" json:"validate_json,omitempty"`
+	Internal                      []InternalMeta               `bson:"internal" json:"internal,omitempty"`
+	AllowedMethods                []AllowedMethodsMeta         `bson:"allowed_methods" json:"allowed_methods,omitempty"`
+	XMLThreatProtection           []XMLThreatProtectionMeta    `bson:"xml_threat_protection" json:"xml_threat_protection,omitempty"`
+	TypedPathParams               []TypedPathParamsMeta        `bson:"typed_path_params" json:"typed_path_params,omitempty"`
+	ContentTypeConversion         []ContentTypeConversionMeta  `bson:"content_type_conversion" json:"content_type_conversion,omitempty"`
+	ContentTypeConversionResponse []ContentTypeConversionMeta  `bson:"content_type_conversion_response" json:"content_type_conversion_response,omitempty"`
+	RateLimit                     []EndpointRateLimitMeta      `bson:"rate_limit" json:"rate_limit,omitempty"`
+	PaginationLimits              []PaginationLimitMeta        `bson:"pagination_limits" json:"pagination_limits,omitempty"`
+}
+
+// EndpointRateLimitMeta declares a rate/quota limit that applies to a
+// single path/method, on top of (not instead of) whatever the key/API
+// level RateLimitAndQuotaCheck already enforces. It's tracked under its
+// own composite Redis key, so hitting an endpoint limit never touches the
+// key's overall rate or quota allowance.
+type EndpointRateLimitMeta struct {
+	Path   string `bson:"path" json:"path"`
+	Method string `bson:"method" json:"method"`
+	// Rate is how many requests are allowed Per seconds, e.g. Rate 10, Per
+	// 1 allows 10 requests per second.
+	Rate float64 `bson:"rate" json:"rate"`
+	Per  float64 `bson:"per" json:"per"`
+	// QuotaMax is the maximum number of requests allowed to this
+	// path/method within QuotaRenewalRate seconds. -1 or 0 means no
+	// endpoint-level quota.
+	QuotaMax         int64 `bson:"quota_max" json:"quota_max"`
+	QuotaRenewalRate int64 `bson:"quota_renewal_rate" json:"quota_renewal_rate"`
+}
+
+// PaginationLimitMeta clamps or rejects pagination query parameters on a
+// single path/method, so a client can't force an expensive query straight
+// through to the upstream database with something like ?limit=1000000.
+type PaginationLimitMeta struct {
+	Path   string `bson:"path" json:"path"`
+	Method string `bson:"method" json:"method"`
+	// LimitParam and OffsetParam name the query parameters to enforce.
+	// Either may be left blank to skip checking that one. Defaults to
+	// "limit" and "offset" if both are blank.
+	LimitParam  string `bson:"limit_param" json:"limit_param"`
+	OffsetParam string `bson:"offset_param" json:"offset_param"`
+	// MaxLimit and MaxOffset are the highest value allowed for each
+	// parameter. 0 means unbounded (the parameter isn't enforced).
+	MaxLimit  int64 `bson:"max_limit" json:"max_limit"`
+	MaxOffset int64 `bson:"max_offset" json:"max_offset"`
+	// RewriteInsteadOfReject clamps an out-of-range value down to its
+	// maximum and lets the request through, instead of failing it with a
+	// 400.
+	RewriteInsteadOfReject bool `bson:"rewrite_instead_of_reject" json:"rewrite_instead_of_reject"`
+}
+
+// RateLimitStrategy picks how an API's key-level rate limit is enforced
+// across a group of gateway nodes.
+type RateLimitStrategy string
+
+const (
+	// RateLimitStrategyDefault defers to the node's global rate limiter
+	// mode (EnableSentinelRateLimiter / EnableRedisRollingLimiter), or the
+	// in-memory DRL bucket if neither is set.
+	RateLimitStrategyDefault RateLimitStrategy = ""
+	// RateLimitStrategyExact always uses the Redis-scripted rolling window
+	// counter, regardless of the node's global limiter mode. Every request
+	// pays a Redis round trip, but the count it's checked against is exact
+	// across the whole cluster - suited to low-rate, high-value limits
+	// where letting a handful of requests slip through matters.
+	RateLimitStrategyExact RateLimitStrategy = "exact"
+	// RateLimitStrategyFast always uses the in-memory DRL bucket, which
+	// approximates a cluster-wide limit from each node's own share of
+	// gossiped load rather than a shared counter. No Redis round trip per
+	// request, at the cost of the usual DRL overshoot on bursty or uneven
+	// traffic - suited to high-volume APIs where a little slack is an
+	// acceptable trade for throughput.
+	RateLimitStrategyFast RateLimitStrategy = "fast"
+)
+
+// RateLimitMeta configures how an individual API's key-level rate limit
+// is enforced.
+type RateLimitMeta struct {
+	Strategy RateLimitStrategy `bson:"strategy" json:"strategy"`
+}
+
+// AccessRuleMeta configures an optional per-API expression that
+// GranularAccessMiddleware evaluates on top of (not instead of) the
+// session's allow/deny URL lists.
+type AccessRuleMeta struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Rule is a boolean expression such as
+	// `claims.role == "admin" && request.method != "DELETE"`, combining
+	// == / != comparisons with && and || and !. It's evaluated against the
+	// session's MetaData (as claims) and the current request's method and
+	// path. A request is rejected if the rule evaluates to false.
+	Rule string `bson:"rule" json:"rule"`
+}
+
+// OASImportMeta configures generating ExtendedPaths from an OpenAPI 3.0
+// document at load time.
+type OASImportMeta struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Source is a local file path or an http(s) URL to the OpenAPI
+	// document, JSON or YAML.
+	Source string `bson:"source" json:"source"`
+	// TargetVersion is the key into VersionData.Versions whose
+	// ExtendedPaths are populated from the document. Defaults to
+	// VersionData.DefaultVersion when blank.
+	TargetVersion string `bson:"target_version" json:"target_version"`
+	// GenerateWhiteList adds a WhiteList entry for every operation found in
+	// the document, so an API doesn't need every path hand-listed.
+	GenerateWhiteList bool `bson:"generate_white_list" json:"generate_white_list"`
+	// GenerateValidation adds a ValidateJSON entry for every operation
+	// whose request body declares an "application/json" schema.
+	GenerateValidation bool `bson:"generate_validation" json:"generate_validation"`
+	// GenerateMocks adds a mock (Reply) response, built from the first
+	// declared 2xx response's example, for every operation - useful for
+	// stubbing an upstream that isn't built yet.
+	GenerateMocks bool `bson:"generate_mocks" json:"generate_mocks"`
+}
+
+// XMLThreatProtectionMeta guards a path that accepts XML/SOAP payloads
+// against parser-level attacks (XXE, entity expansion, oversized or
+// deeply nested documents) before the body is ever relayed upstream.
+type XMLThreatProtectionMeta struct {
+	Path   string `bson:"path" json:"path"`
+	Method string `bson:"method" json:"method"`
+	// ProhibitDTD rejects any document that declares a DOCTYPE, which is
+	// where XXE and entity-expansion ("billion laughs") payloads live.
+	ProhibitDTD bool `bson:"prohibit_dtd" json:"prohibit_dtd"`
+	// MaxDepth limits how deeply elements may nest. 0 means unlimited.
+	MaxDepth int `bson:"max_depth" json:"max_depth"`
+	// MaxSizeBytes limits the size of the body that will be parsed. 0 means unlimited.
+	MaxSizeBytes int64 `bson:"max_size_bytes" json:"max_size_bytes"`
+	// MaxEntityExpansions limits the number of internal entity references
+	// a document may contain, as a backstop against expansion bombs that
+	// don't require a DOCTYPE to be rejected outright.
+	MaxEntityExpansions int `bson:"max_entity_expansions" json:"max_entity_expansions"`
+}
+
+// ContentTypeConversionMeta declares a body format conversion for a path,
+// so a legacy XML upstream can sit behind an API that speaks JSON to its
+// consumers, or vice versa, without hand-writing a template.
+type ContentTypeConversionMeta struct {
+	Path   string `bson:"path" json:"path"`
+	Method string `bson:"method" json:"method"`
+	// From is the content type the body arrives in: "xml" or "json".
+	From string `bson:"from" json:"from"`
+	// To is the content type the body is converted to: "xml" or "json".
+	To string `bson:"to" json:"to"`
+	// XMLRootTag names the root element wrapping a JSON->XML conversion,
+	// since a JSON document has no implicit root element. Ignored when To
+	// is "json".
+	XMLRootTag string `bson:"xml_root_tag" json:"xml_root_tag"`
+}
+
+// PathParamType is the shape a typed path parameter is constrained to.
+type PathParamType string
+
+const (
+	PathParamInt  PathParamType = "int"
+	PathParamUUID PathParamType = "uuid"
+	PathParamEnum PathParamType = "enum"
+)
+
+// PathParamConstraint restricts a single named path segment (the part
+// captured by a {name} placeholder in Path) to a specific shape.
+type PathParamConstraint struct {
+	Name string        `bson:"name" json:"name"`
+	Type PathParamType `bson:"type" json:"type"`
+	// Values lists the allowed values when Type is PathParamEnum.
+	Values []string `bson:"values" json:"values,omitempty"`
+}
+
+// TypedPathParamsMeta declares typed constraints for the named segments of
+// a path template, so a request whose path shape matches but whose segment
+// values don't (e.g. a non-numeric id where an int is required) gets a 400
+// instead of reaching the upstream router as junk traffic.
+type TypedPathParamsMeta struct {
+	Path        string                `bson:"path" json:"path"`
+	Method      string                `bson:"method" json:"method"`
+	Params      []PathParamConstraint `bson:"params" json:"params"`
+	MatchRegexp *regexp.Regexp        `json:"-"`
 }
 
 type VersionInfo struct {
@@ -244,6 +541,228 @@ type VersionInfo struct {
 	GlobalHeadersRemove []string          `bson:"global_headers_remove" json:"global_headers_remove"`
 	GlobalSizeLimit     int64             `bson:"global_size_limit" json:"global_size_limit"`
 	OverrideTarget      string            `bson:"override_target" json:"override_target"`
+	// CustomMiddlewareBundle, when set, replaces the API-level
+	// CustomMiddlewareBundle for requests resolved to this version only,
+	// letting a version run its own set of plugin hooks without the API
+	// definition duplicating its paths, auth settings, etc per version.
+	// Leave empty to keep using the API-level bundle for this version.
+	CustomMiddlewareBundle string `bson:"custom_middleware_bundle" json:"custom_middleware_bundle"`
+}
+
+// WAFConfig configures the built-in WAF middleware, which checks requests
+// against a small subset of the OWASP Core Rule Set (SQLi/XSS patterns) and
+// either blocks or just reports matches, depending on Mode.
+type WAFConfig struct {
+	Enabled          bool   `bson:"enabled" json:"enabled"`
+	Mode             string `bson:"mode" json:"mode"` // "block" or "monitor"
+	AnomalyThreshold int    `bson:"anomaly_threshold" json:"anomaly_threshold"`
+}
+
+// HTTPSOptions lets an API reject or upgrade plain HTTP requests itself,
+// for listeners that accept both schemes, rather than relying on an
+// upstream load balancer to enforce it.
+type HTTPSOptions struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Redirect, when true, responds to a plain HTTP request with a
+	// redirect to the HTTPS equivalent URL instead of rejecting it.
+	Redirect bool `bson:"redirect" json:"redirect"`
+	// STSMaxAgeSeconds is the max-age advertised in the
+	// Strict-Transport-Security header sent on HTTPS responses.
+	STSMaxAgeSeconds     int64 `bson:"sts_max_age_seconds" json:"sts_max_age_seconds"`
+	STSIncludeSubdomains bool  `bson:"sts_include_subdomains" json:"sts_include_subdomains"`
+}
+
+// TrafficShadowingOptions lets an API mirror live traffic to a second
+// (shadow) upstream, so a new backend can be validated against real
+// production traffic before it takes over.
+type TrafficShadowingOptions struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// TargetURL is the shadow upstream that receives a copy of each
+	// request. Its response is never returned to the client.
+	TargetURL string `bson:"target_url" json:"target_url"`
+	// Diff, when enabled, compares the primary and shadow responses and
+	// fires a mismatch event/analytics record on divergence.
+	Diff ResponseDiffOptions `bson:"diff" json:"diff"`
+}
+
+// ResponseDiffOptions controls how the primary and shadow responses are
+// compared when TrafficShadowingOptions.Enabled is set.
+type ResponseDiffOptions struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// CompareHeaders lists the response headers to include in the diff;
+	// headers not listed here (e.g. Date, request IDs) are ignored.
+	CompareHeaders []string `bson:"compare_headers" json:"compare_headers"`
+	// IgnoreBody skips the JSON body comparison, diffing status code and
+	// CompareHeaders only.
+	IgnoreBody bool `bson:"ignore_body" json:"ignore_body"`
+}
+
+// AnalyticsRedaction lists rules for scrubbing PII/PAN data from
+// analytics records and detailed request/response logging before it is
+// persisted, so operators can enable detailed recording without also
+// capturing regulated data.
+type AnalyticsRedaction struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Headers lists header names (case-insensitive) to strip entirely
+	// from the recorded request/response before it is persisted.
+	Headers []string `bson:"headers" json:"headers"`
+	// Regexes lists patterns whose matches are replaced with a fixed
+	// placeholder wherever they occur in the recorded request/response.
+	Regexes []string `bson:"regexes" json:"regexes"`
+}
+
+// KeyObfuscationMode controls how an API key is rendered in logs and
+// analytics records.
+type KeyObfuscationMode string
+
+const (
+	// KeyObfuscationSuffix keeps the last KeyVisibleChars characters of
+	// the key visible and masks the rest. This is the default.
+	KeyObfuscationSuffix KeyObfuscationMode = "suffix"
+	// KeyObfuscationHash replaces the key with its SHA-256 hash.
+	KeyObfuscationHash KeyObfuscationMode = "hash"
+	// KeyObfuscationNone replaces the key entirely with a fixed placeholder.
+	KeyObfuscationNone KeyObfuscationMode = "none"
+)
+
+// IPObfuscationMode controls how a client IP is rendered in analytics
+// records.
+type IPObfuscationMode string
+
+const (
+	// IPObfuscationOff leaves the IP untouched. This is the default.
+	IPObfuscationOff IPObfuscationMode = "none"
+	// IPObfuscationTruncate zeroes the trailing IPTruncateOctets octets.
+	IPObfuscationTruncate IPObfuscationMode = "truncate"
+	// IPObfuscationHash replaces the IP with its SHA-256 hash.
+	IPObfuscationHash IPObfuscationMode = "hash"
+)
+
+// AnalyticsObfuscation configures how keys and client IPs are minimised
+// before they appear in logs and analytics records, so data-minimisation
+// requirements can be met without losing all debuggability.
+type AnalyticsObfuscation struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// KeyMode controls how API keys are rendered. Defaults to "suffix".
+	KeyMode KeyObfuscationMode `bson:"key_mode" json:"key_mode"`
+	// KeyVisibleChars is how many trailing characters of the key stay
+	// visible when KeyMode is "suffix". Defaults to 4.
+	KeyVisibleChars int `bson:"key_visible_chars" json:"key_visible_chars"`
+	// IPMode controls how client IPs are rendered. Defaults to "none"
+	// (untouched).
+	IPMode IPObfuscationMode `bson:"ip_mode" json:"ip_mode"`
+	// IPTruncateOctets is how many trailing IPv4 octets (or IPv6 16-bit
+	// groups) are zeroed when IPMode is "truncate". Defaults to 1.
+	IPTruncateOctets int `bson:"ip_truncate_octets" json:"ip_truncate_octets"`
+}
+
+// TCPHealthCheckConfig configures periodic upstream health pings for a
+// tcp/tls proxied service.
+type TCPHealthCheckConfig struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// IntervalSeconds is how often the upstream is probed with a
+	// short-lived dial while a connection is proxied to it.
+	IntervalSeconds int64 `bson:"interval_seconds" json:"interval_seconds"`
+	// TimeoutSeconds bounds each health probe dial. Defaults to
+	// IntervalSeconds when unset.
+	TimeoutSeconds int64 `bson:"timeout_seconds" json:"timeout_seconds"`
+	// CloseClientOnUpstreamDown closes the client connection as soon as a
+	// health probe fails, so it can reconnect immediately instead of
+	// waiting on its own timeout.
+	CloseClientOnUpstreamDown bool `bson:"close_client_on_upstream_down" json:"close_client_on_upstream_down"`
+}
+
+// ListenPathAlias is a legacy listen path that keeps routing to the same
+// API definition as Proxy.ListenPath, so a public path can be renamed
+// without duplicating the whole API definition just to keep the old path
+// alive during a migration window.
+type ListenPathAlias struct {
+	ListenPath string `bson:"listen_path" json:"listen_path"`
+	// Redirect, when true, sends callers of ListenPath an HTTP redirect to
+	// the current Proxy.ListenPath instead of routing the request through
+	// under the alias directly.
+	Redirect bool `bson:"redirect" json:"redirect"`
+	// DeprecationHeader, when set, is sent as the value of a "Deprecation"
+	// response header for requests that come in via ListenPath, so a
+	// caller still on the old path can tell it's on its way out.
+	DeprecationHeader string `bson:"deprecation_header" json:"deprecation_header"`
+}
+
+// AttributeRateLimit rate-limits requests by a value extracted from the
+// request itself (a header, a JWT claim, or a JSON body field) instead
+// of by API key or IP, so a single shared service key can still get
+// per-tenant fairness.
+type AttributeRateLimit struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Source selects where Name is looked up: "header", "claim", or "body".
+	Source string `bson:"source" json:"source"`
+	// Name is the header name, JWT claim name, or top-level JSON body
+	// field name to key the limiter on.
+	Name string  `bson:"name" json:"name"`
+	Rate float64 `bson:"rate" json:"rate"`
+	Per  float64 `bson:"per" json:"per"`
+}
+
+// ExternalRateLimit delegates rate-limit decisions for an API to an external
+// rate limit service speaking the Envoy RLS gRPC protocol
+// (envoy.service.ratelimit.v3.RateLimitService), so a limit configured once
+// on that service is enforced the same way for both an Envoy fleet and this
+// gateway rather than being duplicated as a separate Tyk-side limit.
+type ExternalRateLimit struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// ServerAddress is the host:port of the RLS gRPC server to call.
+	ServerAddress string `bson:"server_address" json:"server_address"`
+	// Domain is the RLS domain the descriptors below are evaluated against.
+	Domain string `bson:"domain" json:"domain"`
+	// Descriptors are the descriptor entries sent with every request, as
+	// key/value pairs, mirroring Envoy's own static rate limit descriptors.
+	Descriptors []ExternalRateLimitDescriptorEntry `bson:"descriptors" json:"descriptors"`
+	// TimeoutMs bounds how long to wait for the RLS server before falling
+	// back to FailOpen.
+	TimeoutMs int `bson:"timeout_ms" json:"timeout_ms"`
+	// FailOpen lets the request through if the RLS server can't be reached
+	// or times out, instead of rejecting it.
+	FailOpen bool `bson:"fail_open" json:"fail_open"`
+}
+
+// ExternalRateLimitDescriptorEntry is a single key/value descriptor entry
+// sent to the external rate limit service.
+type ExternalRateLimitDescriptorEntry struct {
+	Key   string `bson:"key" json:"key"`
+	Value string `bson:"value" json:"value"`
+}
+
+// AuthCompositionMeta declares which of an API's enabled auth mechanisms
+// (identified by AuthTypeEnum, e.g. JWTClaim, MutualTLS) must be satisfied,
+// and how - Mode CompositionAND requires all of them, CompositionOR
+// requires at least one - evaluated in the order given rather than the
+// implicit "first enabled middleware wins" ordering the gateway otherwise
+// falls back to.
+type AuthCompositionMeta struct {
+	Enabled    bool                `bson:"enabled" json:"enabled"`
+	Mode       AuthCompositionMode `bson:"mode" json:"mode"`
+	Mechanisms []AuthTypeEnum      `bson:"mechanisms" json:"mechanisms"`
+}
+
+// FeatureFlagsConfig holds the set of boolean feature flags an API
+// evaluates per request.
+type FeatureFlagsConfig struct {
+	Enabled bool              `bson:"enabled" json:"enabled"`
+	Flags   []FeatureFlagMeta `bson:"flags" json:"flags"`
+}
+
+// FeatureFlagMeta defines one feature flag. A consumer's effective value is
+// an explicit override read from the session's MetaData (under the
+// "feature_flags" key) if present, otherwise DefaultEnabled unless
+// RolloutPercentage carves out a deterministic subset of keys to flip on.
+type FeatureFlagMeta struct {
+	Name           string `bson:"name" json:"name"`
+	DefaultEnabled bool   `bson:"default_enabled" json:"default_enabled"`
+	// RolloutPercentage (0-100) enables the flag for a deterministic
+	// hash-bucketed subset of keys, so a key's flag state stays stable
+	// across requests instead of flapping. 0 means DefaultEnabled always
+	// applies.
+	RolloutPercentage int `bson:"rollout_percentage" json:"rollout_percentage"`
 }
 
 type AuthProviderMeta struct {
@@ -272,6 +791,11 @@ type MiddlewareDefinition struct {
 	Path           string `bson:"path" json:"path"`
 	RequireSession bool   `bson:"require_session" json:"require_session"`
 	RawBodyOnly    bool   `bson:"raw_body_only" json:"raw_body_only"`
+	// APIVersion selects the Go-plugin contract this hook is built
+	// against, when the middleware driver is "goplugin": "v2" loads it
+	// through the typed PluginV2 contract, anything else (including
+	// empty) falls back to the legacy raw net/http symbol lookup.
+	APIVersion string `bson:"api_version" json:"api_version,omitempty"`
 }
 
 type MiddlewareIdExtractor struct {
@@ -298,11 +822,85 @@ type CacheOptions struct {
 	CacheOnlyResponseCodes     []int  `bson:"cache_response_codes" json:"cache_response_codes"`
 	EnableUpstreamCacheControl bool   `bson:"enable_upstream_cache_control" json:"enable_upstream_cache_control"`
 	CacheControlTTLHeader      string `bson:"cache_control_ttl_header" json:"cache_control_ttl_header"`
+	// AllowClientBypass lets an authorized client force a fresh upstream
+	// fetch (and cache refresh) by sending Cache-Control: no-cache or
+	// Pragma: no-cache, instead of an operator having to purge the Redis
+	// cache entry by hand.
+	AllowClientBypass bool `bson:"allow_client_cache_bypass" json:"allow_client_cache_bypass"`
+	// BypassCachePolicies restricts AllowClientBypass to keys carrying one
+	// of these policy IDs. Empty means any authenticated (or, for keyless
+	// APIs, any) client may request a bypass.
+	BypassCachePolicies []string `bson:"bypass_cache_policies" json:"bypass_cache_policies"`
+	// MaxCachedItems caps how many entries this API is allowed to hold in the
+	// shared Redis cache database at once. Once the cap is reached the
+	// oldest entry is evicted to make room for the newest, so a single busy
+	// API can't grow without bound and starve or evict other APIs' cache
+	// entries out from under them. 0 means unlimited.
+	MaxCachedItems int64 `bson:"max_cached_items" json:"max_cached_items"`
+}
+
+// TracingOptions lets an individual API override the gateway's global
+// trace sampling behaviour. Leave Enabled false to keep tracing this API
+// exactly as the gateway's tracer configuration (trace.SetupTracing) would
+// on its own.
+type TracingOptions struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// SampleRate is the fraction, from 0.0 to 1.0, of this API's requests
+	// that get a trace span, when neither ForceSampleHeader nor
+	// ForceSampleOnError apply.
+	SampleRate float64 `bson:"sample_rate" json:"sample_rate"`
+	// ForceSampleHeader, when present (with any value) on an inbound
+	// request, forces that request to be sampled regardless of SampleRate.
+	ForceSampleHeader string `bson:"force_sample_header" json:"force_sample_header"`
+	// ForceSampleOnError, when true, forces sampling for any request that
+	// ends in an HTTP error status (>=400), even if it wasn't otherwise
+	// picked to be sampled.
+	ForceSampleOnError bool `bson:"force_sample_on_error" json:"force_sample_on_error"`
+}
+
+// WebSocketHooksSection configures optional per-message processing for
+// proxied WebSocket connections. Leave Enabled false to keep the previous
+// behaviour of piping the raw byte stream through untouched.
+type WebSocketHooksSection struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MessageRateLimitPerSecond caps how many client->upstream messages a
+	// single connection may send per second. 0 means unlimited.
+	MessageRateLimitPerSecond int `bson:"message_rate_limit_per_second" json:"message_rate_limit_per_second"`
+	// PayloadTransform, when TemplateSource is set, is run against every
+	// text client->upstream message before it's relayed, using the same
+	// template engine as ExtendedPaths.Transform.
+	PayloadTransform TemplateData `bson:"payload_transform" json:"payload_transform"`
+	// FireEvents, when true, fires a WebSocketMessage event (see
+	// gateway/event_system.go) for every client->upstream message, letting
+	// any event handler already configured on the API (webhook, log,
+	// coprocess) react to it.
+	FireEvents bool `bson:"fire_events" json:"fire_events"`
+}
+
+// IPBlacklistFeed configures periodic refresh of an API's BlacklistedIPs
+// from an external reputation feed (e.g. a Spamhaus/abuse-style DROP
+// list), so operators can keep blocklists current without redeploying the
+// API definition every time the feed changes.
+type IPBlacklistFeed struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// URL is fetched with a plain HTTP GET; the response body is expected
+	// to be newline-separated plain IPs and/or CIDR ranges, one per line,
+	// with "#"-prefixed lines and blank lines ignored.
+	URL string `bson:"url" json:"url"`
+	// RefreshInterval is how often, in seconds, the feed is re-fetched.
+	// Values below 60 are treated as 60, to avoid hammering the feed.
+	RefreshInterval int `bson:"refresh_interval" json:"refresh_interval"`
 }
 
 type ResponseProcessor struct {
 	Name    string      `bson:"name" json:"name"`
 	Options interface{} `bson:"options" json:"options"`
+	// Priority controls where this processor sits in the response chain
+	// relative to the API's other response processors: lower values run
+	// first. Processors sharing a priority (including the default, 0) keep
+	// their relative order from this list, so leaving Priority unset for
+	// every entry reproduces the old array-order behaviour exactly.
+	Priority int `bson:"priority" json:"priority"`
 }
 
 type HostCheckObject struct {
@@ -364,30 +962,51 @@ type APIDefinition struct {
 		AllowedAccessTypes     []osin.AccessRequestType    `bson:"allowed_access_types" json:"allowed_access_types"`
 		AllowedAuthorizeTypes  []osin.AuthorizeRequestType `bson:"allowed_authorize_types" json:"allowed_authorize_types"`
 		AuthorizeLoginRedirect string                      `bson:"auth_login_redirect" json:"auth_login_redirect"`
+		// DeviceFlow configures the OAuth 2.0 device authorization grant
+		// (RFC 8628) for this API's embedded OAuth provider, for CLI and
+		// TV-style clients that can't host a redirect URI.
+		DeviceFlow DeviceFlowConfig `bson:"device_flow" json:"device_flow"`
 	} `bson:"oauth_meta" json:"oauth_meta"`
-	Auth         Auth `bson:"auth" json:"auth"`
-	UseBasicAuth bool `bson:"use_basic_auth" json:"use_basic_auth"`
-	BasicAuth    struct {
+	Auth                 Auth                  `bson:"auth" json:"auth"`
+	UseBasicAuth         bool                  `bson:"use_basic_auth" json:"use_basic_auth"`
+	UseCustomDomainAuth  bool                  `bson:"use_custom_domain_auth" json:"use_custom_domain_auth"`
+	UseTrustedHeaderAuth bool                  `bson:"use_trusted_header_auth" json:"use_trusted_header_auth"`
+	TrustedHeaderAuth    TrustedHeaderAuthMeta `bson:"trusted_header_auth" json:"trusted_header_auth"`
+	// DynamicPolicyRules lets additional policies be attached to a session
+	// for the duration of a single request, based on attributes of that
+	// request, so contextual privileges don't require duplicating keys per
+	// context.
+	DynamicPolicyRules []DynamicPolicyRule `bson:"dynamic_policy_rules" json:"dynamic_policy_rules"`
+	BasicAuth          struct {
 		DisableCaching     bool   `bson:"disable_caching" json:"disable_caching"`
 		CacheTTL           int    `bson:"cache_ttl" json:"cache_ttl"`
 		ExtractFromBody    bool   `bson:"extract_from_body" json:"extract_from_body"`
 		BodyUserRegexp     string `bson:"body_user_regexp" json:"body_user_regexp"`
 		BodyPasswordRegexp string `bson:"body_password_regexp" json:"body_password_regexp"`
+		// ExtAuth validates credentials against an external user store
+		// (htpasswd/LDAP/HTTP) instead of requiring every user to exist
+		// as a Tyk session.
+		ExtAuth ExternalBasicAuthMeta `bson:"ext_auth" json:"ext_auth"`
 	} `bson:"basic_auth" json:"basic_auth"`
-	UseMutualTLSAuth           bool                 `bson:"use_mutual_tls_auth" json:"use_mutual_tls_auth"`
-	ClientCertificates         []string             `bson:"client_certificates" json:"client_certificates"`
-	UpstreamCertificates       map[string]string    `bson:"upstream_certificates" json:"upstream_certificates"`
-	PinnedPublicKeys           map[string]string    `bson:"pinned_public_keys" json:"pinned_public_keys"`
-	EnableJWT                  bool                 `bson:"enable_jwt" json:"enable_jwt"`
-	UseStandardAuth            bool                 `bson:"use_standard_auth" json:"use_standard_auth"`
-	UseGoPluginAuth            bool                 `bson:"use_go_plugin_auth" json:"use_go_plugin_auth"`
-	EnableCoProcessAuth        bool                 `bson:"enable_coprocess_auth" json:"enable_coprocess_auth"`
-	JWTSigningMethod           string               `bson:"jwt_signing_method" json:"jwt_signing_method"`
-	JWTSource                  string               `bson:"jwt_source" json:"jwt_source"`
-	JWTIdentityBaseField       string               `bson:"jwt_identit_base_field" json:"jwt_identity_base_field"`
-	JWTClientIDBaseField       string               `bson:"jwt_client_base_field" json:"jwt_client_base_field"`
-	JWTPolicyFieldName         string               `bson:"jwt_policy_field_name" json:"jwt_policy_field_name"`
-	JWTDefaultPolicies         []string             `bson:"jwt_default_policies" json:"jwt_default_policies"`
+	UseMutualTLSAuth     bool              `bson:"use_mutual_tls_auth" json:"use_mutual_tls_auth"`
+	ClientCertificates   []string          `bson:"client_certificates" json:"client_certificates"`
+	UpstreamCertificates map[string]string `bson:"upstream_certificates" json:"upstream_certificates"`
+	PinnedPublicKeys     map[string]string `bson:"pinned_public_keys" json:"pinned_public_keys"`
+	EnableJWT            bool              `bson:"enable_jwt" json:"enable_jwt"`
+	UseStandardAuth      bool              `bson:"use_standard_auth" json:"use_standard_auth"`
+	UseGoPluginAuth      bool              `bson:"use_go_plugin_auth" json:"use_go_plugin_auth"`
+	EnableCoProcessAuth  bool              `bson:"enable_coprocess_auth" json:"enable_coprocess_auth"`
+	JWTSigningMethod     string            `bson:"jwt_signing_method" json:"jwt_signing_method"`
+	JWTSource            string            `bson:"jwt_source" json:"jwt_source"`
+	JWTIdentityBaseField string            `bson:"jwt_identit_base_field" json:"jwt_identity_base_field"`
+	JWTClientIDBaseField string            `bson:"jwt_client_base_field" json:"jwt_client_base_field"`
+	JWTPolicyFieldName   string            `bson:"jwt_policy_field_name" json:"jwt_policy_field_name"`
+	JWTDefaultPolicies   []string          `bson:"jwt_default_policies" json:"jwt_default_policies"`
+	// JWTDefaultPoliciesByIssuer allows the default policies applied when a
+	// token carries no policy claim to be scoped per token issuer ("iss"
+	// claim). If the issuer isn't present here, JWTDefaultPolicies is used
+	// as the fallback.
+	JWTDefaultPoliciesByIssuer map[string][]string  `bson:"jwt_default_policies_by_issuer" json:"jwt_default_policies_by_issuer"`
 	JWTIssuedAtValidationSkew  uint64               `bson:"jwt_issued_at_validation_skew" json:"jwt_issued_at_validation_skew"`
 	JWTExpiresAtValidationSkew uint64               `bson:"jwt_expires_at_validation_skew" json:"jwt_expires_at_validation_skew"`
 	JWTNotBeforeValidationSkew uint64               `bson:"jwt_not_before_validation_skew" json:"jwt_not_before_validation_skew"`
@@ -400,7 +1019,17 @@ type APIDefinition struct {
 	HmacAllowedAlgorithms      []string             `bson:"hmac_allowed_algorithms" json:"hmac_allowed_algorithms"`
 	RequestSigning             RequestSigningMeta   `bson:"request_signing" json:"request_signing"`
 	BaseIdentityProvidedBy     AuthTypeEnum         `bson:"base_identity_provided_by" json:"base_identity_provided_by"`
-	VersionDefinition          struct {
+	// AuthComposition lets an API require more than one auth mechanism to
+	// pass deterministically (e.g. mTLS AND JWT), instead of relying on the
+	// implicit "first enabled auth middleware wins" ordering that applies
+	// when multiple Use*Auth flags are set independently.
+	AuthComposition AuthCompositionMeta `bson:"auth_composition" json:"auth_composition"`
+	// FeatureFlags lets an API define per-consumer boolean flags that are
+	// evaluated once per request and exposed to templates/upstream
+	// headers, so backends can trust gateway-evaluated flags instead of
+	// each service re-implementing its own rollout logic.
+	FeatureFlags      FeatureFlagsConfig `bson:"feature_flags" json:"feature_flags"`
+	VersionDefinition struct {
 		Location  string `bson:"location" json:"location"`
 		Key       string `bson:"key" json:"key"`
 		StripPath bool   `bson:"strip_path" json:"strip_path"`
@@ -410,6 +1039,11 @@ type APIDefinition struct {
 		DefaultVersion string                 `bson:"default_version" json:"default_version"`
 		Versions       map[string]VersionInfo `bson:"versions" json:"versions"`
 	} `bson:"version_data" json:"version_data"`
+	// OASImport generates the default version's ExtendedPaths from an
+	// OpenAPI 3.0 document at load time, so common whitelist/validation/mock
+	// setup doesn't have to be hand-maintained alongside a spec that already
+	// describes it.
+	OASImport   OASImportMeta `bson:"oas_import" json:"oas_import"`
 	UptimeTests struct {
 		CheckList []HostCheckObject `bson:"check_list" json:"check_list"`
 		Config    struct {
@@ -419,12 +1053,33 @@ type APIDefinition struct {
 		} `bson:"config" json:"config"`
 	} `bson:"uptime_tests" json:"uptime_tests"`
 	Proxy struct {
-		PreserveHostHeader          bool                          `bson:"preserve_host_header" json:"preserve_host_header"`
-		ListenPath                  string                        `bson:"listen_path" json:"listen_path"`
-		TargetURL                   string                        `bson:"target_url" json:"target_url"`
-		DisableStripSlash           bool                          `bson:"disable_strip_slash" json:"disable_strip_slash"`
-		StripListenPath             bool                          `bson:"strip_listen_path" json:"strip_listen_path"`
-		EnableLoadBalancing         bool                          `bson:"enable_load_balancing" json:"enable_load_balancing"`
+		PreserveHostHeader bool `bson:"preserve_host_header" json:"preserve_host_header"`
+		// PreserveHeaderCasing lists request header names in the exact
+		// casing they should be sent upstream, bypassing Go's automatic
+		// header-name canonicalisation (e.g. "x-my-header" becomes
+		// "X-My-Header" by default). Go's HTTP server has already
+		// canonicalised whatever casing the client actually sent by the
+		// time a request reaches the gateway, so this doesn't recover
+		// that - it lets an operator pin the casing a case-sensitive
+		// legacy upstream expects, e.g. ["SOAPAction", "X-userID"].
+		PreserveHeaderCasing []string `bson:"preserve_header_casing" json:"preserve_header_casing"`
+		ListenPath           string   `bson:"listen_path" json:"listen_path"`
+		TargetURL            string   `bson:"target_url" json:"target_url"`
+		DisableStripSlash    bool     `bson:"disable_strip_slash" json:"disable_strip_slash"`
+		StripListenPath      bool     `bson:"strip_listen_path" json:"strip_listen_path"`
+		EnableLoadBalancing  bool     `bson:"enable_load_balancing" json:"enable_load_balancing"`
+		// LoadBalancerStrategy selects how a target is picked from Targets
+		// when EnableLoadBalancing is set. Empty (the default) keeps the
+		// existing round-robin behaviour. "consistent-hash" instead hashes
+		// the authenticated key (or ConsistentHashHeader, if set) so the
+		// same caller keeps landing on the same upstream instance, which is
+		// useful for cache locality without relying on a sticky cookie.
+		LoadBalancerStrategy string `bson:"load_balancer_strategy" json:"load_balancer_strategy"`
+		// ConsistentHashHeader names a request header to hash instead of the
+		// authenticated key, when LoadBalancerStrategy is "consistent-hash".
+		// Useful for keyless APIs, or for grouping several keys under one
+		// affinity bucket (e.g. a tenant ID header).
+		ConsistentHashHeader        string                        `bson:"consistent_hash_header" json:"consistent_hash_header"`
 		Targets                     []string                      `bson:"target_list" json:"target_list"`
 		StructuredTargetList        *HostList                     `bson:"-" json:"-"`
 		CheckHostAgainstUptimeTests bool                          `bson:"check_host_against_uptime_tests" json:"check_host_against_uptime_tests"`
@@ -435,11 +1090,44 @@ type APIDefinition struct {
 			SSLMinVersion         uint16   `bson:"ssl_min_version" json:"ssl_min_version"`
 			ProxyURL              string   `bson:"proxy_url" json:"proxy_url"`
 		} `bson:"transport" json:"transport"`
+		// ListenPathAliases lets a renamed public path keep routing through
+		// this same API definition, so renaming ListenPath doesn't force
+		// duplicating the whole API spec just to keep the old path alive
+		// for callers who haven't migrated yet.
+		ListenPathAliases []ListenPathAlias `bson:"listen_path_aliases" json:"listen_path_aliases"`
+		// TCPHealthCheck configures periodic upstream liveness probing for
+		// tcp/tls proxied services, so a dead upstream socket is detected
+		// and torn down instead of lingering until the client's own
+		// timeout fires.
+		TCPHealthCheck TCPHealthCheckConfig `bson:"tcp_health_check" json:"tcp_health_check"`
 	} `bson:"proxy" json:"proxy"`
-	DisableRateLimit          bool                   `bson:"disable_rate_limit" json:"disable_rate_limit"`
-	DisableQuota              bool                   `bson:"disable_quota" json:"disable_quota"`
+	DisableRateLimit bool `bson:"disable_rate_limit" json:"disable_rate_limit"`
+	DisableQuota     bool `bson:"disable_quota" json:"disable_quota"`
+	// EnableRateLimitHeaders turns on X-RateLimit-* and draft IETF
+	// RateLimit-* response headers for this API, so well-behaved clients can
+	// see how close they are to being throttled and back off before they
+	// hit a 429.
+	EnableRateLimitHeaders bool `bson:"enable_rate_limit_headers" json:"enable_rate_limit_headers"`
+	// RateLimit picks how this API's rate limit is enforced across a group
+	// of gateway nodes, overriding the node's global limiter mode
+	// (EnableSentinelRateLimiter / EnableRedisRollingLimiter / the
+	// in-memory DRL bucket) for this API only. DRL's own tuning
+	// (synchronisation interval, smoothing, per-node share recalculation)
+	// stays process-wide - see config.DRLNotificationFrequency - since a
+	// single DRLManager and gossiped server list is shared by every API on
+	// the node.
+	RateLimit RateLimitMeta `bson:"rate_limit" json:"rate_limit"`
+	// AccessRule lets GranularAccessMiddleware delegate a request's
+	// path/method decision to a small boolean expression evaluated against
+	// the session's claims and the request itself, for access rules too
+	// irregular to express as an allow/deny URL list. This is a
+	// hand-written expression evaluator, not a Rego/OPA integration - OPA
+	// isn't vendored in this repository.
+	AccessRule                AccessRuleMeta         `bson:"access_rule" json:"access_rule"`
 	CustomMiddleware          MiddlewareSection      `bson:"custom_middleware" json:"custom_middleware"`
 	CustomMiddlewareBundle    string                 `bson:"custom_middleware_bundle" json:"custom_middleware_bundle"`
+	WebSocketHooks            WebSocketHooksSection  `bson:"websocket_hooks" json:"websocket_hooks"`
+	TracingOptions            TracingOptions         `bson:"tracing_options" json:"tracing_options"`
 	CacheOptions              CacheOptions           `bson:"cache_options" json:"cache_options"`
 	SessionLifetime           int64                  `bson:"session_lifetime" json:"session_lifetime"`
 	Active                    bool                   `bson:"active" json:"active"`
@@ -452,6 +1140,7 @@ type APIDefinition struct {
 	AllowedIPs                []string               `mapstructure:"allowed_ips" bson:"allowed_ips" json:"allowed_ips"`
 	EnableIpBlacklisting      bool                   `mapstructure:"enable_ip_blacklisting" bson:"enable_ip_blacklisting" json:"enable_ip_blacklisting"`
 	BlacklistedIPs            []string               `mapstructure:"blacklisted_ips" bson:"blacklisted_ips" json:"blacklisted_ips"`
+	IPBlacklistFeed           IPBlacklistFeed        `bson:"ip_blacklist_feed" json:"ip_blacklist_feed"`
 	DontSetQuotasOnCreate     bool                   `mapstructure:"dont_set_quota_on_create" bson:"dont_set_quota_on_create" json:"dont_set_quota_on_create"`
 	ExpireAnalyticsAfter      int64                  `mapstructure:"expire_analytics_after" bson:"expire_analytics_after" json:"expire_analytics_after"` // must have an expireAt TTL index set (http://docs.mongodb.org/manual/tutorial/expire-data/)
 	ResponseProcessors        []ResponseProcessor    `bson:"response_processors" json:"response_processors"`
@@ -466,15 +1155,151 @@ type APIDefinition struct {
 		OptionsPassthrough bool     `bson:"options_passthrough" json:"options_passthrough"`
 		Debug              bool     `bson:"debug" json:"debug"`
 	} `bson:"CORS" json:"CORS"`
-	Domain            string                 `bson:"domain" json:"domain"`
-	Certificates      []string               `bson:"certificates" json:"certificates"`
-	DoNotTrack        bool                   `bson:"do_not_track" json:"do_not_track"`
+	Domain       string   `bson:"domain" json:"domain"`
+	Certificates []string `bson:"certificates" json:"certificates"`
+	DoNotTrack   bool     `bson:"do_not_track" json:"do_not_track"`
+	// SlowLogThresholdMs is the per-API latency budget, in milliseconds. Requests
+	// exceeding it are recorded in the gateway's in-memory slow-log ring buffer
+	// with a per-middleware and upstream latency breakdown. 0 disables slow logging.
+	SlowLogThresholdMs int64 `bson:"slow_log_threshold_ms" json:"slow_log_threshold_ms"`
+	// EnableRequestBudgetHeaders adds X-Tyk-Gateway-Time-Ms, X-Tyk-Upstream-Time-Ms,
+	// X-Tyk-Applied-Policies and X-Tyk-Cache-Status headers to every response, so API
+	// consumers can see where time went and whether a response was cached without
+	// needing to raise a support ticket.
+	EnableRequestBudgetHeaders bool `bson:"enable_request_budget_headers" json:"enable_request_budget_headers"`
+	// EnableServerTimingHeader adds a standard Server-Timing header to every
+	// response, breaking latency down by gateway phase (one entry per
+	// middleware that ran, plus a "proxy" entry for the upstream round trip)
+	// so browser devtools and APM agents can attribute it without custom
+	// instrumentation.
+	EnableServerTimingHeader bool `bson:"enable_server_timing_header" json:"enable_server_timing_header"`
+	// AnomalyDetection computes short rolling baselines for this API's
+	// error rate, latency and traffic in-gateway, firing an AnomalyDetected
+	// event when current behaviour deviates from them by more than
+	// SigmaThreshold standard deviations.
+	AnomalyDetection  AnomalyDetectionMeta   `bson:"anomaly_detection" json:"anomaly_detection"`
 	Tags              []string               `bson:"tags" json:"tags"`
 	EnableContextVars bool                   `bson:"enable_context_vars" json:"enable_context_vars"`
 	ConfigData        map[string]interface{} `bson:"config_data" json:"config_data"`
 	TagHeaders        []string               `bson:"tag_headers" json:"tag_headers"`
-	GlobalRateLimit   GlobalRateLimit        `bson:"global_rate_limit" json:"global_rate_limit"`
-	StripAuthData     bool                   `bson:"strip_auth_data" json:"strip_auth_data"`
+	// TagBaggageKeys lists W3C baggage member keys that should be added
+	// as analytics tags, mirroring TagHeaders but sourced from trace
+	// baggage instead of arbitrary request headers.
+	TagBaggageKeys   []string                `bson:"tag_baggage_keys" json:"tag_baggage_keys"`
+	GlobalRateLimit  GlobalRateLimit         `bson:"global_rate_limit" json:"global_rate_limit"`
+	StripAuthData    bool                    `bson:"strip_auth_data" json:"strip_auth_data"`
+	WAF              WAFConfig               `bson:"waf" json:"waf"`
+	HTTPS            HTTPSOptions            `bson:"https" json:"https"`
+	TrafficShadowing TrafficShadowingOptions `bson:"traffic_shadowing" json:"traffic_shadowing"`
+	// AnalyticsRedactionRules overrides the global analytics redaction
+	// rules for this API when Enabled is set.
+	AnalyticsRedactionRules AnalyticsRedaction `bson:"analytics_redaction_rules" json:"analytics_redaction_rules"`
+	// AnalyticsObfuscationRules overrides the global key/IP obfuscation
+	// rules for this API when Enabled is set.
+	AnalyticsObfuscationRules AnalyticsObfuscation `bson:"analytics_obfuscation_rules" json:"analytics_obfuscation_rules"`
+	AttributeRateLimit        AttributeRateLimit   `bson:"attribute_rate_limit" json:"attribute_rate_limit"`
+	ExternalRateLimit         ExternalRateLimit    `bson:"external_rate_limit" json:"external_rate_limit"`
+	// SelectiveDetailedRecording turns on detailed (raw request/response
+	// body) analytics recording only for the hits that match it, instead
+	// of EnableDetailedRecording paying that capture cost on every hit.
+	SelectiveDetailedRecording SelectiveDetailedRecordingMeta `bson:"selective_detailed_recording" json:"selective_detailed_recording"`
+	// AnalyticsArchive streams this API's raw analytics records straight to
+	// an object store bucket on a rotation interval, bypassing the pump,
+	// for long-term retention independent of whatever the pump is
+	// configured to keep.
+	AnalyticsArchive AnalyticsArchive `bson:"analytics_archive" json:"analytics_archive"`
+	// LoadShedding configures probabilistic dropping of low-priority
+	// requests to this API when the upstream signals it is under load.
+	LoadShedding LoadSheddingConfig `bson:"load_shedding" json:"load_shedding"`
+}
+
+// DeviceFlowConfig configures the OAuth 2.0 device authorization grant
+// (RFC 8628) endpoints of an API's embedded OAuth provider.
+type DeviceFlowConfig struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// VerificationURI is the URL shown to the user to enter UserCode,
+	// returned verbatim in the device authorization response.
+	VerificationURI string `bson:"verification_uri" json:"verification_uri"`
+	// CodeExpirySeconds is how long a device_code/user_code pair stays
+	// valid. Defaults to 600 (10 minutes) when unset.
+	CodeExpirySeconds int64 `bson:"code_expiry_seconds" json:"code_expiry_seconds"`
+	// PollIntervalSeconds is the minimum gap the client is told to leave
+	// between polls of the token endpoint. Defaults to 5 when unset.
+	PollIntervalSeconds int64 `bson:"poll_interval_seconds" json:"poll_interval_seconds"`
+	// UserCodeLength is how many characters long the user-facing code is.
+	// Defaults to 8 when unset.
+	UserCodeLength int `bson:"user_code_length" json:"user_code_length"`
+}
+
+// LoadSheddingConfig lets the gateway probabilistically shed low-priority
+// requests before they're proxied, based on a health hint the upstream puts
+// on its responses, so a struggling backend degrades gracefully instead of
+// falling over under undifferentiated load.
+type LoadSheddingConfig struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// LoadHeaderName is the response header the upstream uses to report
+	// its own load, as a value between 0 and 1 (e.g. "X-Backend-Load").
+	LoadHeaderName string `bson:"load_header_name" json:"load_header_name"`
+	// UseCircuitBreakerErrorRate, when true, also feeds this API's
+	// circuit breaker error rate into the load signal alongside (or
+	// instead of, if LoadHeaderName is unset) LoadHeaderName.
+	UseCircuitBreakerErrorRate bool `bson:"use_circuit_breaker_error_rate" json:"use_circuit_breaker_error_rate"`
+	// LoadThreshold is the load level, from 0 to 1, at which shedding
+	// begins. Below it, nothing is shed.
+	LoadThreshold float64 `bson:"load_threshold" json:"load_threshold"`
+	// MinPriority is the session Priority (see user.SessionState) that is
+	// always let through, however high the load. Sessions with a lower
+	// priority are shed with a probability that scales linearly between
+	// LoadThreshold (0% shed) and 1.0 load (100% shed).
+	MinPriority int `bson:"min_priority" json:"min_priority"`
+}
+
+// AnalyticsArchiveProvider identifies which object store AnalyticsArchive
+// uploads to.
+type AnalyticsArchiveProvider string
+
+const (
+	AnalyticsArchiveS3  AnalyticsArchiveProvider = "s3"
+	AnalyticsArchiveGCS AnalyticsArchiveProvider = "gcs"
+)
+
+// AnalyticsArchive configures direct archival of this API's raw analytics
+// records as compressed NDJSON batches to S3 or GCS, independent of the
+// pump. Parquet output is not supported - this repo doesn't vendor a
+// Parquet writer, so only the NDJSON format is implemented.
+type AnalyticsArchive struct {
+	Enabled  bool                     `bson:"enabled" json:"enabled"`
+	Provider AnalyticsArchiveProvider `bson:"provider" json:"provider"`
+	Bucket   string                   `bson:"bucket" json:"bucket"`
+	// Prefix is prepended to every archived object's key, e.g. "prod/orders/".
+	Prefix string `bson:"prefix" json:"prefix"`
+	// Region is required for the S3 provider - it's part of both the
+	// upload endpoint and the SigV4 signature.
+	Region string `bson:"region" json:"region"`
+	// RotationIntervalSeconds controls how often buffered records are
+	// flushed as a new archive object, regardless of how many accumulated.
+	RotationIntervalSeconds int64 `bson:"rotation_interval_seconds" json:"rotation_interval_seconds"`
+	// AccessKeyID and SecretAccessKey authenticate uploads to the S3
+	// provider via AWS SigV4.
+	AccessKeyID     string `bson:"access_key_id" json:"access_key_id"`
+	SecretAccessKey string `bson:"secret_access_key" json:"secret_access_key"`
+	// BearerToken authenticates uploads to the GCS provider via its XML API.
+	BearerToken string `bson:"bearer_token" json:"bearer_token"`
+}
+
+// SelectiveDetailedRecordingMeta selectively turns on detailed analytics
+// recording for a hit that would otherwise not have it, based on the
+// response rather than the request - e.g. so bodies are only captured for
+// requests that turned out to be a problem.
+type SelectiveDetailedRecordingMeta struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// StatusCodes triggers detailed recording when the response status
+	// matches one of these codes exactly.
+	StatusCodes []int `bson:"status_codes" json:"status_codes"`
+	// TriggerHeader, when the upstream sets it (to any non-empty value)
+	// on the response, also triggers detailed recording for that hit,
+	// regardless of status code.
+	TriggerHeader string `bson:"trigger_header" json:"trigger_header"`
 }
 
 type Auth struct {
@@ -516,6 +1341,105 @@ type RequestSigningMeta struct {
 	Algorithm string `bson:"algorithm" json:"algorithm"`
 }
 
+// ExternalBasicAuthMeta configures validating basic auth credentials against
+// an external user store instead of requiring every user to exist as a Tyk
+// session. A successful validation is mapped onto a session carrying
+// DefaultPolicies, so the operator only has to manage users in the external
+// store rather than mirroring each one into Tyk.
+type ExternalBasicAuthMeta struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Type selects the external store: "htpasswd", "ldap" or "http".
+	Type ExternalBasicAuthType `bson:"type" json:"type"`
+	// CacheTTL is how long, in seconds, a successful validation is cached
+	// for so every request doesn't re-hit the external store.
+	CacheTTL int `bson:"cache_ttl" json:"cache_ttl"`
+	// DefaultPolicies are applied to the session created for a user
+	// validated against the external store.
+	DefaultPolicies []string `bson:"default_policies" json:"default_policies"`
+
+	// HtpasswdPath is the path to an htpasswd file, used when Type is
+	// "htpasswd". Only bcrypt-hashed entries (htpasswd -B) are supported.
+	HtpasswdPath string `bson:"htpasswd_path" json:"htpasswd_path"`
+
+	// LDAPServer, LDAPPort and LDAPBaseDN configure a bind against an LDAP
+	// server, used when Type is "ldap". LDAPBindDNTemplate builds the DN to
+	// bind as from the supplied username, with USERNAME replaced by the
+	// value the client sent.
+	LDAPServer         string `bson:"ldap_server" json:"ldap_server"`
+	LDAPPort           uint16 `bson:"ldap_port" json:"ldap_port"`
+	LDAPBaseDN         string `bson:"ldap_base_dn" json:"ldap_base_dn"`
+	LDAPBindDNTemplate string `bson:"ldap_bind_dn_template" json:"ldap_bind_dn_template"`
+
+	// HTTPEndpoint is a URL that's sent a POST request with the username and
+	// password, used when Type is "http". A 2xx response is treated as a
+	// valid login and anything else as invalid.
+	HTTPEndpoint string `bson:"http_endpoint" json:"http_endpoint"`
+}
+
+// ExternalBasicAuthType identifies which kind of external user store
+// ExternalBasicAuthMeta.Type points at.
+type ExternalBasicAuthType string
+
+const (
+	ExternalBasicAuthHtpasswd ExternalBasicAuthType = "htpasswd"
+	ExternalBasicAuthLDAP     ExternalBasicAuthType = "ldap"
+	ExternalBasicAuthHTTP     ExternalBasicAuthType = "http"
+)
+
+// TrustedHeaderAuthMeta configures identity extraction from a header set by
+// a trusted upstream SSO proxy/load balancer, for intranet deployments
+// where the LB has already authenticated the caller and Tyk only needs to
+// map that identity onto a session and policy set.
+type TrustedHeaderAuthMeta struct {
+	// HeaderName is the header the trusted proxy sets with the caller's
+	// identity, e.g. "X-Authenticated-User".
+	HeaderName string `bson:"header_name" json:"header_name"`
+	// TrustedCIDRs restricts which source IPs may assert identity via
+	// HeaderName - normally just the LB's own address(es) or the intranet
+	// range it's deployed on. A request from outside these ranges is
+	// rejected outright, so the header can't be spoofed by reaching the
+	// gateway directly.
+	TrustedCIDRs []string `bson:"trusted_cidrs" json:"trusted_cidrs"`
+	// DefaultPolicies are applied to the session synthesised for a caller
+	// identity seen for the first time.
+	DefaultPolicies []string `bson:"default_policies" json:"default_policies"`
+}
+
+// DynamicPolicyRule declares one condition-to-policies mapping evaluated
+// against each request during auth. A rule matches when every condition it
+// sets is non-empty and satisfied; conditions left blank are ignored, so a
+// rule can match on any combination of them.
+type DynamicPolicyRule struct {
+	// HeaderName/HeaderValue match when the request carries HeaderName set
+	// to exactly HeaderValue.
+	HeaderName  string `bson:"header_name" json:"header_name"`
+	HeaderValue string `bson:"header_value" json:"header_value"`
+	// PathPrefix matches when the request path starts with it.
+	PathPrefix string `bson:"path_prefix" json:"path_prefix"`
+	// ClientCertOU matches when the client's mTLS certificate carries this
+	// value in its Subject's Organizational Unit.
+	ClientCertOU string `bson:"client_cert_ou" json:"client_cert_ou"`
+	// PolicyIDs are appended to the session's policies for the duration of
+	// the request when this rule matches.
+	PolicyIDs []string `bson:"policy_ids" json:"policy_ids"`
+}
+
+// AnomalyDetectionMeta configures in-gateway rolling-baseline anomaly
+// detection for an API: short-term latency, error rate and traffic are
+// compared against a slower-moving baseline, firing an AnomalyDetected
+// event when they drift too far apart.
+type AnomalyDetectionMeta struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// SigmaThreshold is how many standard deviations away from the rolling
+	// baseline current behaviour must be before an anomaly event fires.
+	// Defaults to 3 if unset.
+	SigmaThreshold float64 `bson:"sigma_threshold" json:"sigma_threshold"`
+	// MinSamples is the minimum number of requests collected before anomaly
+	// checks start, so a freshly started API isn't flagged from noise.
+	// Defaults to 100 if unset.
+	MinSamples int `bson:"min_samples" json:"min_samples"`
+}
+
 // Clean will URL encode map[string]struct variables for saving
 func (a *APIDefinition) EncodeForDB() {
 	newVersion := make(map[string]VersionInfo)
@@ -762,6 +1686,7 @@ func DummyAPI() APIDefinition {
 		ClientCertificates:      []string{},
 		BlacklistedIPs:          []string{},
 		TagHeaders:              []string{},
+		TagBaggageKeys:          []string{},
 		UpstreamCertificates:    map[string]string{},
 		JWTScopeToPolicyMapping: map[string]string{},
 		HmacAllowedAlgorithms:   []string{},
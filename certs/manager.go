@@ -37,6 +37,7 @@ type CertificateManager struct {
 	logger  *logrus.Entry
 	cache   *cache.Cache
 	secret  string
+	vault   VaultConfig
 }
 
 func NewCertificateManager(storage StorageHandler, secret string, logger *logrus.Logger) *CertificateManager {
@@ -52,6 +53,13 @@ func NewCertificateManager(storage StorageHandler, secret string, logger *logrus
 	}
 }
 
+// SetVaultConfig wires up the connection details used to resolve "vault://"
+// certificate IDs. Called once at gateway startup; left zero-valued, any
+// "vault://" ID simply fails to resolve.
+func (c *CertificateManager) SetVaultConfig(vault VaultConfig) {
+	c.vault = vault
+}
+
 // Extracted from: https://golang.org/src/crypto/tls/tls.go
 //
 // Attempt to parse the given private key DER block. OpenSSL 0.9.8 generates
@@ -238,6 +246,68 @@ func ExtractCertificateMeta(cert *tls.Certificate, certID string) *CertificateMe
 	}
 }
 
+// readRawCert fetches the raw PEM bytes for a certificate ID from whichever
+// backing store the ID's shape identifies: Vault (a "vault://" ID), Redis
+// (a SHA256 hex ID), or the local filesystem (anything else). Used by both
+// List, on a cache miss, and Refresh, to re-read a certificate that might
+// have rotated at the source.
+func (c *CertificateManager) readRawCert(id string) ([]byte, error) {
+	if strings.HasPrefix(id, vaultCertPrefix) {
+		rawCert, err := fetchVaultCert(c.vault, id)
+		if err != nil {
+			c.logger.Error("Error while reading certificate from Vault:", id, err)
+			return nil, err
+		}
+		return rawCert, nil
+	}
+
+	if isSHA256(id) {
+		val, err := c.storage.GetKey("raw-" + id)
+		if err != nil {
+			c.logger.Warn("Can't retrieve certificate from Redis:", id, err)
+			return nil, err
+		}
+		return []byte(val), nil
+	}
+
+	rawCert, err := ioutil.ReadFile(id)
+	if err != nil {
+		c.logger.Error("Error while reading certificate from file:", id, err)
+		return nil, err
+	}
+	return rawCert, nil
+}
+
+// Refresh re-reads certIDs from their backing store and swaps in any that
+// changed, so a certificate rotated at the source (file, Redis, or Vault)
+// gets picked up before its cache entry would otherwise expire. Unlike
+// evicting via the control API's cert-delete endpoint, this doesn't require
+// the operator to tell the gateway a rotation happened.
+func (c *CertificateManager) Refresh(certIDs []string) {
+	for _, id := range certIDs {
+		rawCert, err := c.readRawCert(id)
+		if err != nil {
+			continue
+		}
+
+		cert, err := ParsePEMCertificate(rawCert, c.secret)
+		if err != nil {
+			c.logger.Error("Error while parsing refreshed certificate: ", id, " ", err)
+			continue
+		}
+
+		if cached, found := c.cache.Get(id); found {
+			if string(cached.(*tls.Certificate).Leaf.Extensions[0].Value) == string(cert.Leaf.Extensions[0].Value) {
+				// Fingerprint unchanged, nothing to swap.
+				continue
+			}
+			c.logger.Info("Certificate rotated, hot-swapping: ", id)
+		}
+
+		c.cache.Set(id, cert, cache.DefaultExpiration)
+	}
+}
+
 func (c *CertificateManager) List(certIDs []string, mode CertificateType) (out []*tls.Certificate) {
 	var cert *tls.Certificate
 	var rawCert []byte
@@ -251,22 +321,10 @@ func (c *CertificateManager) List(certIDs []string, mode CertificateType) (out [
 			continue
 		}
 
-		if isSHA256(id) {
-			var val string
-			val, err = c.storage.GetKey("raw-" + id)
-			if err != nil {
-				c.logger.Warn("Can't retrieve certificate from Redis:", id, err)
-				out = append(out, nil)
-				continue
-			}
-			rawCert = []byte(val)
-		} else {
-			rawCert, err = ioutil.ReadFile(id)
-			if err != nil {
-				c.logger.Error("Error while reading certificate from file:", id, err)
-				out = append(out, nil)
-				continue
-			}
+		rawCert, err = c.readRawCert(id)
+		if err != nil {
+			out = append(out, nil)
+			continue
 		}
 
 		cert, err = ParsePEMCertificate(rawCert, c.secret)
@@ -58,6 +58,10 @@ const (
 	PickFirstStrategy IPsHandleStrategy = "pick_first"
 	RandomStrategy    IPsHandleStrategy = "random"
 	NoCacheStrategy   IPsHandleStrategy = "no_cache"
+	// FailoverStrategy round-robins across all cached addresses for a
+	// host, skipping any address that recently failed to dial, instead
+	// of pinning every request to the first resolved address.
+	FailoverStrategy IPsHandleStrategy = "failover"
 
 	DefaultDashPolicySource     = "service"
 	DefaultDashPolicyRecordName = "tyk_policies"
@@ -116,7 +120,15 @@ type AnalyticsConfigConfig struct {
 	PoolSize                int                 `json:"pool_size"`
 	RecordsBufferSize       uint64              `json:"records_buffer_size"`
 	StorageExpirationTime   int                 `json:"storage_expiration_time"`
-	ignoredIPsCompiled      map[string]bool
+	// Redaction scrubs PII/PAN data from analytics records and detailed
+	// request/response logging before it is persisted, applied whenever
+	// an API doesn't define its own redaction rules.
+	Redaction apidef.AnalyticsRedaction `json:"redaction"`
+	// Obfuscation controls how keys and client IPs are minimised in logs
+	// and analytics records, applied whenever an API doesn't define its
+	// own obfuscation rules.
+	Obfuscation        apidef.AnalyticsObfuscation `json:"obfuscation"`
+	ignoredIPsCompiled map[string]bool
 }
 
 type HealthCheckConfig struct {
@@ -145,6 +157,42 @@ type WebHookHandlerConf struct {
 	TemplatePath string            `bson:"template_path" json:"template_path"`
 	HeaderList   map[string]string `bson:"header_map" json:"header_map"`
 	EventTimeout int64             `bson:"event_timeout" json:"event_timeout"`
+	// Secret, when set, makes the webhook HMAC-sign its request body (see
+	// the X-Tyk-Webhook-Signature/X-Tyk-Webhook-Timestamp headers on
+	// WebHookHandler), so the receiver can authenticate that the event
+	// genuinely came from this gateway.
+	Secret string `bson:"secret" json:"secret,omitempty"`
+}
+
+// AlertHandlerConf configures one of the built-in alert notifiers (Slack,
+// PagerDuty, email). It mirrors WebHookHandlerConf's shape - a target,
+// an optional custom message template, and a delivery cooldown - plus the
+// handful of fields each specific notifier needs.
+type AlertHandlerConf struct {
+	// TargetPath is the delivery target: a Slack incoming webhook URL for
+	// eh_slack_handler, a PagerDuty Events API URL for eh_pagerduty_handler,
+	// ignored for eh_email_handler.
+	TargetPath string `bson:"target_path" json:"target_path"`
+	// TemplatePath renders the alert body, falling back to a built-in
+	// default per handler type when empty.
+	TemplatePath string `bson:"template_path" json:"template_path"`
+	// CooldownSecs suppresses repeat notifications for the same event type
+	// for this long, so a spike of identical events sends one alert
+	// instead of one per occurrence. 0 disables suppression.
+	CooldownSecs int64 `bson:"cooldown_secs" json:"cooldown_secs"`
+
+	// RoutingKey is the PagerDuty Events API v2 integration key.
+	RoutingKey string `bson:"routing_key" json:"routing_key,omitempty"`
+	// Severity is the PagerDuty event severity (critical, error, warning, info).
+	Severity string `bson:"severity" json:"severity,omitempty"`
+
+	// SMTPAddress is the host:port of the outbound mail server.
+	SMTPAddress string `bson:"smtp_address" json:"smtp_address,omitempty"`
+	// SMTPUsername/SMTPPassword authenticate against SMTPAddress, when set.
+	SMTPUsername string   `bson:"smtp_username" json:"smtp_username,omitempty"`
+	SMTPPassword string   `bson:"smtp_password" json:"smtp_password,omitempty"`
+	From         string   `bson:"from" json:"from,omitempty"`
+	To           []string `bson:"to" json:"to,omitempty"`
 }
 
 type SlaveOptionsConfig struct {
@@ -169,13 +217,84 @@ type LocalSessionCacheConf struct {
 	CacheSessionEviction     int  `json:"cached_session_eviction"`
 }
 
+// GitAppConfigConfig configures a git-backed API definition/policy loader,
+// pulling both from a single repository checkout on a branch or tag.
+type GitAppConfigConfig struct {
+	Enabled bool `json:"enabled"`
+	// Repo is the clone URL, either ssh (git@host:org/repo.git) or https.
+	Repo string `json:"repo"`
+	// Branch and Tag are mutually exclusive; Branch takes precedence if both are set.
+	Branch string `json:"branch"`
+	Tag    string `json:"tag"`
+	// SSHKeyPath is used to authenticate ssh:// / git@ clone URLs.
+	SSHKeyPath string `json:"ssh_key_path"`
+	// Username and Password authenticate https:// clone URLs (Password may
+	// be a personal access token).
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// APIDefinitionsPath and PoliciesPath are directories, relative to the
+	// repository root, containing the JSON API definition and policy files.
+	APIDefinitionsPath string `json:"api_definitions_path"`
+	PoliciesPath       string `json:"policies_path"`
+	// SyncIntervalSeconds is how often to pull and reload; 0 disables
+	// periodic sync, relying solely on the reload webhook.
+	SyncIntervalSeconds int `json:"sync_interval_seconds"`
+}
+
+// KubernetesAppConfigConfig configures a Kubernetes-backed API definition
+// loader, polling a namespaced custom resource for ApiDefinition objects so
+// the gateway can run dashboard-less inside a cluster and pick up changes
+// made with kubectl. It talks to the Kubernetes API server's plain REST
+// interface directly rather than through a generated client, so it only
+// supports listing the configured resource, not the full CRD feature set.
+type KubernetesAppConfigConfig struct {
+	Enabled bool `json:"enabled"`
+	// APIServerHost overrides the in-cluster API server address
+	// (KUBERNETES_SERVICE_HOST/PORT); leave blank when running in-cluster.
+	APIServerHost string `json:"api_server_host"`
+	// TokenPath and CACertPath default to the standard in-cluster service
+	// account paths and rarely need overriding outside of tests.
+	TokenPath  string `json:"token_path"`
+	CACertPath string `json:"ca_cert_path"`
+	// Namespace, Group, Version and Resource identify the custom resource
+	// to list, e.g. Group "tyk.io", Version "v1", Resource "apidefinitions".
+	Namespace string `json:"namespace"`
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Resource  string `json:"resource"`
+	// SyncIntervalSeconds is how often to poll; 0 disables periodic sync,
+	// relying solely on the reload webhook.
+	SyncIntervalSeconds int `json:"sync_interval_seconds"`
+}
+
+// VaultConfig configures resolving "vault://" upstream/client certificate
+// IDs against a HashiCorp Vault KV secret engine. There's no vendored Vault
+// client in this tree, so the gateway talks to Vault's plain HTTP API and
+// only supports a single static token, not any of Vault's auth methods.
+type VaultConfig struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+	Token   string `json:"token"`
+	// TokenPath, if set and Token is empty, is read once at startup - lets
+	// operators inject the token via a mounted file instead of plaintext
+	// config, the same convention KubernetesAppConfigConfig uses for its
+	// service-account token.
+	TokenPath string `json:"token_path"`
+}
+
 type HttpServerOptionsConfig struct {
-	OverrideDefaults       bool       `json:"override_defaults"`
-	ReadTimeout            int        `json:"read_timeout"`
-	WriteTimeout           int        `json:"write_timeout"`
-	UseSSL                 bool       `json:"use_ssl"`
-	UseLE_SSL              bool       `json:"use_ssl_le"`
-	EnableHttp2            bool       `json:"enable_http2"`
+	OverrideDefaults bool `json:"override_defaults"`
+	ReadTimeout      int  `json:"read_timeout"`
+	WriteTimeout     int  `json:"write_timeout"`
+	UseSSL           bool `json:"use_ssl"`
+	UseLE_SSL        bool `json:"use_ssl_le"`
+	EnableHttp2      bool `json:"enable_http2"`
+	// EnableHttp3 starts an additional QUIC (HTTP/3) listener alongside the
+	// HTTPS listener on the same port, and advertises it via Alt-Svc, for
+	// clients that benefit from QUIC's reduced connection setup and
+	// head-of-line-blocking-free multiplexing (e.g. mobile consumers on
+	// lossy networks). Requires UseSSL.
+	EnableHttp3            bool       `json:"enable_http3"`
 	SSLInsecureSkipVerify  bool       `json:"ssl_insecure_skip_verify"`
 	EnableWebSockets       bool       `json:"enable_websockets"`
 	Certificates           []CertData `json:"certificates"`
@@ -216,6 +335,28 @@ type CoProcessConfig struct {
 	CoProcessGRPCServer string `json:"coprocess_grpc_server"`
 	PythonPathPrefix    string `json:"python_path_prefix"`
 	PythonVersion       string `json:"python_version"`
+	// MaxConcurrentDispatches caps the number of in-flight Dispatch calls
+	// per driver (python, lua, grpc). 0 means unlimited. When the limit is
+	// reached, requests queue for DispatchQueueTimeout before being shed
+	// with a 503 rather than piling up unbounded goroutines.
+	MaxConcurrentDispatches int `json:"max_concurrent_dispatches"`
+	// DispatchQueueTimeout is how long, in milliseconds, a request waits
+	// for a free dispatch slot before being shed. Defaults to 1000ms.
+	DispatchQueueTimeout int `json:"dispatch_queue_timeout"`
+}
+
+// RequestConcurrencyControlConfig bounds how many requests the gateway
+// will hold in flight across all APIs at once.
+type RequestConcurrencyControlConfig struct {
+	// MaxConcurrentRequests caps the number of in-flight proxied requests.
+	// 0 (the default) means unlimited.
+	MaxConcurrentRequests int `json:"max_concurrent_requests"`
+	// MaxQueuedRequests caps how many requests may wait for a free slot
+	// once MaxConcurrentRequests is reached, before being shed outright.
+	MaxQueuedRequests int `json:"max_queued_requests"`
+	// QueueTimeoutMs is how long, in milliseconds, a queued request waits
+	// for a free slot before being shed with a 503. Defaults to 1000ms.
+	QueueTimeoutMs int `json:"queue_timeout_ms"`
 }
 
 type CertificatesConfig struct {
@@ -299,11 +440,15 @@ type Config struct {
 	// was written.
 	OriginalPath string `json:"-"`
 
-	HostName                  string                  `json:"hostname"`
-	ListenAddress             string                  `json:"listen_address"`
-	ListenPort                int                     `json:"listen_port"`
-	ControlAPIHostname        string                  `json:"control_api_hostname"`
-	ControlAPIPort            int                     `json:"control_api_port"`
+	HostName           string `json:"hostname"`
+	ListenAddress      string `json:"listen_address"`
+	ListenPort         int    `json:"listen_port"`
+	ControlAPIHostname string `json:"control_api_hostname"`
+	ControlAPIPort     int    `json:"control_api_port"`
+	// MetricsPort, when set, serves the Prometheus /metrics endpoint on
+	// its own listener instead of the control API's port. Leave unset
+	// (or equal to ControlAPIPort) to expose it alongside the control API.
+	MetricsPort               int                     `json:"metrics_port"`
 	Secret                    string                  `json:"secret"`
 	NodeSecret                string                  `json:"node_secret"`
 	PIDFileLocation           string                  `json:"pid_file_location"`
@@ -318,14 +463,36 @@ type Config struct {
 	SuppressRedisSignalReload bool                    `json:"suppress_redis_signal_reload"`
 
 	// Gateway Security Policies
-	HashKeys                bool           `json:"hash_keys"`
-	HashKeyFunction         string         `json:"hash_key_function"`
-	EnableHashedKeysListing bool           `json:"enable_hashed_keys_listing"`
-	MinTokenLength          int            `json:"min_token_length"`
-	EnableAPISegregation    bool           `json:"enable_api_segregation"`
-	TemplatePath            string         `json:"template_path"`
-	Policies                PoliciesConfig `json:"policies"`
-	DisablePortWhiteList    bool           `json:"disable_ports_whitelist"`
+	HashKeys                bool   `json:"hash_keys"`
+	HashKeyFunction         string `json:"hash_key_function"`
+	EnableHashedKeysListing bool   `json:"enable_hashed_keys_listing"`
+	MinTokenLength          int    `json:"min_token_length"`
+	EnableAPISegregation    bool   `json:"enable_api_segregation"`
+	EnableAPIAutoDisable    bool   `json:"enable_api_auto_disable"`
+
+	// TrustedProxyDepth is the number of trusted reverse proxies in front
+	// of the gateway. It controls how many hops in from the right of the
+	// X-Forwarded-For header are trusted when determining the real client
+	// IP, so dual-stack/mixed IPv4-IPv6 load balancer chains don't get the
+	// client IP misattributed to an intermediate proxy. 0 (the default)
+	// preserves the historical behaviour of trusting the left-most entry.
+	TrustedProxyDepth int `json:"trusted_proxy_depth"`
+
+	// RequestConcurrencyControl bounds how many requests the gateway will
+	// process at once, so a traffic spike degrades into fast 503s instead
+	// of unbounded goroutine/memory growth and rising latency for everyone.
+	RequestConcurrencyControl RequestConcurrencyControlConfig `json:"request_concurrency_control"`
+
+	// SidecarMode makes the gateway act as a service mesh sidecar: every
+	// loaded API's upstream is transparently redirected to the local
+	// application instead of whatever target URL the API definition
+	// declares, so the gateway can sit in front of a workload without
+	// each API needing to know its own sidecar address.
+	SidecarMode            bool           `json:"sidecar_mode"`
+	SidecarLocalAppAddress string         `json:"sidecar_local_app_address"`
+	TemplatePath           string         `json:"template_path"`
+	Policies               PoliciesConfig `json:"policies"`
+	DisablePortWhiteList   bool           `json:"disable_ports_whitelist"`
 	// Defines the ports that will be available for the api services to bind to.
 	// This is a map of protocol to PortWhiteList. This allows per protocol
 	// configurations.
@@ -334,6 +501,22 @@ type Config struct {
 	// CE Configurations
 	AppPath string `json:"app_path"`
 
+	// GitAppConfig configures loading API definitions and policies from a
+	// git repository instead of (or in addition to syncing into) AppPath,
+	// giving file-mode/single-node users change history and review without
+	// needing the dashboard.
+	GitAppConfig GitAppConfigConfig `json:"git_app_config"`
+
+	// KubernetesAppConfig configures loading API definitions from a
+	// Kubernetes custom resource instead of (or in addition to syncing
+	// into) AppPath, for dashboard-less clusters managed with kubectl.
+	KubernetesAppConfig KubernetesAppConfigConfig `json:"kubernetes_app_config"`
+
+	// Vault configures resolving "vault://" certificate IDs used by
+	// APIDefinition.UpstreamCertificates/ClientCertificates and
+	// Security.Certificates.Upstream.
+	Vault VaultConfig `json:"vault"`
+
 	// Dashboard Configurations
 	UseDBAppConfigs          bool                   `json:"use_db_app_configs"`
 	DBAppConfOptions         DBAppConfOptionsConfig `json:"db_app_conf_options"`
@@ -350,6 +533,12 @@ type Config struct {
 	EnableSentinelRateLimiter         bool `json:"enable_sentinel_rate_limiter"`
 	EnableRedisRollingLimiter         bool `json:"enable_redis_rolling_limiter"`
 	DRLNotificationFrequency          int  `json:"drl_notification_frequency"`
+	// EnableConcurrentRateLimitAndQuotaChecks runs the per-request rate
+	// limit and quota Redis round trips concurrently instead of back to
+	// back, since they touch independent keys. This trades strict
+	// short-circuiting (a rate-limited request no longer skips its quota
+	// increment) for lower added latency on the hot path.
+	EnableConcurrentRateLimitAndQuotaChecks bool `json:"enable_concurrent_rate_limit_and_quota_checks"`
 
 	// Organization configurations
 	EnforceOrgDataAge               bool          `json:"enforce_org_data_age"`
@@ -359,12 +548,33 @@ type Config struct {
 	Monitor                         MonitorConfig `json:"monitor"`
 
 	// Client-Gateway Configuration
-	MaxIdleConns         int   `bson:"max_idle_connections" json:"max_idle_connections"`
-	MaxIdleConnsPerHost  int   `bson:"max_idle_connections_per_host" json:"max_idle_connections_per_host"`
-	MaxConnTime          int64 `json:"max_conn_time"`
-	CloseIdleConnections bool  `json:"close_idle_connections"`
-	CloseConnections     bool  `json:"close_connections"`
-	EnableCustomDomains  bool  `json:"enable_custom_domains"`
+	MaxIdleConns        int   `bson:"max_idle_connections" json:"max_idle_connections"`
+	MaxIdleConnsPerHost int   `bson:"max_idle_connections_per_host" json:"max_idle_connections_per_host"`
+	MaxConnTime         int64 `json:"max_conn_time"`
+	// DrainConnectionsTimeout is how many seconds an API's old upstream
+	// transport is kept alive for after a reload replaces it, so requests
+	// that are still in flight on it get to finish instead of the old
+	// transport's connections being dropped from under them. 0 disables
+	// draining and the old transport is discarded immediately, as before
+	// this setting existed.
+	DrainConnectionsTimeout int64 `json:"drain_connections_timeout"`
+	CloseIdleConnections    bool  `json:"close_idle_connections"`
+	CloseConnections        bool  `json:"close_connections"`
+	// HandlerDrainTimeout is how many seconds a reload waits for in-flight
+	// client requests on a listener that the new configuration no longer
+	// uses (e.g. an API's ListenPort changed or was removed) to finish
+	// before that listener is shut down. Defaults to 10 seconds if unset.
+	HandlerDrainTimeout int64 `json:"handler_drain_timeout"`
+	// ProbeTargetsOnReload, when true, makes a reload probe each API's
+	// upstream target (a HEAD request, or a bare TCP connect for non-HTTP
+	// targets) before it completes, so a typo'd or unreachable upstream is
+	// caught and reported immediately instead of only surfacing once
+	// traffic starts failing against it.
+	ProbeTargetsOnReload bool `json:"probe_targets_on_reload"`
+	// ProbeTargetsTimeout bounds how long each upstream probe triggered by
+	// ProbeTargetsOnReload is allowed to take, in seconds. Defaults to 5.
+	ProbeTargetsTimeout int64 `json:"probe_targets_timeout"`
+	EnableCustomDomains bool  `json:"enable_custom_domains"`
 	// If AllowMasterKeys is set to true, session objects (key definitions) that do not have explicit access rights set
 	// will be allowed by Tyk. This means that keys that are created have access to ALL APIs, which in many cases is
 	// unwanted behaviour unless you are sure about what you are doing.
@@ -29,6 +29,11 @@ const (
 	ThrottleLevelLimit
 	Trace
 	CheckLoopLimits
+	MWTimings
+	QuotaOverage
+	CertPinningMismatch
+	ControlAPIOrgID
+	CacheStatus
 )
 
 func setContext(r *http.Request, ctx context.Context) {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/TykTechnologies/tyk/config"
@@ -14,6 +15,10 @@ import (
 	"github.com/TykTechnologies/tyk/log"
 )
 
+// failoverCooldown is how long a failed address is skipped by
+// FailoverStrategy before it's considered eligible again.
+const failoverCooldown = 30 * time.Second
+
 var (
 	logger = log.Get().WithField("prefix", "dnscache")
 )
@@ -46,11 +51,21 @@ type DnsCacheManager struct {
 	cacheStorage IDnsCacheStorage
 	strategy     config.IPsHandleStrategy
 	rand         *rand.Rand
+
+	failuresMu sync.Mutex
+	failures   map[string]time.Time // ip -> time it becomes eligible again
+
+	rrMu    sync.Mutex
+	rrIndex map[string]int // host -> next round-robin offset
 }
 
 // NewDnsCacheManager returns new empty/non-initialized DnsCacheManager
 func NewDnsCacheManager(multipleIPsHandleStrategy config.IPsHandleStrategy) *DnsCacheManager {
-	manager := &DnsCacheManager{nil, multipleIPsHandleStrategy, nil}
+	manager := &DnsCacheManager{
+		strategy: multipleIPsHandleStrategy,
+		failures: make(map[string]time.Time),
+		rrIndex:  make(map[string]int),
+	}
 	return manager
 }
 
@@ -123,9 +138,57 @@ func (m *DnsCacheManager) doCachedDial(d *net.Dialer, ctx context.Context, netwo
 		return safeDial(ips[0]+":"+port, host)
 	}
 
+	if m.strategy == config.FailoverStrategy {
+		ip := m.getFailoverIp(host, ips)
+		conn, err := d.DialContext(ctx, network, ip+":"+port)
+		if err != nil {
+			m.markIPFailed(ip)
+		}
+		return conn, err
+	}
+
 	return safeDial(ips[0]+":"+port, host)
 }
 
+// markIPFailed takes ip out of rotation for failoverCooldown, so a bad
+// address doesn't keep absorbing its share of traffic on every request.
+func (m *DnsCacheManager) markIPFailed(ip string) {
+	m.failuresMu.Lock()
+	defer m.failuresMu.Unlock()
+	m.failures[ip] = time.Now().Add(failoverCooldown)
+}
+
+func (m *DnsCacheManager) isIPHealthy(ip string) bool {
+	m.failuresMu.Lock()
+	defer m.failuresMu.Unlock()
+	until, failed := m.failures[ip]
+	return !failed || time.Now().After(until)
+}
+
+// getFailoverIp round-robins across the addresses in ips that haven't
+// recently failed to dial, so healthy AZs keep sharing the load instead
+// of every request pinning to ips[0]. If every address is currently
+// marked down, it round-robins across all of them anyway rather than
+// failing the request outright.
+func (m *DnsCacheManager) getFailoverIp(host string, ips []string) string {
+	healthy := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		if m.isIPHealthy(ip) {
+			healthy = append(healthy, ip)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = ips
+	}
+
+	m.rrMu.Lock()
+	idx := m.rrIndex[host] % len(healthy)
+	m.rrIndex[host] = (idx + 1) % len(healthy)
+	m.rrMu.Unlock()
+
+	return healthy[idx]
+}
+
 func (m *DnsCacheManager) getRandomIp(ips []string) (string, error) {
 	if m.strategy != config.RandomStrategy {
 		return "", fmt.Errorf(
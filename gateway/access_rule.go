@@ -0,0 +1,244 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type accessRuleTokenKind int
+
+const (
+	accessRuleTokEOF accessRuleTokenKind = iota
+	accessRuleTokLParen
+	accessRuleTokRParen
+	accessRuleTokAnd
+	accessRuleTokOr
+	accessRuleTokNot
+	accessRuleTokEq
+	accessRuleTokNeq
+	accessRuleTokIdent
+	accessRuleTokString
+)
+
+type accessRuleToken struct {
+	kind accessRuleTokenKind
+	val  string
+}
+
+func isAccessRuleIdentChar(c byte) bool {
+	return c == '.' || c == '_' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func lexAccessRule(rule string) ([]accessRuleToken, error) {
+	var toks []accessRuleToken
+	i, n := 0, len(rule)
+	for i < n {
+		c := rule[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			toks = append(toks, accessRuleToken{accessRuleTokLParen, "("})
+			i++
+		case c == ')':
+			toks = append(toks, accessRuleToken{accessRuleTokRParen, ")"})
+			i++
+		case c == '&' && i+1 < n && rule[i+1] == '&':
+			toks = append(toks, accessRuleToken{accessRuleTokAnd, "&&"})
+			i += 2
+		case c == '|' && i+1 < n && rule[i+1] == '|':
+			toks = append(toks, accessRuleToken{accessRuleTokOr, "||"})
+			i += 2
+		case c == '!' && i+1 < n && rule[i+1] == '=':
+			toks = append(toks, accessRuleToken{accessRuleTokNeq, "!="})
+			i += 2
+		case c == '!':
+			toks = append(toks, accessRuleToken{accessRuleTokNot, "!"})
+			i++
+		case c == '=' && i+1 < n && rule[i+1] == '=':
+			toks = append(toks, accessRuleToken{accessRuleTokEq, "=="})
+			i += 2
+		case c == '"' || c == '\'':
+			quote := c
+			j := i + 1
+			for j < n && rule[j] != quote {
+				j++
+			}
+			if j >= n {
+				return nil, fmt.Errorf("unterminated string literal at position %d", i)
+			}
+			toks = append(toks, accessRuleToken{accessRuleTokString, rule[i+1 : j]})
+			i = j + 1
+		default:
+			j := i
+			for j < n && isAccessRuleIdentChar(rule[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			toks = append(toks, accessRuleToken{accessRuleTokIdent, rule[i:j]})
+			i = j
+		}
+	}
+	return append(toks, accessRuleToken{accessRuleTokEOF, ""}), nil
+}
+
+// accessRuleParser evaluates the token stream directly rather than building
+// a separate AST, since the grammar is small enough that the recursive
+// descent functions double as the evaluator.
+type accessRuleParser struct {
+	toks []accessRuleToken
+	pos  int
+	ctx  map[string]interface{}
+}
+
+func (p *accessRuleParser) peek() accessRuleToken {
+	return p.toks[p.pos]
+}
+
+func (p *accessRuleParser) next() accessRuleToken {
+	t := p.toks[p.pos]
+	if p.pos < len(p.toks)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *accessRuleParser) parseOr() (bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return false, err
+	}
+	for p.peek().kind == accessRuleTokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return false, err
+		}
+		left = left || right
+	}
+	return left, nil
+}
+
+func (p *accessRuleParser) parseAnd() (bool, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return false, err
+	}
+	for p.peek().kind == accessRuleTokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return false, err
+		}
+		left = left && right
+	}
+	return left, nil
+}
+
+func (p *accessRuleParser) parseUnary() (bool, error) {
+	if p.peek().kind == accessRuleTokNot {
+		p.next()
+		v, err := p.parseUnary()
+		return !v, err
+	}
+	return p.parsePrimary()
+}
+
+func (p *accessRuleParser) parsePrimary() (bool, error) {
+	if p.peek().kind == accessRuleTokLParen {
+		p.next()
+		v, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if p.peek().kind != accessRuleTokRParen {
+			return false, errors.New("expected closing parenthesis")
+		}
+		p.next()
+		return v, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *accessRuleParser) parseOperand() (string, error) {
+	tok := p.next()
+	switch tok.kind {
+	case accessRuleTokString:
+		return tok.val, nil
+	case accessRuleTokIdent:
+		return accessRuleLookup(p.ctx, tok.val), nil
+	}
+	return "", fmt.Errorf("expected a value, got %q", tok.val)
+}
+
+func (p *accessRuleParser) parseComparison() (bool, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return false, err
+	}
+
+	op := p.next()
+	if op.kind != accessRuleTokEq && op.kind != accessRuleTokNeq {
+		return false, fmt.Errorf("expected == or !=, got %q", op.val)
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return false, err
+	}
+
+	if op.kind == accessRuleTokEq {
+		return left == right, nil
+	}
+	return left != right, nil
+}
+
+// accessRuleLookup resolves a dotted path (e.g. "claims.role") against a
+// tree of nested map[string]interface{}, returning "" if any segment is
+// missing so a rule can compare against an absent claim without erroring.
+func accessRuleLookup(ctx map[string]interface{}, path string) string {
+	var cur interface{} = ctx
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		cur, ok = m[part]
+		if !ok {
+			return ""
+		}
+	}
+	if cur == nil {
+		return ""
+	}
+	return fmt.Sprint(cur)
+}
+
+// evalAccessRule evaluates a small boolean expression combining ==, !=,
+// &&, ||  and ! over dotted paths into ctx against string literals, e.g.
+// `claims.role == "admin" && request.method != "DELETE"`. It's a
+// hand-written expression evaluator, not a Rego/OPA engine - OPA isn't
+// vendored in this repository, so this only covers straightforward
+// equality-based rules rather than the full Rego language.
+func evalAccessRule(rule string, ctx map[string]interface{}) (bool, error) {
+	toks, err := lexAccessRule(rule)
+	if err != nil {
+		return false, err
+	}
+
+	p := &accessRuleParser{toks: toks, ctx: ctx}
+	result, err := p.parseOr()
+	if err != nil {
+		return false, err
+	}
+
+	if p.peek().kind != accessRuleTokEOF {
+		return false, fmt.Errorf("unexpected trailing token %q", p.peek().val)
+	}
+
+	return result, nil
+}
@@ -301,6 +301,10 @@ func (r *RedisAnalyticsHandler) recordWorker() {
 				record.RawPath = "/" + record.RawPath
 			}
 
+			// Stream a copy to this API's archive buffer, if it has one
+			// configured, so long-term retention doesn't depend on the pump.
+			archiveAnalyticsRecord(record)
+
 			if encoded, err := msgpack.Marshal(record); err != nil {
 				log.WithError(err).Error("Error encoding analytics data")
 			} else {
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const obfuscatedPlaceholder = "--"
+
+// effectiveObfuscation returns the API's own obfuscation rules when
+// enabled, falling back to the gateway-wide rules otherwise.
+func effectiveObfuscation(global, api apidef.AnalyticsObfuscation) apidef.AnalyticsObfuscation {
+	if api.Enabled {
+		return api
+	}
+	return global
+}
+
+func hashForObfuscation(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
+
+// legacyObfuscateKey is the original last-4-chars scheme, used when
+// obfuscation isn't explicitly configured.
+func legacyObfuscateKey(keyName string) string {
+	if len(keyName) > 4 {
+		return "****" + keyName[len(keyName)-4:]
+	}
+	return obfuscatedPlaceholder
+}
+
+// obfuscateKeyWith renders key according to conf.
+func obfuscateKeyWith(key string, conf apidef.AnalyticsObfuscation) string {
+	if !conf.Enabled {
+		return legacyObfuscateKey(key)
+	}
+
+	switch conf.KeyMode {
+	case apidef.KeyObfuscationHash:
+		return hashForObfuscation(key)
+	case apidef.KeyObfuscationNone:
+		return obfuscatedPlaceholder
+	default: // suffix
+		visible := conf.KeyVisibleChars
+		if visible <= 0 {
+			visible = 4
+		}
+		if len(key) > visible {
+			return strings.Repeat("*", 4) + key[len(key)-visible:]
+		}
+		return obfuscatedPlaceholder
+	}
+}
+
+// obfuscateIP renders ipStr according to conf. It is left untouched when
+// conf isn't enabled or its mode is "none"/unset.
+func obfuscateIP(ipStr string, conf apidef.AnalyticsObfuscation) string {
+	if !conf.Enabled || ipStr == "" {
+		return ipStr
+	}
+
+	switch conf.IPMode {
+	case apidef.IPObfuscationHash:
+		return hashForObfuscation(ipStr)
+	case apidef.IPObfuscationTruncate:
+		return truncateIP(ipStr, conf.IPTruncateOctets)
+	default:
+		return ipStr
+	}
+}
+
+func truncateIP(ipStr string, octets int) string {
+	if octets <= 0 {
+		octets = 1
+	}
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return ipStr
+	}
+	if v4 := ip.To4(); v4 != nil {
+		for i := 0; i < octets && i < 4; i++ {
+			v4[len(v4)-1-i] = 0
+		}
+		return v4.String()
+	}
+	v6 := ip.To16()
+	if v6 == nil {
+		return ipStr
+	}
+	bytesToZero := octets * 2
+	for i := 0; i < bytesToZero && i < len(v6); i++ {
+		v6[len(v6)-1-i] = 0
+	}
+	return v6.String()
+}
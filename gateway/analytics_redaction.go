@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const redactedPlaceholder = "[REDACTED]"
+
+var redactionRegexCache sync.Map // pattern string -> *regexp.Regexp
+
+func compileRedactionRegex(pattern string) *regexp.Regexp {
+	if cached, ok := redactionRegexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.WithError(err).WithField("pattern", pattern).Warning("Invalid analytics redaction regex")
+		re = nil
+	}
+
+	redactionRegexCache.Store(pattern, re)
+	return re
+}
+
+// effectiveRedaction returns the API's own redaction rules when enabled,
+// falling back to the gateway-wide rules otherwise.
+func effectiveRedaction(global, api apidef.AnalyticsRedaction) apidef.AnalyticsRedaction {
+	if api.Enabled {
+		return api
+	}
+	return global
+}
+
+// redactHeaders removes the configured header names (case-insensitive)
+// from h in place.
+func redactHeaders(h http.Header, names []string) {
+	for _, name := range names {
+		h.Del(name)
+	}
+}
+
+// redactBytes replaces every match of the configured regexes with a
+// fixed placeholder, so recorded payloads never carry PII/PAN data even
+// as the shape of the field changes over time.
+func redactBytes(b []byte, patterns []string) []byte {
+	for _, pattern := range patterns {
+		re := compileRedactionRegex(pattern)
+		if re == nil {
+			continue
+		}
+		b = re.ReplaceAll(b, []byte(redactedPlaceholder))
+	}
+	return b
+}
@@ -0,0 +1,178 @@
+package gateway
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// EventAnomalyDetected fires when an API's rolling short-term behaviour
+// deviates from its longer-term baseline by more than its configured sigma
+// threshold, giving basic anomaly alerting at the edge without needing an
+// external monitoring stack.
+const EventAnomalyDetected apidef.TykEvent = "AnomalyDetected"
+
+// EventAnomalyDetectedMeta is the metadata structure fired with
+// EventAnomalyDetected.
+type EventAnomalyDetectedMeta struct {
+	EventMetaDefault
+	APIID    string
+	Metric   string
+	Baseline float64
+	Current  float64
+	StdDev   float64
+	Sigma    float64
+}
+
+const (
+	// anomalyBaselineAlpha is the EWMA weight for the slow-moving baseline -
+	// small, so it takes a long run of requests to shift.
+	anomalyBaselineAlpha = 0.02
+	// anomalyRecentAlpha is the EWMA weight for the fast-moving recent
+	// window that gets compared against the baseline.
+	anomalyRecentAlpha = 0.2
+
+	defaultAnomalySigmaThreshold = 3.0
+	defaultAnomalyMinSamples     = 100
+)
+
+// anomalyMetric tracks a slow-moving EWMA baseline (mean and variance)
+// alongside a faster-moving EWMA of recent behaviour for a single signal
+// (latency, error rate, request interval), so a request can be compared
+// against "usual" without keeping a history of individual samples.
+type anomalyMetric struct {
+	baselineMean float64
+	baselineVar  float64
+	recentMean   float64
+	samples      int64
+}
+
+func (m *anomalyMetric) add(value float64) {
+	m.samples++
+
+	if m.samples == 1 {
+		m.baselineMean = value
+		m.recentMean = value
+		return
+	}
+
+	delta := value - m.baselineMean
+	m.baselineMean += anomalyBaselineAlpha * delta
+	m.baselineVar = (1 - anomalyBaselineAlpha) * (m.baselineVar + anomalyBaselineAlpha*delta*delta)
+
+	m.recentMean += anomalyRecentAlpha * (value - m.recentMean)
+}
+
+// sigmasFromBaseline reports how many standard deviations recentMean
+// currently sits from baselineMean, or 0 if there isn't enough spread in
+// the baseline yet to call anything a deviation.
+func (m *anomalyMetric) sigmasFromBaseline() float64 {
+	stdDev := math.Sqrt(m.baselineVar)
+	if stdDev == 0 {
+		return 0
+	}
+	return math.Abs(m.recentMean-m.baselineMean) / stdDev
+}
+
+// anomalyTracker holds the rolling baselines for one API: request latency,
+// error rate (as a 0/1 EWMA) and traffic, approximated by the EWMA of the
+// interval between requests since a shorter interval means more traffic.
+type anomalyTracker struct {
+	latency         anomalyMetric
+	errors          anomalyMetric
+	requestInterval anomalyMetric
+	lastRequest     time.Time
+}
+
+var (
+	anomalyTrackersMu sync.Mutex
+	anomalyTrackers   = map[string]*anomalyTracker{}
+
+	// anomalyCooldown suppresses repeat EventAnomalyDetected firings for
+	// the same API+metric while the underlying condition is still true,
+	// reusing the same Redis-backed cooldown built for alert notifiers.
+	anomalyCooldown = newAlertCooldown("anomaly.", 300)
+)
+
+func getAnomalyTracker(apiID string) *anomalyTracker {
+	anomalyTrackersMu.Lock()
+	defer anomalyTrackersMu.Unlock()
+
+	t, ok := anomalyTrackers[apiID]
+	if !ok {
+		t = &anomalyTracker{}
+		anomalyTrackers[apiID] = t
+	}
+	return t
+}
+
+// recordAnomalySample feeds one request's outcome into spec's rolling
+// baselines and fires EventAnomalyDetected for any signal that has drifted
+// beyond spec.AnomalyDetection.SigmaThreshold standard deviations from its
+// baseline.
+func recordAnomalySample(spec *APISpec, code int, latencyMs int64) {
+	cfg := spec.AnomalyDetection
+	if !cfg.Enabled {
+		return
+	}
+
+	sigma := cfg.SigmaThreshold
+	if sigma <= 0 {
+		sigma = defaultAnomalySigmaThreshold
+	}
+
+	minSamples := int64(cfg.MinSamples)
+	if minSamples <= 0 {
+		minSamples = defaultAnomalyMinSamples
+	}
+
+	tracker := getAnomalyTracker(spec.APIID)
+
+	errVal := 0.0
+	if code >= 500 {
+		errVal = 1.0
+	}
+
+	tracker.latency.add(float64(latencyMs))
+	tracker.errors.add(errVal)
+
+	now := time.Now()
+	if !tracker.lastRequest.IsZero() {
+		tracker.requestInterval.add(float64(now.Sub(tracker.lastRequest).Nanoseconds()) / 1e6)
+	}
+	tracker.lastRequest = now
+
+	if tracker.latency.samples < minSamples {
+		return
+	}
+
+	checkAnomaly(spec, "latency_ms", &tracker.latency, sigma)
+	checkAnomaly(spec, "error_rate", &tracker.errors, sigma)
+	checkAnomaly(spec, "request_interval_ms", &tracker.requestInterval, sigma)
+}
+
+// checkAnomaly fires EventAnomalyDetected for metric if it has drifted
+// beyond sigma standard deviations from its baseline and isn't currently in
+// its cooldown window.
+func checkAnomaly(spec *APISpec, metric string, m *anomalyMetric, sigma float64) {
+	deviation := m.sigmasFromBaseline()
+	if deviation < sigma {
+		return
+	}
+
+	if !anomalyCooldown.allow(apidef.TykEvent(spec.APIID + ":" + metric)) {
+		return
+	}
+
+	spec.FireEvent(EventAnomalyDetected, EventAnomalyDetectedMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Anomaly detected in " + metric + " for " + spec.APIID},
+		APIID:            spec.APIID,
+		Metric:           metric,
+		Baseline:         m.baselineMean,
+		Current:          m.recentMean,
+		StdDev:           math.Sqrt(m.baselineVar),
+		Sigma:            deviation,
+	})
+}
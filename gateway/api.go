@@ -3,24 +3,24 @@
 // The code below describes the Tyk Gateway API
 // Version: 2.8.0
 //
-//     Schemes: https, http
-//     Host: localhost
-//     BasePath: /tyk/
+//	Schemes: https, http
+//	Host: localhost
+//	BasePath: /tyk/
 //
-//     Consumes:
-//     - application/json
+//	Consumes:
+//	- application/json
 //
-//     Produces:
-//     - application/json
+//	Produces:
+//	- application/json
 //
-//     Security:
-//     - api_key:
+//	Security:
+//	- api_key:
 //
-//     SecurityDefinitions:
-//     api_key:
-//          type: apiKey
-//          name: X-Tyk-Authorization
-//          in: header
+//	SecurityDefinitions:
+//	api_key:
+//	     type: apiKey
+//	     name: X-Tyk-Authorization
+//	     in: header
 //
 // swagger:meta
 package gateway
@@ -391,10 +391,16 @@ func handleAddOrUpdate(keyName string, r *http.Request, isHashed bool) (interfac
 		}
 	}
 
+	if !checkAliasAvailable(newSession.Alias, keyName) {
+		return apiError("Alias is already in use by another key"), http.StatusConflict
+	}
+
 	if err := doAddOrUpdate(keyName, &newSession, suppressReset, isHashed); err != nil {
 		return apiError("Failed to create key, ensure security settings are correct."), http.StatusInternalServerError
 	}
 
+	setAlias(newSession.Alias, originalKey.Alias, keyName)
+
 	action := "modified"
 	event := EventTokenUpdated
 	if r.Method == http.MethodPost {
@@ -587,6 +593,10 @@ func handleAddKey(keyName, hashedName, sessionString, apiID string) {
 }
 
 func handleDeleteKey(keyName, apiID string, resetQuota bool) (interface{}, int) {
+	if session, found := getKeyDetail(keyName, apiID, false); found {
+		removeAlias(session.Alias)
+	}
+
 	if apiID == "-1" {
 		// Go through ALL managed API's and delete the key
 		apisMu.RLock()
@@ -818,8 +828,17 @@ func handleDeleteAPI(apiID string) (interface{}, int) {
 	return response, http.StatusOK
 }
 
+// apiOwnedByOrg reports whether apiID belongs to orgID, so an
+// organisation-scoped control API token can be denied access to APIs
+// outside its own org.
+func apiOwnedByOrg(apiID, orgID string) bool {
+	spec := getApiSpec(apiID)
+	return spec != nil && spec.OrgID == orgID
+}
+
 func apiHandler(w http.ResponseWriter, r *http.Request) {
 	apiID := mux.Vars(r)["apiID"]
+	orgID := ctxGetControlAPIOrgID(r)
 
 	var obj interface{}
 	var code int
@@ -827,34 +846,71 @@ func apiHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
 		if apiID != "" {
+			if orgID != "" && !apiOwnedByOrg(apiID, orgID) {
+				doJSONWrite(w, http.StatusNotFound, apiError("API not found"))
+				return
+			}
 			log.Debug("Requesting API definition for", apiID)
 			obj, code = handleGetAPI(apiID)
 		} else {
 			log.Debug("Requesting API list")
 			obj, code = handleGetAPIList()
+			if orgID != "" {
+				obj, code = filterAPIListByOrg(obj, code, orgID)
+			}
 		}
 	case "POST":
+		if orgID != "" {
+			// Creating a brand new API is an org-boundary decision
+			// (which org it belongs to) that self-service tokens
+			// aren't trusted to make - only updates to an org's
+			// existing APIs go through the PUT branch below.
+			doJSONWrite(w, http.StatusForbidden, apiError("Organisation tokens cannot create new APIs"))
+			return
+		}
 		log.Debug("Creating new definition file")
 		obj, code = handleAddOrUpdateApi(apiID, r)
 	case "PUT":
-		if apiID != "" {
+		if apiID == "" {
+			obj, code = apiError("Must specify an apiID to update"), http.StatusBadRequest
+		} else if orgID != "" && !apiOwnedByOrg(apiID, orgID) {
+			obj, code = apiError("API not found"), http.StatusNotFound
+		} else {
 			log.Debug("Updating existing API: ", apiID)
 			obj, code = handleAddOrUpdateApi(apiID, r)
-		} else {
-			obj, code = apiError("Must specify an apiID to update"), http.StatusBadRequest
 		}
 	case "DELETE":
-		if apiID != "" {
+		if apiID == "" {
+			obj, code = apiError("Must specify an apiID to delete"), http.StatusBadRequest
+		} else if orgID != "" && !apiOwnedByOrg(apiID, orgID) {
+			obj, code = apiError("API not found"), http.StatusNotFound
+		} else {
 			log.Debug("Deleting API definition for: ", apiID)
 			obj, code = handleDeleteAPI(apiID)
-		} else {
-			obj, code = apiError("Must specify an apiID to delete"), http.StatusBadRequest
 		}
 	}
 
 	doJSONWrite(w, code, obj)
 }
 
+// filterAPIListByOrg narrows a handleGetAPIList result down to the
+// definitions owned by orgID, so an organisation-scoped token listing APIs
+// only ever sees its own.
+func filterAPIListByOrg(obj interface{}, code int, orgID string) (interface{}, int) {
+	list, ok := obj.([]*apidef.APIDefinition)
+	if !ok {
+		return obj, code
+	}
+
+	filtered := make([]*apidef.APIDefinition, 0)
+	for _, def := range list {
+		if def.OrgID == orgID {
+			filtered = append(filtered, def)
+		}
+	}
+	return filtered, code
+}
+
 func keyHandler(w http.ResponseWriter, r *http.Request) {
 	keyName := mux.Vars(r)["keyName"]
 	apiID := r.URL.Query().Get("api_id")
@@ -868,6 +924,18 @@ func keyHandler(w http.ResponseWriter, r *http.Request) {
 		keyName = generateToken(orgID, keyName)
 	}
 
+	// An organisation-scoped control API token has no way to identify
+	// which keys are its own unless the request is pinned to one of its
+	// own APIs, so we require api_id and reject anything broader (an
+	// unscoped key, or a global key listing) rather than risk leaking
+	// another tenant's keys.
+	if callerOrgID := ctxGetControlAPIOrgID(r); callerOrgID != "" {
+		if apiID == "" || !apiOwnedByOrg(apiID, callerOrgID) {
+			doJSONWrite(w, http.StatusForbidden, apiError("A valid api_id owned by your organisation is required"))
+			return
+		}
+	}
+
 	var obj interface{}
 	var code int
 	hashKeyFunction := config.Global().HashKeyFunction
@@ -930,6 +998,77 @@ func keyHandler(w http.ResponseWriter, r *http.Request) {
 	doJSONWrite(w, code, obj)
 }
 
+// keyUsageStreamPollInterval controls how often keyUsageStreamHandler
+// re-checks a key's quota/rate usage and pushes an update to the client.
+const keyUsageStreamPollInterval = 2 * time.Second
+
+// KeyUsageUpdate is a single push update sent by keyUsageStreamHandler,
+// carrying just the fields a dashboard usage widget needs rather than the
+// whole session object.
+type KeyUsageUpdate struct {
+	QuotaMax       int64   `json:"quota_max"`
+	QuotaRemaining int64   `json:"quota_remaining"`
+	QuotaRenews    int64   `json:"quota_renews"`
+	Rate           float64 `json:"rate"`
+	Per            float64 `json:"per"`
+}
+
+// keyUsageStreamHandler streams a key's quota/rate usage to the client as it
+// changes, as a Server-Sent Events subscription, so a partner portal
+// dashboard widget can show live usage instead of polling the key detail
+// endpoint itself.
+func keyUsageStreamHandler(w http.ResponseWriter, r *http.Request) {
+	keyName := mux.Vars(r)["keyName"]
+	apiID := r.URL.Query().Get("api_id")
+	isHashed := r.URL.Query().Get("hashed") != ""
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Streaming not supported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(keyUsageStreamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		obj, code := handleGetDetail(keyName, apiID, isHashed)
+		if code != http.StatusOK {
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", apiError("Key not found").Message)
+			flusher.Flush()
+			return
+		}
+
+		session := obj.(user.SessionState)
+		update := KeyUsageUpdate{
+			QuotaMax:       session.QuotaMax,
+			QuotaRemaining: session.QuotaRemaining,
+			QuotaRenews:    session.QuotaRenews,
+			Rate:           session.Rate,
+			Per:            session.Per,
+		}
+
+		payload, err := json.Marshal(update)
+		if err != nil {
+			return
+		}
+
+		fmt.Fprintf(w, "data: %s\n\n", payload)
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 type PolicyUpdateObj struct {
 	Policy        string   `json:"policy"`
 	ApplyPolicies []string `json:"apply_policies"`
@@ -1069,6 +1208,13 @@ func handleOrgAddOrUpdate(keyName string, r *http.Request) (interface{}, int) {
 		spec.Unlock()
 	}
 
+	// Drop cached org session/expiry data on this node and tell the rest of
+	// the cluster to do the same, so the new rate/quota settings apply to
+	// the very next request instead of trailing SessionCache/ExpiryCache TTLs.
+	SessionCache.Delete(keyName)
+	ExpiryCache.Delete(keyName)
+	MainNotifier.Notify(Notification{Command: NoticeOrgSessionUpdate, Payload: keyName})
+
 	log.WithFields(logrus.Fields{
 		"prefix": "api",
 		"org":    keyName,
@@ -1188,16 +1334,45 @@ func groupResetHandler(w http.ResponseWriter, r *http.Request) {
 // was in the URL parameters, it will block until the reload is done.
 // Otherwise, it won't block and fn will be called once the reload is
 // finished.
-//
+// reloadStatusMessage is the response for a blocking reload - it's just
+// apiStatusMessage plus any dead upstream targets probeTargets found while
+// the reload it just waited on was applying, so a typo'd target is visible
+// in the reload response itself rather than only in the logs/events.
+type reloadStatusMessage struct {
+	apiStatusMessage
+	Warnings   []DeadTargetWarning `json:"warnings,omitempty"`
+	Generation uint64              `json:"generation"`
+}
+
+// reloadScopeFromRequest reads the optional "tags" (comma-separated) and
+// "org_id" query parameters off a /tyk/reload request, so an operator can
+// limit a reload to one segment of a large, segmented installation instead
+// of reprocessing every API on every change. Returns nil - meaning
+// "reload everything", the previous behaviour - if neither was set.
+func reloadScopeFromRequest(r *http.Request) *reloadScope {
+	orgID := r.URL.Query().Get("org_id")
+	tags := r.URL.Query().Get("tags")
+	if orgID == "" && tags == "" {
+		return nil
+	}
+	scope := &reloadScope{orgID: orgID}
+	if tags != "" {
+		scope.tags = strings.Split(tags, ",")
+	}
+	return scope
+}
+
 func resetHandler(fn func()) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var wg sync.WaitGroup
 
-		if fn == nil && r.URL.Query().Get("block") == "true" {
+		blocked := fn == nil && r.URL.Query().Get("block") == "true"
+		scope := reloadScopeFromRequest(r)
+		if blocked {
 			wg.Add(1)
-			reloadURLStructure(wg.Done)
+			reloadURLStructureWithScope(wg.Done, scope)
 		} else {
-			reloadURLStructure(fn)
+			reloadURLStructureWithScope(fn, scope)
 		}
 
 		log.WithFields(logrus.Fields{
@@ -1205,6 +1380,11 @@ func resetHandler(fn func()) http.HandlerFunc {
 		}).Info("Reload URL Structure - Scheduled")
 
 		wg.Wait()
+
+		if blocked {
+			doJSONWrite(w, http.StatusOK, reloadStatusMessage{apiOk(""), lastDeadTargetWarnings(), currentReloadGeneration()})
+			return
+		}
 		doJSONWrite(w, http.StatusOK, apiOk(""))
 	}
 }
@@ -1719,6 +1899,12 @@ func oAuthClientTokensHandler(w http.ResponseWriter, r *http.Request) {
 	apiID := mux.Vars(r)["apiID"]
 	keyName := mux.Vars(r)["keyName"]
 
+	if r.Method == http.MethodDelete {
+		obj, code := revokeOauthClientTokens(keyName, apiID)
+		doJSONWrite(w, code, obj)
+		return
+	}
+
 	apiSpec := getApiSpec(apiID)
 	if apiSpec == nil {
 		log.WithFields(logrus.Fields{
@@ -1771,6 +1957,14 @@ func oAuthClientTokensHandler(w http.ResponseWriter, r *http.Request) {
 	doJSONWrite(w, http.StatusOK, tokens)
 }
 
+func oAuthClientRotateSecretHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := mux.Vars(r)["apiID"]
+	keyName := mux.Vars(r)["keyName"]
+
+	obj, code := rotateOauthClientSecret(keyName, apiID, r)
+	doJSONWrite(w, code, obj)
+}
+
 // Get client details
 func getOauthClientDetails(keyName, apiID string) (interface{}, int) {
 	storageID := oauthClientStorageID(keyName)
@@ -1847,6 +2041,132 @@ func handleDeleteOAuthClient(keyName, apiID string) (interface{}, int) {
 	return statusObj, http.StatusOK
 }
 
+// revokeOauthClientTokens removes every access and refresh token issued to
+// a client, e.g. after the client's credentials are believed compromised,
+// without deleting the client registration itself.
+func revokeOauthClientTokens(keyName, apiID string) (interface{}, int) {
+	apiSpec := getApiSpec(apiID)
+	if apiSpec == nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "api",
+			"apiID":  apiID,
+			"status": "fail",
+			"client": keyName,
+			"err":    "not found",
+		}).Error("Failed to revoke OAuth client tokens")
+
+		return apiError("OAuth Client ID not found"), http.StatusNotFound
+	}
+
+	tokens, err := apiSpec.OAuthManager.OsinServer.Storage.GetClientTokens(keyName)
+	if err != nil {
+		return apiError("Get client tokens failed"), http.StatusInternalServerError
+	}
+
+	for _, token := range tokens {
+		if err := apiSpec.OAuthManager.OsinServer.Storage.RemoveAccess(token.Token); err != nil {
+			log.WithError(err).Warning("Could not remove access token while revoking OAuth client")
+		}
+		if err := apiSpec.OAuthManager.OsinServer.Storage.RemoveRefresh(token.Token); err != nil {
+			log.WithError(err).Warning("Could not remove refresh token while revoking OAuth client")
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"prefix": "api",
+		"apiID":  apiID,
+		"client": keyName,
+		"status": "ok",
+	}).Info("Revoked all OAuth client tokens")
+
+	return apiModifyKeySuccess{Key: keyName, Status: "ok", Action: "revoked"}, http.StatusOK
+}
+
+// oauthSecretRotationRequest is the body accepted by the rotate-secret
+// endpoint.
+//
+// swagger:model
+type oauthSecretRotationRequest struct {
+	// GracePeriod is how many seconds the old secret keeps working
+	// alongside the new one, so callers can roll the new secret out
+	// without a hard cutover. Defaults to 0 (immediate cutover).
+	GracePeriod int64 `json:"grace_period"`
+}
+
+// oauthSecretRotationResponse reports the new secret and when the previous
+// one stops being accepted.
+//
+// swagger:model
+type oauthSecretRotationResponse struct {
+	ClientID              string `json:"client_id"`
+	ClientSecret          string `json:"secret"`
+	PreviousSecretExpires int64  `json:"previous_secret_expires,omitempty"`
+}
+
+// rotateOauthClientSecret issues a new client secret, keeping the old one
+// valid for GracePeriod seconds so credential rollout doesn't require a
+// synchronised deploy of every downstream consumer.
+func rotateOauthClientSecret(keyName, apiID string, r *http.Request) (interface{}, int) {
+	var req oauthSecretRotationRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return apiError("Unmarshalling failed"), http.StatusBadRequest
+		}
+	}
+
+	apiSpec := getApiSpec(apiID)
+	if apiSpec == nil {
+		return apiError("API doesn't exist"), http.StatusNotFound
+	}
+
+	storageID := oauthClientStorageID(keyName)
+	client, err := apiSpec.OAuthManager.OsinServer.Storage.GetExtendedClientNoPrefix(storageID)
+	if err != nil {
+		return apiError("OAuth Client ID not found"), http.StatusNotFound
+	}
+
+	newSecret := base64.StdEncoding.EncodeToString([]byte(uuid.NewV4().String()))
+
+	rotatedClient := OAuthClient{
+		ClientID:             client.GetId(),
+		ClientSecret:         newSecret,
+		ClientRedirectURI:    client.GetRedirectUri(),
+		PolicyID:             client.GetPolicyID(),
+		MetaData:             client.GetUserData(),
+		Description:          client.GetDescription(),
+		PreviousClientSecret: client.GetSecret(),
+	}
+	if req.GracePeriod > 0 {
+		rotatedClient.PreviousSecretExpires = time.Now().Unix() + req.GracePeriod
+	}
+
+	if err := apiSpec.OAuthManager.OsinServer.Storage.SetClient(storageID, &rotatedClient, true); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "api",
+			"apiID":  apiID,
+			"client": keyName,
+			"status": "fail",
+			"err":    err,
+		}).Error("Failed to rotate OAuth client secret")
+
+		return apiError("Failure in storing client data"), http.StatusInternalServerError
+	}
+
+	log.WithFields(logrus.Fields{
+		"prefix":      "api",
+		"apiID":       apiID,
+		"client":      keyName,
+		"gracePeriod": req.GracePeriod,
+		"status":      "ok",
+	}).Info("Rotated OAuth client secret")
+
+	return oauthSecretRotationResponse{
+		ClientID:              rotatedClient.ClientID,
+		ClientSecret:          rotatedClient.ClientSecret,
+		PreviousSecretExpires: rotatedClient.PreviousSecretExpires,
+	}, http.StatusOK
+}
+
 const oAuthNotPropagatedErr = "OAuth client list isn't available or hasn't been propagated yet."
 
 // List Clients
@@ -2017,6 +2337,67 @@ func ctxScheduleSessionUpdate(r *http.Request) {
 	setCtxValue(r, ctx.UpdateSession, true)
 }
 
+// ctxSetQuotaOverage records how many requests over quota this one is, so
+// that a request let through under a policy's overage allowance can still
+// be tagged in analytics and reported via an event, instead of looking
+// identical to ordinary in-quota traffic.
+func ctxSetQuotaOverage(r *http.Request, overage int64) {
+	setCtxValue(r, ctx.QuotaOverage, overage)
+}
+
+func ctxGetQuotaOverage(r *http.Request) int64 {
+	if v := r.Context().Value(ctx.QuotaOverage); v != nil {
+		return v.(int64)
+	}
+	return 0
+}
+
+// ctxSetCertPinningMismatch flags that the upstream TLS handshake was
+// aborted because the presented certificate's public key wasn't in the
+// API's pinned SPKI set, so the request can be tagged distinctly in
+// analytics instead of looking like a generic proxy failure.
+func ctxSetCertPinningMismatch(r *http.Request) {
+	setCtxValue(r, ctx.CertPinningMismatch, true)
+}
+
+func ctxGetCertPinningMismatch(r *http.Request) bool {
+	if v := r.Context().Value(ctx.CertPinningMismatch); v != nil {
+		return v.(bool)
+	}
+	return false
+}
+
+// ctxSetControlAPIOrgID records that the caller authenticated to the control
+// API with an organisation-scoped token rather than the global admin
+// secret, so handlers can restrict the request to that org's own
+// APIs/keys/policies. Left unset for the global secret, which remains
+// unrestricted.
+func ctxSetControlAPIOrgID(r *http.Request, orgID string) {
+	setCtxValue(r, ctx.ControlAPIOrgID, orgID)
+}
+
+func ctxGetControlAPIOrgID(r *http.Request) string {
+	if v := r.Context().Value(ctx.ControlAPIOrgID); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// ctxSetCacheStatus records whether RedisCacheMiddleware served this
+// request from cache ("HIT") or is letting it through to the upstream
+// ("MISS"), so HandleResponse can surface it via X-Tyk-Cache-Status when
+// EnableRequestBudgetHeaders is on.
+func ctxSetCacheStatus(r *http.Request, status string) {
+	setCtxValue(r, ctx.CacheStatus, status)
+}
+
+func ctxGetCacheStatus(r *http.Request) string {
+	if v := r.Context().Value(ctx.CacheStatus); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
 func ctxDisableSessionUpdate(r *http.Request) {
 	setCtxValue(r, ctx.UpdateSession, false)
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
@@ -34,7 +35,7 @@ import (
 	"github.com/TykTechnologies/tyk/storage"
 )
 
-//const used by cache middleware
+// const used by cache middleware
 const SAFE_METHODS = "SAFE_METHODS"
 
 const (
@@ -63,10 +64,13 @@ const (
 	Cached
 	Transformed
 	TransformedJQ
+	TransformedJSONata
 	HeaderInjected
 	HeaderInjectedResponse
 	TransformedResponse
 	TransformedJQResponse
+	TransformedJSONataResponse
+	TransformedResponseChain
 	HardTimeout
 	CircuitBreaker
 	URLRewrite
@@ -77,6 +81,13 @@ const (
 	RequestNotTracked
 	ValidateJSONRequest
 	Internal
+	AllowedMethods
+	XMLThreatProtection
+	TypedPathParams
+	ContentTypeConversion
+	ContentTypeConversionResponse
+	EndpointRateLimit
+	PaginationLimit
 )
 
 // RequestStatus is a custom type to avoid collisions
@@ -96,6 +107,9 @@ const (
 	StatusTransformResponse        RequestStatus = "Transformed response"
 	StatusTransformJQ              RequestStatus = "Transformed path with JQ"
 	StatusTransformJQResponse      RequestStatus = "Transformed response with JQ"
+	StatusTransformJSONata         RequestStatus = "Transformed path with JSONata"
+	StatusTransformJSONataResponse RequestStatus = "Transformed response with JSONata"
+	StatusTransformResponseChain   RequestStatus = "Transformed response chain"
 	StatusHeaderInjected           RequestStatus = "Header injected"
 	StatusMethodTransformed        RequestStatus = "Method Transformed"
 	StatusHeaderInjectedResponse   RequestStatus = "Header injected on response"
@@ -109,37 +123,53 @@ const (
 	StatusRequestNotTracked        RequestStatus = "Request Not Tracked"
 	StatusValidateJSON             RequestStatus = "Validate JSON"
 	StatusInternal                 RequestStatus = "Internal path"
+	StatusMethodNotAllowed         RequestStatus = "Method not allowed on this path"
+	StatusEndpointRateLimit        RequestStatus = "Rate limited by endpoint rate limit"
+	StatusPaginationLimit          RequestStatus = "Pagination parameters limited"
 )
 
 // URLSpec represents a flattened specification for URLs, used to check if a proxy URL
 // path is on any of the white, black or ignored lists. This is generated as part of the
 // configuration init
 type URLSpec struct {
-	Spec                      *regexp.Regexp
-	Status                    URLStatus
-	MethodActions             map[string]apidef.EndpointMethodMeta
-	CacheConfig               EndPointCacheMeta
-	TransformAction           TransformSpec
-	TransformResponseAction   TransformSpec
-	TransformJQAction         TransformJQSpec
-	TransformJQResponseAction TransformJQSpec
-	InjectHeaders             apidef.HeaderInjectionMeta
-	InjectHeadersResponse     apidef.HeaderInjectionMeta
-	HardTimeout               apidef.HardTimeoutMeta
-	CircuitBreaker            ExtendedCircuitBreakerMeta
-	URLRewrite                *apidef.URLRewriteMeta
-	VirtualPathSpec           apidef.VirtualMeta
-	RequestSize               apidef.RequestSizeMeta
-	MethodTransform           apidef.MethodTransformMeta
-	TrackEndpoint             apidef.TrackEndpointMeta
-	DoNotTrackEndpoint        apidef.TrackEndpointMeta
-	ValidatePathMeta          apidef.ValidatePathMeta
-	Internal                  apidef.InternalMeta
+	Spec                           *regexp.Regexp
+	Status                         URLStatus
+	MethodActions                  map[string]apidef.EndpointMethodMeta
+	CacheConfig                    EndPointCacheMeta
+	TransformAction                TransformSpec
+	TransformResponseAction        TransformSpec
+	TransformJQAction              TransformJQSpec
+	TransformJQResponseAction      TransformJQSpec
+	TransformJSONataAction         TransformJSONataSpec
+	TransformJSONataResponseAction TransformJSONataSpec
+	TransformResponseChainAction   ResponseTransformChainSpec
+	InjectHeaders                  apidef.HeaderInjectionMeta
+	InjectHeadersResponse          apidef.HeaderInjectionMeta
+	HardTimeout                    apidef.HardTimeoutMeta
+	CircuitBreaker                 ExtendedCircuitBreakerMeta
+	URLRewrite                     *apidef.URLRewriteMeta
+	VirtualPathSpec                apidef.VirtualMeta
+	RequestSize                    apidef.RequestSizeMeta
+	MethodTransform                apidef.MethodTransformMeta
+	TrackEndpoint                  apidef.TrackEndpointMeta
+	DoNotTrackEndpoint             apidef.TrackEndpointMeta
+	ValidatePathMeta               apidef.ValidatePathMeta
+	Internal                       apidef.InternalMeta
+	AllowedMethods                 apidef.AllowedMethodsMeta
+	XMLThreatProtection            apidef.XMLThreatProtectionMeta
+	TypedPathParams                apidef.TypedPathParamsMeta
+	ContentTypeConversion          apidef.ContentTypeConversionMeta
+	ContentTypeConversionResponse  apidef.ContentTypeConversionMeta
+	EndpointRateLimit              apidef.EndpointRateLimitMeta
+	PaginationLimit                apidef.PaginationLimitMeta
 }
 
 type EndPointCacheMeta struct {
-	Method        string
-	CacheKeyRegex string
+	Method            string
+	CacheKeyRegex     string
+	CacheTTL          int64
+	CacheByFields     []string
+	CacheFieldsSource string
 }
 
 type TransformSpec struct {
@@ -150,6 +180,34 @@ type TransformSpec struct {
 type ExtendedCircuitBreakerMeta struct {
 	apidef.CircuitBreakerMeta
 	CB *circuit.Breaker `json:"-"`
+
+	targetBreakersMu sync.Mutex
+	targetBreakers   map[string]*circuit.Breaker
+}
+
+// BreakerFor returns the breaker to use for a given upstream host. When
+// PerTargetBreaker isn't enabled it's just the shared, path-level breaker;
+// otherwise a breaker is lazily created per host so a single bad target
+// gets ejected without tripping the whole path.
+func (e *ExtendedCircuitBreakerMeta) BreakerFor(host string) *circuit.Breaker {
+	if !e.PerTargetBreaker || host == "" {
+		return e.CB
+	}
+
+	e.targetBreakersMu.Lock()
+	defer e.targetBreakersMu.Unlock()
+
+	if e.targetBreakers == nil {
+		e.targetBreakers = make(map[string]*circuit.Breaker)
+	}
+
+	cb, ok := e.targetBreakers[host]
+	if !ok {
+		cb = circuit.NewRateBreaker(e.ThresholdPercent, e.Samples)
+		e.targetBreakers[host] = cb
+	}
+
+	return cb
 }
 
 // APISpec represents a path specification for an API, to avoid enumerating multiple nested lists, a single
@@ -180,13 +238,21 @@ type APISpec struct {
 	HTTPTransportCreated     time.Time
 	WSTransport              http.RoundTripper
 	WSTransportCreated       time.Time
-	GlobalConfig             config.Config
-	OrgHasNoSession          bool
+	// WSMessageTemplate is the compiled per-message payload transform for
+	// proxied WebSocket connections, compiled from
+	// APIDefinition.WebSocketHooks.PayloadTransform - see websocket_hooks.go.
+	WSMessageTemplate *template.Template
+	GlobalConfig      config.Config
+	OrgHasNoSession   bool
 
 	middlewareChain *ChainObject
 
 	shouldRelease bool
 	network       NetworkStats
+
+	// overrides holds persisted per-API runtime overrides merged in at
+	// load time, see applyAPIOverrides.
+	overrides APIOverrides
 }
 
 // Release re;leases all resources associated with API spec
@@ -249,6 +315,10 @@ func (a APIDefinitionLoader) MakeSpec(def *apidef.APIDefinition, logger *logrus.
 		logger = logrus.NewEntry(log)
 	}
 
+	interpolateEnvVars(def)
+
+	applyOASImport(def, logger)
+
 	// parse version expiration time stamps
 	for key, ver := range def.VersionData.Versions {
 		if ver.Expires == "" || ver.Expires == "-1" {
@@ -325,6 +395,20 @@ func (a APIDefinitionLoader) MakeSpec(def *apidef.APIDefinition, logger *logrus.
 		spec.WhiteListEnabled[v.Name] = whiteListSpecs
 	}
 
+	applyAPIOverrides(spec)
+
+	if src := def.WebSocketHooks.PayloadTransform.TemplateSource; def.WebSocketHooks.Enabled && src != "" {
+		var err error
+		if def.WebSocketHooks.PayloadTransform.Mode == apidef.UseBlob {
+			spec.WSMessageTemplate, err = a.loadBlobTemplate(src)
+		} else {
+			spec.WSMessageTemplate, err = a.loadFileTemplate(src)
+		}
+		if err != nil {
+			logger.WithError(err).Error("Failed to load websocket payload transform template")
+		}
+	}
+
 	return spec
 }
 
@@ -419,11 +503,17 @@ func (a APIDefinitionLoader) FromDashboardService(endpoint, secret string) ([]*A
 // FromCloud will connect and download ApiDefintions from a Mongo DB instance.
 func (a APIDefinitionLoader) FromRPC(orgId string) ([]*APISpec, error) {
 	if rpc.IsEmergencyMode() {
-		return LoadDefinitionsFromRPCBackup()
+		if specs, err := LoadDefinitionsFromRPCBackup(); err == nil {
+			return specs, nil
+		}
+		return LoadDefinitionsFromRPCFileBackup()
 	}
 
 	store := RPCStorageHandler{}
 	if !store.Connect() {
+		if specs, fileErr := LoadDefinitionsFromRPCFileBackup(); fileErr == nil {
+			return specs, nil
+		}
 		return nil, errors.New("Can't connect RPC layer")
 	}
 
@@ -442,6 +532,9 @@ func (a APIDefinitionLoader) FromRPC(orgId string) ([]*APISpec, error) {
 		if err := saveRPCDefinitionsBackup(apiCollection); err != nil {
 			return nil, err
 		}
+		if err := saveRPCDefinitionsFileBackup(apiCollection); err != nil {
+			log.Warning(err)
+		}
 	}
 
 	return a.processRPCDefinitions(apiCollection)
@@ -575,6 +668,9 @@ func (a APIDefinitionLoader) compileCachedPathSpec(oldpaths []string, newpaths [
 		a.generateRegex(spec.Path, &newSpec, Cached)
 		newSpec.CacheConfig.Method = spec.Method
 		newSpec.CacheConfig.CacheKeyRegex = spec.CacheKeyRegex
+		newSpec.CacheConfig.CacheTTL = spec.CacheTTL
+		newSpec.CacheConfig.CacheByFields = spec.CacheByFields
+		newSpec.CacheConfig.CacheFieldsSource = spec.CacheFieldsSource
 		// Extend with method actions
 		urlSpec = append(urlSpec, newSpec)
 	}
@@ -651,6 +747,45 @@ func (a APIDefinitionLoader) compileTransformPathSpec(paths []apidef.TemplateMet
 	return urlSpec
 }
 
+// compileTransformResponseChainPathSpec compiles each ordered step of a
+// ResponseTransformChainMeta up front - loading any template steps' templates
+// and handing jq/jsonata steps off to compileChainJQStep/compileChainJSONataStep
+// so a bad expression is caught at load time rather than on the first request -
+// then keeps the whole path if every step compiled cleanly.
+func (a APIDefinitionLoader) compileTransformResponseChainPathSpec(paths []apidef.ResponseTransformChainMeta, stat URLStatus) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+
+		compiledSteps := make([]compiledResponseTransformStep, len(stringSpec.Steps))
+		ok := true
+		for i, step := range stringSpec.Steps {
+			compiled, err := a.compileResponseTransformStep(step)
+			if err != nil {
+				log.Error("Response transform chain step failed to compile, skipping path: ", err)
+				ok = false
+				break
+			}
+			compiledSteps[i] = compiled
+		}
+
+		if !ok {
+			continue
+		}
+
+		newSpec.TransformResponseChainAction = ResponseTransformChainSpec{
+			ResponseTransformChainMeta: stringSpec,
+			Steps:                      compiledSteps,
+		}
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) compileInjectedHeaderSpec(paths []apidef.HeaderInjectionMeta, stat URLStatus) []URLSpec {
 	// transform an extended configuration URL into an array of URLSpecs
 	// This way we can iterate the whole array once, on match we break with status
@@ -742,16 +877,26 @@ func (a APIDefinitionLoader) compileCircuitBreakerPathSpec(paths []apidef.Circui
 				case circuit.BreakerTripped:
 					log.Warning("[PROXY] [CIRCUIT BREAKER] Breaker tripped for path: ", path)
 					log.Debug("Breaker tripped: ", e)
+
+					if spec.LoadShedding.UseCircuitBreakerErrorRate {
+						recordBreakerLoad(spec.APIID, true)
+					}
+
 					// Start a timer function
 
 					if !timerActive {
-						go func(timeout int, breaker *circuit.Breaker) {
-							log.Debug("-- Sleeping for (s): ", timeout)
-							time.Sleep(time.Duration(timeout) * time.Second)
-							log.Debug("-- Resetting breaker")
+						go func(timeout, jitterPercent int, breaker *circuit.Breaker) {
+							sleepFor := time.Duration(timeout) * time.Second
+							if jitterPercent > 0 {
+								jitter := time.Duration(rand.Intn(jitterPercent+1)) * sleepFor / 100
+								sleepFor += jitter
+							}
+							log.Debug("-- Sleeping for: ", sleepFor)
+							time.Sleep(sleepFor)
+							log.Debug("-- Resetting breaker (half-open probe)")
 							breaker.Reset()
 							timerActive = false
-						}(newSpec.CircuitBreaker.ReturnToServiceAfter, breakerPtr)
+						}(newSpec.CircuitBreaker.ReturnToServiceAfter, newSpec.CircuitBreaker.ReturnToServiceJitterPercent, breakerPtr)
 						timerActive = true
 					}
 
@@ -770,6 +915,10 @@ func (a APIDefinitionLoader) compileCircuitBreakerPathSpec(paths []apidef.Circui
 					})
 
 				case circuit.BreakerReset:
+					if spec.LoadShedding.UseCircuitBreakerErrorRate {
+						recordBreakerLoad(spec.APIID, false)
+					}
+
 					// check if this spec is set to release resources
 					if spec.shouldRelease {
 						// time to stop this Go-routine
@@ -870,6 +1019,78 @@ func (a APIDefinitionLoader) compileValidateJSONPathspathSpec(paths []apidef.Val
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileAllowedMethodsPathSpec(paths []apidef.AllowedMethodsMeta, stat URLStatus) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+
+		// Extend with method actions
+		newSpec.AllowedMethods = stringSpec
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
+func (a APIDefinitionLoader) compileXMLThreatProtectionPathSpec(paths []apidef.XMLThreatProtectionMeta, stat URLStatus) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+		newSpec.XMLThreatProtection = stringSpec
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
+// pathParamNameRE captures the {name} placeholders in a path template so
+// they can be turned into named regex capture groups, letting
+// TypedPathParamsMiddleware pull the actual segment value back out by name.
+var pathParamNameRE = regexp.MustCompile(`{([^}]*)}`)
+
+func (a APIDefinitionLoader) compileTypedPathParamsPathSpec(paths []apidef.TypedPathParamsMeta, stat URLStatus) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		curStringSpec := stringSpec
+		newSpec := URLSpec{}
+		a.generateRegex(curStringSpec.Path, &newSpec, stat)
+
+		asRegexStr := pathParamNameRE.ReplaceAllString(curStringSpec.Path, `(?P<$1>[^/]*)`)
+		if namedRegex, err := regexp.Compile(asRegexStr); err == nil {
+			curStringSpec.MatchRegexp = namedRegex
+		} else {
+			log.WithError(err).Error("Could not compile typed path param regex for ", curStringSpec.Path)
+		}
+
+		newSpec.TypedPathParams = curStringSpec
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
+func (a APIDefinitionLoader) compileContentTypeConversionPathSpec(paths []apidef.ContentTypeConversionMeta, stat URLStatus) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+		if stat == ContentTypeConversionResponse {
+			newSpec.ContentTypeConversionResponse = stringSpec
+		} else {
+			newSpec.ContentTypeConversion = stringSpec
+		}
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) compileUnTrackedEndpointPathspathSpec(paths []apidef.TrackEndpointMeta, stat URLStatus) []URLSpec {
 	urlSpec := []URLSpec{}
 
@@ -898,6 +1119,40 @@ func (a APIDefinitionLoader) compileInternalPathspathSpec(paths []apidef.Interna
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileEndpointRateLimitPathSpec(paths []apidef.EndpointRateLimitMeta, stat URLStatus) []URLSpec {
+	// transform an extended configuration URL into an array of URLSpecs
+	// This way we can iterate the whole array once, on match we break with status
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+		// Extend with method actions
+		newSpec.EndpointRateLimit = stringSpec
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
+func (a APIDefinitionLoader) compilePaginationLimitPathSpec(paths []apidef.PaginationLimitMeta, stat URLStatus) []URLSpec {
+	// transform an extended configuration URL into an array of URLSpecs
+	// This way we can iterate the whole array once, on match we break with status
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+		// Extend with method actions
+		newSpec.PaginationLimit = stringSpec
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionInfo, apiSpec *APISpec) ([]URLSpec, bool) {
 	// TODO: New compiler here, needs to put data into a different structure
 
@@ -907,8 +1162,11 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	cachedPaths := a.compileCachedPathSpec(apiVersionDef.ExtendedPaths.Cached, apiVersionDef.ExtendedPaths.AdvanceCacheConfig)
 	transformPaths := a.compileTransformPathSpec(apiVersionDef.ExtendedPaths.Transform, Transformed)
 	transformResponsePaths := a.compileTransformPathSpec(apiVersionDef.ExtendedPaths.TransformResponse, TransformedResponse)
+	transformResponseChainPaths := a.compileTransformResponseChainPathSpec(apiVersionDef.ExtendedPaths.TransformResponseChain, TransformedResponseChain)
 	transformJQPaths := a.compileTransformJQPathSpec(apiVersionDef.ExtendedPaths.TransformJQ, TransformedJQ)
 	transformJQResponsePaths := a.compileTransformJQPathSpec(apiVersionDef.ExtendedPaths.TransformJQResponse, TransformedJQResponse)
+	transformJSONataPaths := a.compileTransformJSONataPathSpec(apiVersionDef.ExtendedPaths.TransformJSONata, TransformedJSONata)
+	transformJSONataResponsePaths := a.compileTransformJSONataPathSpec(apiVersionDef.ExtendedPaths.TransformJSONataResponse, TransformedJSONataResponse)
 	headerTransformPaths := a.compileInjectedHeaderSpec(apiVersionDef.ExtendedPaths.TransformHeader, HeaderInjected)
 	headerTransformPathsOnResponse := a.compileInjectedHeaderSpec(apiVersionDef.ExtendedPaths.TransformResponseHeader, HeaderInjectedResponse)
 	hardTimeouts := a.compileTimeoutPathSpec(apiVersionDef.ExtendedPaths.HardTimeouts, HardTimeout)
@@ -921,6 +1179,13 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	unTrackedPaths := a.compileUnTrackedEndpointPathspathSpec(apiVersionDef.ExtendedPaths.DoNotTrackEndpoints, RequestNotTracked)
 	validateJSON := a.compileValidateJSONPathspathSpec(apiVersionDef.ExtendedPaths.ValidateJSON, ValidateJSONRequest)
 	internalPaths := a.compileInternalPathspathSpec(apiVersionDef.ExtendedPaths.Internal, Internal)
+	allowedMethodsPaths := a.compileAllowedMethodsPathSpec(apiVersionDef.ExtendedPaths.AllowedMethods, AllowedMethods)
+	xmlThreatProtectionPaths := a.compileXMLThreatProtectionPathSpec(apiVersionDef.ExtendedPaths.XMLThreatProtection, XMLThreatProtection)
+	typedPathParamsPaths := a.compileTypedPathParamsPathSpec(apiVersionDef.ExtendedPaths.TypedPathParams, TypedPathParams)
+	contentTypeConversionPaths := a.compileContentTypeConversionPathSpec(apiVersionDef.ExtendedPaths.ContentTypeConversion, ContentTypeConversion)
+	contentTypeConversionResponsePaths := a.compileContentTypeConversionPathSpec(apiVersionDef.ExtendedPaths.ContentTypeConversionResponse, ContentTypeConversionResponse)
+	endpointRateLimitPaths := a.compileEndpointRateLimitPathSpec(apiVersionDef.ExtendedPaths.RateLimit, EndpointRateLimit)
+	paginationLimitPaths := a.compilePaginationLimitPathSpec(apiVersionDef.ExtendedPaths.PaginationLimits, PaginationLimit)
 
 	combinedPath := []URLSpec{}
 	combinedPath = append(combinedPath, ignoredPaths...)
@@ -929,8 +1194,11 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	combinedPath = append(combinedPath, cachedPaths...)
 	combinedPath = append(combinedPath, transformPaths...)
 	combinedPath = append(combinedPath, transformResponsePaths...)
+	combinedPath = append(combinedPath, transformResponseChainPaths...)
 	combinedPath = append(combinedPath, transformJQPaths...)
 	combinedPath = append(combinedPath, transformJQResponsePaths...)
+	combinedPath = append(combinedPath, transformJSONataPaths...)
+	combinedPath = append(combinedPath, transformJSONataResponsePaths...)
 	combinedPath = append(combinedPath, headerTransformPaths...)
 	combinedPath = append(combinedPath, headerTransformPathsOnResponse...)
 	combinedPath = append(combinedPath, hardTimeouts...)
@@ -943,6 +1211,13 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	combinedPath = append(combinedPath, unTrackedPaths...)
 	combinedPath = append(combinedPath, validateJSON...)
 	combinedPath = append(combinedPath, internalPaths...)
+	combinedPath = append(combinedPath, allowedMethodsPaths...)
+	combinedPath = append(combinedPath, xmlThreatProtectionPaths...)
+	combinedPath = append(combinedPath, typedPathParamsPaths...)
+	combinedPath = append(combinedPath, contentTypeConversionPaths...)
+	combinedPath = append(combinedPath, contentTypeConversionResponsePaths...)
+	combinedPath = append(combinedPath, endpointRateLimitPaths...)
+	combinedPath = append(combinedPath, paginationLimitPaths...)
 
 	return combinedPath, len(whiteListPaths) > 0
 }
@@ -981,6 +1256,12 @@ func (a *APISpec) getURLStatus(stat URLStatus) RequestStatus {
 		return StatusTransformResponse
 	case TransformedJQResponse:
 		return StatusTransformJQResponse
+	case TransformedJSONata:
+		return StatusTransformJSONata
+	case TransformedJSONataResponse:
+		return StatusTransformJSONataResponse
+	case TransformedResponseChain:
+		return StatusTransformResponseChain
 	case HardTimeout:
 		return StatusHardTimeout
 	case CircuitBreaker:
@@ -1001,6 +1282,20 @@ func (a *APISpec) getURLStatus(stat URLStatus) RequestStatus {
 		return StatusValidateJSON
 	case Internal:
 		return StatusInternal
+	case AllowedMethods:
+		return StatusOk
+	case XMLThreatProtection:
+		return StatusOk
+	case TypedPathParams:
+		return StatusOk
+	case ContentTypeConversion:
+		return StatusOk
+	case ContentTypeConversionResponse:
+		return StatusOk
+	case EndpointRateLimit:
+		return StatusOk
+	case PaginationLimit:
+		return StatusOk
 
 	default:
 		log.Error("URL Status was not one of Ignored, Blacklist or WhiteList! Blocking.")
@@ -1041,6 +1336,15 @@ func (a *APISpec) URLAllowedAndIgnored(r *http.Request, rxPaths []URLSpec, white
 			return EndPointNotAllowed, nil
 		}
 
+		if v.Status == AllowedMethods {
+			for _, method := range v.AllowedMethods.Methods {
+				if strings.EqualFold(method, r.Method) {
+					return a.getURLStatus(v.Status), nil
+				}
+			}
+			return StatusMethodNotAllowed, &v.AllowedMethods
+		}
+
 		if whiteListStatus {
 			// We have a whitelist, nothing gets through unless specifically defined
 			switch v.Status {
@@ -1062,6 +1366,10 @@ func (a *APISpec) URLAllowedAndIgnored(r *http.Request, rxPaths []URLSpec, white
 			return a.getURLStatus(v.Status), &v.TransformJQAction
 		}
 
+		if v.TransformJSONataAction.Expression != "" {
+			return a.getURLStatus(v.Status), &v.TransformJSONataAction
+		}
+
 		// TODO: Fix, Not a great detection method
 		if len(v.InjectHeaders.Path) > 0 {
 			return a.getURLStatus(v.Status), &v.InjectHeaders
@@ -1087,7 +1395,7 @@ func (a *APISpec) CheckSpecMatchesStatus(r *http.Request, rxPaths []URLSpec, mod
 
 	//If url-rewrite middleware was used, call response middleware of original path and not of rewritten path
 	// context variable UrlRewritePath is set by rewrite middleware
-	if mode == TransformedJQResponse || mode == HeaderInjectedResponse || mode == TransformedResponse {
+	if mode == TransformedJQResponse || mode == HeaderInjectedResponse || mode == TransformedResponse || mode == TransformedResponseChain || mode == ContentTypeConversionResponse {
 		matchPath = ctxGetUrlRewritePath(r)
 		method = ctxGetRequestMethod(r)
 		if matchPath == "" {
@@ -1146,6 +1454,18 @@ func (a *APISpec) CheckSpecMatchesStatus(r *http.Request, rxPaths []URLSpec, mod
 			if method == v.TransformJQResponseAction.Method {
 				return true, &v.TransformJQResponseAction
 			}
+		case TransformedJSONata:
+			if method == v.TransformJSONataAction.Method {
+				return true, &v.TransformJSONataAction
+			}
+		case TransformedJSONataResponse:
+			if method == v.TransformJSONataResponseAction.Method {
+				return true, &v.TransformJSONataResponseAction
+			}
+		case TransformedResponseChain:
+			if method == v.TransformResponseChainAction.Method {
+				return true, &v.TransformResponseChainAction
+			}
 		case HardTimeout:
 			if r.Method == v.HardTimeout.Method {
 				return true, &v.HardTimeout.TimeOut
@@ -1182,6 +1502,30 @@ func (a *APISpec) CheckSpecMatchesStatus(r *http.Request, rxPaths []URLSpec, mod
 			if method == v.ValidatePathMeta.Method {
 				return true, &v.ValidatePathMeta
 			}
+		case XMLThreatProtection:
+			if method == v.XMLThreatProtection.Method {
+				return true, &v.XMLThreatProtection
+			}
+		case TypedPathParams:
+			if method == v.TypedPathParams.Method {
+				return true, &v.TypedPathParams
+			}
+		case ContentTypeConversion:
+			if method == v.ContentTypeConversion.Method {
+				return true, &v.ContentTypeConversion
+			}
+		case ContentTypeConversionResponse:
+			if method == v.ContentTypeConversionResponse.Method {
+				return true, &v.ContentTypeConversionResponse
+			}
+		case EndpointRateLimit:
+			if method == v.EndpointRateLimit.Method {
+				return true, &v.EndpointRateLimit
+			}
+		case PaginationLimit:
+			if method == v.PaginationLimit.Method {
+				return true, &v.PaginationLimit
+			}
 		case Internal:
 			if method == v.Internal.Method {
 				return true, &v.Internal
@@ -1267,6 +1611,8 @@ func (a *APISpec) RequestValid(r *http.Request) (bool, RequestStatus, interface{
 	switch status {
 	case EndPointNotAllowed:
 		return false, status, expTime
+	case StatusMethodNotAllowed:
+		return false, status, meta
 	case StatusRedirectFlowByReply:
 		return true, status, meta
 	case StatusOkAndIgnore, StatusCached, StatusTransform,
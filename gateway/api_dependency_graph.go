@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+)
+
+// APIDependencyEdge records one API's dependency on another, so operators can
+// gauge the blast radius of disabling or changing an API before doing so.
+//
+// swagger:model apiDependencyEdge
+type APIDependencyEdge struct {
+	FromID   string `json:"from_id"`
+	FromName string `json:"from_name"`
+	// ToID is empty when the target can't be resolved to a loaded API,
+	// e.g. a tyk:// loop target that doesn't match anything currently
+	// loaded, or a batch call whose target is only known at request time.
+	ToID   string `json:"to_id,omitempty"`
+	ToName string `json:"to_name,omitempty"`
+	// Kind is one of "url_rewrite_loop", "virtual_endpoint_loop" or
+	// "batch_request".
+	Kind string `json:"kind"`
+	Path string `json:"path,omitempty"`
+	// Certain is false when the dependency is only a possibility rather
+	// than a statically resolved target, e.g. batch requests build their
+	// target URLs from the request body at runtime.
+	Certain bool `json:"certain"`
+}
+
+// apiDependencyGraph is the payload returned by the dependency graph
+// endpoint.
+//
+// swagger:model apiDependencyGraph
+type apiDependencyGraph struct {
+	Edges []APIDependencyEdge `json:"edges"`
+}
+
+// loopTarget resolves a tyk://<host> rewrite target against currently loaded
+// APIs, matching the same lookup DummyProxyHandler uses at request time.
+func loopTarget(rewriteTo string) (id, name string, resolved bool) {
+	matches := LoopHostRE.FindStringSubmatch(rewriteTo)
+	if matches == nil {
+		return "", "", false
+	}
+
+	host := matches[1]
+	if host == "self" {
+		return "", "", false
+	}
+
+	target := fuzzyFindAPI(host)
+	if target == nil {
+		return "", "", false
+	}
+
+	return target.APIID, target.Name, true
+}
+
+// buildAPIDependencyGraph walks the loaded API definitions looking for
+// tyk:// URL rewrite loops, virtual endpoints (which may loop at runtime),
+// and batch request support (which can call any API on the gateway based on
+// the request body), and returns one edge per dependency found.
+func buildAPIDependencyGraph(specs []*APISpec) []APIDependencyEdge {
+	var edges []APIDependencyEdge
+
+	for _, spec := range specs {
+		if spec.EnableBatchRequestSupport {
+			edges = append(edges, APIDependencyEdge{
+				FromID:   spec.APIID,
+				FromName: spec.Name,
+				Kind:     "batch_request",
+				Certain:  false,
+			})
+		}
+
+		for _, version := range spec.VersionData.Versions {
+			for _, rewrite := range version.ExtendedPaths.URLRewrite {
+				if !strings.HasPrefix(rewrite.RewriteTo, LoopScheme+"://") {
+					continue
+				}
+
+				toID, toName, resolved := loopTarget(rewrite.RewriteTo)
+				edges = append(edges, APIDependencyEdge{
+					FromID:   spec.APIID,
+					FromName: spec.Name,
+					ToID:     toID,
+					ToName:   toName,
+					Kind:     "url_rewrite_loop",
+					Path:     rewrite.Path,
+					Certain:  resolved,
+				})
+			}
+
+			for _, virtual := range version.ExtendedPaths.Virtual {
+				edges = append(edges, APIDependencyEdge{
+					FromID:   spec.APIID,
+					FromName: spec.Name,
+					Kind:     "virtual_endpoint_loop",
+					Path:     virtual.Path,
+					Certain:  false,
+				})
+			}
+		}
+	}
+
+	return edges
+}
+
+// apiDependencyGraphHandler exposes the dependency graph across all loaded
+// APIs on /tyk/apis/dependency-graph, so operators can see the blast radius
+// of disabling or changing an internal API before doing so.
+func apiDependencyGraphHandler(w http.ResponseWriter, r *http.Request) {
+	apisMu.RLock()
+	specs := make([]*APISpec, len(apiSpecs))
+	copy(specs, apiSpecs)
+	apisMu.RUnlock()
+
+	edges := buildAPIDependencyGraph(specs)
+	doJSONWrite(w, http.StatusOK, apiDependencyGraph{Edges: edges})
+}
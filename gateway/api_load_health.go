@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// maxAPILoadFailures is the number of consecutive load failures an API
+// definition may accumulate before it is automatically disabled instead of
+// being silently skipped via chainDef.Skip on every reload.
+const maxAPILoadFailures = 3
+
+var apiLoadHealth = struct {
+	sync.Mutex
+	failures map[string]int
+	disabled map[string]string
+}{
+	failures: make(map[string]int),
+	disabled: make(map[string]string),
+}
+
+// recordAPILoadFailure tracks a load failure for an API and disables it,
+// firing EventAPIAutoDisabled, once it crosses maxAPILoadFailures in a row.
+// It returns true if the API should be skipped as a result.
+func recordAPILoadFailure(spec *APISpec, reason string) bool {
+	apiLoadHealth.Lock()
+	defer apiLoadHealth.Unlock()
+
+	if _, alreadyDisabled := apiLoadHealth.disabled[spec.APIID]; alreadyDisabled {
+		return true
+	}
+
+	apiLoadHealth.failures[spec.APIID]++
+	if apiLoadHealth.failures[spec.APIID] < maxAPILoadFailures {
+		return false
+	}
+
+	apiLoadHealth.disabled[spec.APIID] = reason
+	spec.FireEvent(EventAPIAutoDisabled, EventAPIAutoDisabledMeta{
+		EventMetaDefault: EventMetaDefault{Message: reason},
+		APIID:            spec.APIID,
+		Reason:           reason,
+	})
+	return true
+}
+
+// clearAPILoadFailure resets the failure count for an API once it loads
+// successfully again.
+func clearAPILoadFailure(apiID string) {
+	apiLoadHealth.Lock()
+	defer apiLoadHealth.Unlock()
+	delete(apiLoadHealth.failures, apiID)
+	delete(apiLoadHealth.disabled, apiID)
+}
+
+// apiAutoDisabledReason returns the reason an API was auto-disabled, if any,
+// so it can be surfaced over the control API instead of the API just
+// disappearing from the router.
+func apiAutoDisabledReason(apiID string) (string, bool) {
+	apiLoadHealth.Lock()
+	defer apiLoadHealth.Unlock()
+	reason, ok := apiLoadHealth.disabled[apiID]
+	return reason, ok
+}
+
+// apiLoadHealthStatus reports whether the given API is currently auto
+// disabled, and why, via the control API.
+//
+// swagger:model apiLoadHealthStatus
+type apiLoadHealthStatus struct {
+	APIID    string `json:"api_id"`
+	Disabled bool   `json:"disabled"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func apiLoadHealthHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := mux.Vars(r)["apiID"]
+
+	reason, disabled := apiAutoDisabledReason(apiID)
+	doJSONWrite(w, http.StatusOK, apiLoadHealthStatus{
+		APIID:    apiID,
+		Disabled: disabled,
+		Reason:   reason,
+	})
+}
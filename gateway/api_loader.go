@@ -2,6 +2,8 @@ package gateway
 
 import (
 	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +12,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/gorilla/mux"
 	"github.com/justinas/alice"
@@ -48,7 +51,7 @@ func prepareStorage() generalStores {
 func skipSpecBecauseInvalid(spec *APISpec, logger *logrus.Entry) bool {
 
 	switch spec.Protocol {
-	case "", "http", "https":
+	case "", "http", "https", "grpc":
 		if spec.Proxy.ListenPath == "" {
 			logger.Error("Listen path is empty")
 			return true
@@ -118,12 +121,29 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 		spec.TagHeaders = lowerCaseHeaders
 	}
 
+	ensureIPBlacklistFeed(spec)
+
+	if spec.WebSocketHooks.Enabled {
+		// WebSocket connections are hijacked and piped byte-for-byte once
+		// the client's Upgrade completes, so they never pass through
+		// chainDef.chain below - the per-message rate limit/transform/event
+		// hooks configured here are enforced by WSDialer/relayWSMessages
+		// instead, against the compiled spec.WSMessageTemplate.
+		logger.Info("WebSocket per-message hooks enabled")
+	}
+
 	if skipSpecBecauseInvalid(spec, logger) {
-		logger.Warning("Spec not valid, skipped!")
+		if config.Global().EnableAPIAutoDisable && recordAPILoadFailure(spec, "spec failed validation on load") {
+			logger.Warning("Spec repeatedly failed validation, auto-disabled!")
+		} else {
+			logger.Warning("Spec not valid, skipped!")
+		}
 		chainDef.Skip = true
 		return &chainDef
 	}
 
+	clearAPILoadFailure(spec.APIID)
+
 	// Expose API only to looping
 	if spec.Internal {
 		chainDef.Skip = true
@@ -199,6 +219,9 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 	if spec.CustomMiddlewareBundle != "" {
 		if err := loadBundle(spec); err != nil {
 			logger.Error("Couldn't load bundle")
+			if config.Global().EnableAPIAutoDisable {
+				recordAPILoadFailure(spec, "bundle could not be fetched: "+err.Error())
+			}
 		}
 		tykBundlePath := filepath.Join(config.Global().MiddlewarePath, "bundles")
 		bundleNameHash := md5.New()
@@ -215,10 +238,21 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 		spec.JSVM.LoadJSPaths(mwPaths, prefix)
 	}
 
+	// Versions can opt out of the API-level bundle and load their own, so
+	// v2 of an API can run a different set of coprocess plugin hooks than
+	// v1 without duplicating the whole API definition. Each entry below
+	// is later spliced into chainArray tagged with its version name, and
+	// only runs for requests resolved to that version.
+	versionMW := loadVersionCustomMiddleware(spec)
+
 	if spec.EnableBatchRequestSupport {
 		addBatchEndpoint(spec, subrouter)
 	}
 
+	if spec.EnableJWT && spec.JWTSource != "" {
+		addTokenExchangeEndpoint(spec, subrouter)
+	}
+
 	if spec.UseOauth2 {
 		logger.Debug("Loading OAuth Manager")
 		oauthManager := addOAuthHandlers(spec, subrouter)
@@ -236,6 +270,13 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 		}
 	}
 
+	// In sidecar mode all inbound traffic is transparently forwarded to
+	// the local application, regardless of the target URL declared by
+	// the API definition.
+	if config.Global().SidecarMode && config.Global().SidecarLocalAppAddress != "" {
+		spec.Proxy.TargetURL = config.Global().SidecarLocalAppAddress
+	}
+
 	// Already vetted
 	spec.target, _ = url.Parse(spec.Proxy.TargetURL)
 
@@ -248,7 +289,7 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 	}
 
 	// Create the response processors, pass all the loaded custom middleware response functions:
-	createResponseMiddlewareChain(spec, mwResponseFuncs)
+	createResponseMiddlewareChain(spec, mwResponseFuncs, mwDriver)
 
 	baseMid := BaseMiddleware{Spec: spec, Proxy: proxy, logger: logger}
 
@@ -274,26 +315,34 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 		logger.Info("Checking security policy: Open")
 	}
 
+	handleRequestConcurrencyControl(&chainArray)
 	handleCORS(&chainArray, spec)
 
+	mwAppendEnabled(&chainArray, &HTTPSEnforceMiddleware{BaseMiddleware: baseMid})
+
 	for _, obj := range mwPreFuncs {
 		if mwDriver == apidef.GoPluginDriver {
-			mwAppendEnabled(
-				&chainArray,
-				&GoPluginMiddleware{
-					BaseMiddleware: baseMid,
-					Path:           obj.Path,
-					SymbolName:     obj.Name,
-				},
-			)
+			mwAppendGoPlugin(&chainArray, baseMid, obj)
 		} else if mwDriver != apidef.OttoDriver {
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", mwDriver)
-			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Pre, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Pre, obj.Name, mwDriver, obj.RawBodyOnly, "", nil})
 		} else {
 			chainArray = append(chainArray, createDynamicMiddleware(obj.Name, true, obj.RequireSession, baseMid))
 		}
 	}
 
+	for _, vmw := range versionMW {
+		if vmw.driver == apidef.OttoDriver || vmw.driver == apidef.GoPluginDriver {
+			// Version-scoped bundles are only supported for the coprocess
+			// drivers (Python/Lua/gRPC); see loadVersionCustomMiddleware.
+			continue
+		}
+		for _, obj := range vmw.preFuncs {
+			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", vmw.driver, ", version: ", vmw.versionName)
+			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Pre, obj.Name, vmw.driver, obj.RawBodyOnly, vmw.versionName, nil})
+		}
+	}
+
 	mwAppendEnabled(&chainArray, &RateCheckMW{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &IPWhiteListMiddleware{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &IPBlackListMiddleware{BaseMiddleware: baseMid})
@@ -301,94 +350,120 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 	mwAppendEnabled(&chainArray, &OrganizationMonitor{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &VersionCheck{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &RequestSizeLimitMiddleware{baseMid})
+	// WAFMiddleware buffers the request body to scan it, so it must run after
+	// RequestSizeLimitMiddleware has had a chance to reject an oversized
+	// request outright.
+	mwAppendEnabled(&chainArray, &WAFMiddleware{BaseMiddleware: baseMid})
+	mwAppendEnabled(&chainArray, &PaginationLimitMiddleware{baseMid})
 	mwAppendEnabled(&chainArray, &MiddlewareContextVars{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &TrackEndpointMiddleware{baseMid})
 
 	if !spec.UseKeylessAccess {
-		// Select the keying method to use for setting session states
-		if mwAppendEnabled(&authArray, &Oauth2KeyExists{baseMid}) {
-			logger.Info("Checking security policy: OAuth")
-		}
+		if spec.AuthComposition.Enabled && len(spec.AuthComposition.Mechanisms) > 0 {
+			logger.Info("Checking security policy: Auth Composition (", spec.AuthComposition.Mode, ")")
+			mwAppendEnabled(&authArray, &AuthCompositionMiddleware{baseMid})
+		} else {
+			// Select the keying method to use for setting session states
+			if mwAppendEnabled(&authArray, &Oauth2KeyExists{baseMid}) {
+				logger.Info("Checking security policy: OAuth")
+			}
 
-		if mwAppendEnabled(&authArray, &BasicAuthKeyIsValid{baseMid, nil, nil}) {
-			logger.Info("Checking security policy: Basic")
-		}
+			if mwAppendEnabled(&authArray, &BasicAuthKeyIsValid{baseMid, nil, nil}) {
+				logger.Info("Checking security policy: Basic")
+			}
 
-		if mwAppendEnabled(&authArray, &HMACMiddleware{BaseMiddleware: baseMid}) {
-			logger.Info("Checking security policy: HMAC")
-		}
+			if mwAppendEnabled(&authArray, &HMACMiddleware{BaseMiddleware: baseMid}) {
+				logger.Info("Checking security policy: HMAC")
+			}
 
-		if mwAppendEnabled(&authArray, &JWTMiddleware{baseMid}) {
-			logger.Info("Checking security policy: JWT")
-		}
+			if mwAppendEnabled(&authArray, &JWTMiddleware{baseMid}) {
+				logger.Info("Checking security policy: JWT")
+			}
 
-		if mwAppendEnabled(&authArray, &OpenIDMW{BaseMiddleware: baseMid}) {
-			logger.Info("Checking security policy: OpenID")
-		}
+			if mwAppendEnabled(&authArray, &OpenIDMW{BaseMiddleware: baseMid}) {
+				logger.Info("Checking security policy: OpenID")
+			}
 
-		coprocessAuth := mwDriver != apidef.OttoDriver && spec.EnableCoProcessAuth
-		ottoAuth := !coprocessAuth && mwDriver == apidef.OttoDriver && spec.EnableCoProcessAuth
-		gopluginAuth := !coprocessAuth && !ottoAuth && mwDriver == apidef.GoPluginDriver && spec.UseGoPluginAuth
+			if mwAppendEnabled(&authArray, &CustomDomainAuth{baseMid}) {
+				logger.Info("Checking security policy: Custom Domain")
+			}
 
-		if coprocessAuth {
-			// TODO: check if mwAuthCheckFunc is available/valid
-			coprocessLog.Debug("Registering coprocess middleware, hook name: ", mwAuthCheckFunc.Name, "hook type: CustomKeyCheck", ", driver: ", mwDriver)
+			if mwAppendEnabled(&authArray, &TrustedHeaderAuth{baseMid}) {
+				logger.Info("Checking security policy: Trusted Header")
+			}
 
-			newExtractor(spec, baseMid)
-			mwAppendEnabled(&authArray, &CoProcessMiddleware{baseMid, coprocess.HookType_CustomKeyCheck, mwAuthCheckFunc.Name, mwDriver, mwAuthCheckFunc.RawBodyOnly, nil})
-		}
+			coprocessAuth := mwDriver != apidef.OttoDriver && spec.EnableCoProcessAuth
+			ottoAuth := !coprocessAuth && mwDriver == apidef.OttoDriver && spec.EnableCoProcessAuth
+			gopluginAuth := !coprocessAuth && !ottoAuth && mwDriver == apidef.GoPluginDriver && spec.UseGoPluginAuth
 
-		if ottoAuth {
-			logger.Info("----> Checking security policy: JS Plugin")
+			if coprocessAuth {
+				// TODO: check if mwAuthCheckFunc is available/valid
+				coprocessLog.Debug("Registering coprocess middleware, hook name: ", mwAuthCheckFunc.Name, "hook type: CustomKeyCheck", ", driver: ", mwDriver)
 
-			authArray = append(authArray, createDynamicMiddleware(mwAuthCheckFunc.Name, true, false, baseMid))
-		}
+				newExtractor(spec, baseMid)
+				mwAppendEnabled(&authArray, &CoProcessMiddleware{baseMid, coprocess.HookType_CustomKeyCheck, mwAuthCheckFunc.Name, mwDriver, mwAuthCheckFunc.RawBodyOnly, "", nil})
+			}
 
-		if gopluginAuth {
-			mwAppendEnabled(
-				&authArray,
-				&GoPluginMiddleware{
-					BaseMiddleware: baseMid,
-					Path:           mwAuthCheckFunc.Path,
-					SymbolName:     mwAuthCheckFunc.Name,
-				},
-			)
-		}
+			if ottoAuth {
+				logger.Info("----> Checking security policy: JS Plugin")
 
-		if spec.UseStandardAuth || len(authArray) == 0 {
-			logger.Info("Checking security policy: Token")
-			authArray = append(authArray, createMiddleware(&AuthKey{baseMid}))
+				authArray = append(authArray, createDynamicMiddleware(mwAuthCheckFunc.Name, true, false, baseMid))
+			}
+
+			if gopluginAuth {
+				mwAppendGoPlugin(&authArray, baseMid, mwAuthCheckFunc)
+			}
+
+			if spec.UseStandardAuth || len(authArray) == 0 {
+				logger.Info("Checking security policy: Token")
+				authArray = append(authArray, createMiddleware(&AuthKey{baseMid}))
+			}
 		}
 
 		chainArray = append(chainArray, authArray...)
 
 		for _, obj := range mwPostAuthCheckFuncs {
 			if mwDriver == apidef.GoPluginDriver {
-				mwAppendEnabled(
-					&chainArray,
-					&GoPluginMiddleware{
-						BaseMiddleware: baseMid,
-						Path:           obj.Path,
-						SymbolName:     obj.Name,
-					},
-				)
+				mwAppendGoPlugin(&chainArray, baseMid, obj)
 			} else {
 				coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", mwDriver)
-				mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_PostKeyAuth, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+				mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_PostKeyAuth, obj.Name, mwDriver, obj.RawBodyOnly, "", nil})
+			}
+		}
+
+		for _, vmw := range versionMW {
+			if vmw.driver == apidef.OttoDriver || vmw.driver == apidef.GoPluginDriver {
+				continue
+			}
+			for _, obj := range vmw.postKeyAuthFuncs {
+				coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: PostKeyAuth", ", driver: ", vmw.driver, ", version: ", vmw.versionName)
+				mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_PostKeyAuth, obj.Name, vmw.driver, obj.RawBodyOnly, vmw.versionName, nil})
 			}
 		}
 
 		mwAppendEnabled(&chainArray, &StripAuth{baseMid})
 		mwAppendEnabled(&chainArray, &KeyExpired{baseMid})
+		mwAppendEnabled(&chainArray, &DynamicPolicyMiddleware{baseMid})
+		mwAppendEnabled(&chainArray, &LoadSheddingMiddleware{baseMid})
 		mwAppendEnabled(&chainArray, &AccessRightsCheck{baseMid})
 		mwAppendEnabled(&chainArray, &GranularAccessMiddleware{baseMid})
 		mwAppendEnabled(&chainArray, &RateLimitAndQuotaCheck{baseMid})
+		mwAppendEnabled(&chainArray, &RateLimitHeaders{baseMid})
+		mwAppendEnabled(&chainArray, &EndpointRateLimitMiddleware{baseMid})
+		mwAppendEnabled(&chainArray, &ExternalRateLimitMiddleware{baseMid})
 	}
 
+	mwAppendEnabled(&chainArray, &FeatureFlagsMiddleware{baseMid})
+
 	mwAppendEnabled(&chainArray, &RateLimitForAPI{BaseMiddleware: baseMid})
+	mwAppendEnabled(&chainArray, &RateLimitByAttribute{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &ValidateJSON{BaseMiddleware: baseMid})
+	mwAppendEnabled(&chainArray, &XMLThreatProtectionMiddleware{BaseMiddleware: baseMid})
+	mwAppendEnabled(&chainArray, &TypedPathParamsMiddleware{BaseMiddleware: baseMid})
+	mwAppendEnabled(&chainArray, &ContentTypeConversionMiddleware{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &TransformMiddleware{baseMid})
 	mwAppendEnabled(&chainArray, &TransformJQMiddleware{baseMid})
+	mwAppendEnabled(&chainArray, &TransformJSONataMiddleware{baseMid})
 	mwAppendEnabled(&chainArray, &TransformHeaders{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &URLRewriteMiddleware{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &TransformMethod{BaseMiddleware: baseMid})
@@ -398,22 +473,25 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 
 	for _, obj := range mwPostFuncs {
 		if mwDriver == apidef.GoPluginDriver {
-			mwAppendEnabled(
-				&chainArray,
-				&GoPluginMiddleware{
-					BaseMiddleware: baseMid,
-					Path:           obj.Path,
-					SymbolName:     obj.Name,
-				},
-			)
+			mwAppendGoPlugin(&chainArray, baseMid, obj)
 		} else if mwDriver != apidef.OttoDriver {
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Post", ", driver: ", mwDriver)
-			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Post, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Post, obj.Name, mwDriver, obj.RawBodyOnly, "", nil})
 		} else {
 			chainArray = append(chainArray, createDynamicMiddleware(obj.Name, false, obj.RequireSession, baseMid))
 		}
 	}
 
+	for _, vmw := range versionMW {
+		if vmw.driver == apidef.OttoDriver || vmw.driver == apidef.GoPluginDriver {
+			continue
+		}
+		for _, obj := range vmw.postFuncs {
+			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Post", ", driver: ", vmw.driver, ", version: ", vmw.versionName)
+			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Post, obj.Name, vmw.driver, obj.RawBodyOnly, vmw.versionName, nil})
+		}
+	}
+
 	chain = alice.New(chainArray...).Then(&DummyProxyHandler{SH: SuccessHandler{baseMid}})
 
 	if !spec.UseKeylessAccess {
@@ -438,7 +516,7 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 	logger.Debug("Setting Listen Path: ", spec.Proxy.ListenPath)
 
 	if trace.IsEnabled() {
-		chainDef.ThisHandler = trace.Handle(spec.Name, chain)
+		chainDef.ThisHandler = tracingHandler(spec, chain)
 	} else {
 		chainDef.ThisHandler = chain
 	}
@@ -455,6 +533,49 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 	return &chainDef
 }
 
+// versionCoprocessMW is one version's own coprocess hook set, loaded from
+// its apidef.VersionInfo.CustomMiddlewareBundle rather than the API-level
+// bundle. Response hooks aren't included: the response chain is built once
+// per API from spec.ResponseProcessors and isn't version-aware, so a
+// version-scoped bundle's Response hooks are not currently honoured.
+type versionCoprocessMW struct {
+	versionName                           string
+	preFuncs, postFuncs, postKeyAuthFuncs []apidef.MiddlewareDefinition
+	driver                                apidef.MiddlewareDriver
+}
+
+// loadVersionCustomMiddleware loads a per-version CustomMiddlewareBundle for
+// every version that declares one, so that version can run its own set of
+// coprocess plugin hooks instead of the API-level bundle's. Versions that
+// don't set their own bundle are left out of the result and keep using the
+// API-level hooks, as before.
+func loadVersionCustomMiddleware(spec *APISpec) []versionCoprocessMW {
+	var out []versionCoprocessMW
+	for versionName, versionInfo := range spec.VersionData.Versions {
+		if versionInfo.CustomMiddlewareBundle == "" {
+			continue
+		}
+
+		bundle, err := loadBundleNamed(spec, versionInfo.CustomMiddlewareBundle)
+		if err != nil || bundle == nil {
+			mainLog.WithFields(logrus.Fields{
+				"prefix": "main",
+			}).Error("Couldn't load version bundle for ", versionName, ": ", err)
+			continue
+		}
+
+		preFuncs, postFuncs, postKeyAuthFuncs, _, driver := customMiddlewareFromSection(bundle.Manifest.CustomMiddleware)
+		out = append(out, versionCoprocessMW{
+			versionName:      versionInfo.Name,
+			preFuncs:         preFuncs,
+			postFuncs:        postFuncs,
+			postKeyAuthFuncs: postKeyAuthFuncs,
+			driver:           driver,
+		})
+	}
+	return out
+}
+
 // Check for recursion
 const defaultLoopLevelLimit = 5
 
@@ -585,10 +706,18 @@ func loadHTTPService(spec *APISpec, apisByListen map[string]int, gs *generalStor
 		router = router.Host(hostname).Subrouter()
 	}
 
-	chainObj := processSpec(spec, apisByListen, gs, router, logrus.NewEntry(log))
-	apisMu.Lock()
-	spec.middlewareChain = chainObj
-	apisMu.Unlock()
+	// spec.middlewareChain is only non-nil here if loadApps found this
+	// exact *APISpec unchanged since the last reload (see
+	// reuseUnchangedSpecs) and carried it over instead of building a fresh
+	// one - in that case the existing chain is still valid and rebuilding
+	// it would just waste the time this diffing was added to save.
+	chainObj := spec.middlewareChain
+	if chainObj == nil {
+		chainObj = processSpec(spec, apisByListen, gs, router, logrus.NewEntry(log))
+		apisMu.Lock()
+		spec.middlewareChain = chainObj
+		apisMu.Unlock()
+	}
 
 	if chainObj.Skip {
 		return
@@ -599,6 +728,13 @@ func loadHTTPService(spec *APISpec, apisByListen map[string]int, gs *generalStor
 	}
 
 	router.Handle(chainObj.ListenOn, chainObj.ThisHandler)
+
+	for _, alias := range spec.Proxy.ListenPathAliases {
+		if alias.ListenPath == "" || alias.ListenPath == spec.Proxy.ListenPath {
+			continue
+		}
+		router.Handle(alias.ListenPath+"{rest:.*}", listenPathAliasHandler(alias, spec.Proxy.ListenPath, chainObj.ThisHandler))
+	}
 }
 
 func loadTCPService(spec *APISpec, muxer *proxyMux) {
@@ -609,10 +745,77 @@ type generalStores struct {
 	redisStore, redisOrgStore, healthStore, rpcAuthStore, rpcOrgStore storage.Handler
 }
 
+// reloadGeneration counts how many times loadApps has swapped in a new
+// muxer/apisByID register, so the control API can report which reload a
+// given /tyk/reload call actually produced - useful for confirming a
+// reload was picked up, or for spotting one that got coalesced away.
+var reloadGeneration uint64
+
+func currentReloadGeneration() uint64 {
+	return atomic.LoadUint64(&reloadGeneration)
+}
+
+// specChecksums holds the checksum loadApps last saw for each API ID, so
+// the next reload can tell which specs actually changed. Guarded by
+// apisMu, same as apisByID which it's always read/written alongside.
+var specChecksums = make(map[string]string)
+
+// specChecksum hashes the parts of an APISpec that a config change would
+// touch. It deliberately hashes only the embedded APIDefinition - not the
+// whole APISpec, which also carries live state like SessionManager and
+// middlewareChain that differs on every run even when the definition
+// itself hasn't changed.
+func specChecksum(spec *APISpec) string {
+	b, err := json.Marshal(spec.APIDefinition)
+	if err != nil {
+		// Fall back to always treating this spec as changed - safe, if
+		// slower, and this should never actually happen given
+		// APIDefinition is plain JSON-tagged data.
+		return ""
+	}
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// reuseUnchangedSpecs is the diff step of the reload: any spec whose
+// checksum matches what was loaded last time is swapped out for the
+// *APISpec already running, chain and all, so loadHTTPService can skip
+// rebuilding it. This is what keeps a reload of a large API set fast when
+// only a handful of specs actually changed.
+func reuseUnchangedSpecs(specs []*APISpec) {
+	apisMu.RLock()
+	previous := apisByID
+	apisMu.RUnlock()
+
+	newChecksums := make(map[string]string, len(specs))
+	reused := 0
+	for i, spec := range specs {
+		sum := specChecksum(spec)
+		newChecksums[spec.APIID] = sum
+
+		old, ok := previous[spec.APIID]
+		if !ok || sum == "" || specChecksums[spec.APIID] != sum {
+			continue
+		}
+		specs[i] = old
+		reused++
+	}
+
+	if reused > 0 {
+		mainLog.Infof("Reusing %d unchanged API chain(s) out of %d", reused, len(specs))
+	}
+
+	apisMu.Lock()
+	specChecksums = newChecksums
+	apisMu.Unlock()
+}
+
 // Create the individual API (app) specs based on live configurations and assign middleware
 func loadApps(specs []*APISpec) {
 	mainLog.Info("Loading API configurations.")
 
+	reuseUnchangedSpecs(specs)
+
 	tmpSpecRegister := make(map[string]*APISpec)
 
 	// sort by listen path from longer to shorter, so that /foo
@@ -645,7 +848,7 @@ func loadApps(specs []*APISpec) {
 		tmpSpecRegister[spec.APIID] = spec
 
 		switch spec.Protocol {
-		case "", "http", "https":
+		case "", "http", "https", "grpc":
 			loadHTTPService(spec, apisByListen, &gs, muxer)
 		case "tcp", "tls":
 			loadTCPService(spec, muxer)
@@ -653,12 +856,23 @@ func loadApps(specs []*APISpec) {
 	}
 
 	defaultProxyMux.swap(muxer)
+	atomic.AddUint64(&reloadGeneration, 1)
 
 	// Swap in the new register
 	apisMu.Lock()
+	oldSpecRegister := apisByID
 	apisByID = tmpSpecRegister
 	apisMu.Unlock()
 
+	// The old specs are no longer reachable, but their upstream transports
+	// may still have requests in flight on them - drain those rather than
+	// abandoning them outright.
+	for oldID, oldSpec := range oldSpecRegister {
+		if tmpSpecRegister[oldID] != oldSpec {
+			go drainOldTransports(oldSpec)
+		}
+	}
+
 	mainLog.Debug("Checker host list")
 
 	// Kick off our host checkers
@@ -668,5 +882,7 @@ func loadApps(specs []*APISpec) {
 
 	mainLog.Debug("Checker host Done")
 
+	setLastDeadTargetWarnings(probeTargets(specs))
+
 	mainLog.Info("Initialised API Definitions")
 }
@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// APIOverrides holds fast operational tweaks for a loaded API that don't
+// warrant pushing a new API definition through the release pipeline. They
+// are persisted in Redis and merged onto the APISpec at load time.
+//
+// swagger:model
+type APIOverrides struct {
+	// DisabledMiddleware lists middleware Name() values to skip when
+	// building this API's chain.
+	DisabledMiddleware []string `json:"disabled_middleware,omitempty"`
+	// CacheTTL overrides spec.CacheOptions.CacheTimeout when set.
+	CacheTTL *int64 `json:"cache_ttl,omitempty"`
+	// LogLevel overrides the log level used for this API's request logger,
+	// e.g. "debug", "info", "warn", "error".
+	LogLevel string `json:"log_level,omitempty"`
+}
+
+// apiOverrideStore persists per-API overrides so they survive gateway
+// restarts and are shared across a cluster.
+var apiOverrideStore = storage.RedisCluster{KeyPrefix: "api-override-"}
+
+func apiOverrideKeyName(apiID string) string {
+	return "override-" + apiID
+}
+
+// getAPIOverrides returns the persisted overrides for an API, if any.
+func getAPIOverrides(apiID string) (APIOverrides, bool) {
+	raw, err := apiOverrideStore.GetRawKey(apiOverrideKeyName(apiID))
+	if err != nil {
+		return APIOverrides{}, false
+	}
+
+	var overrides APIOverrides
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		log.WithError(err).Error("Failed to decode persisted API overrides")
+		return APIOverrides{}, false
+	}
+
+	return overrides, true
+}
+
+// setAPIOverrides persists overrides for an API.
+func setAPIOverrides(apiID string, overrides APIOverrides) error {
+	raw, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+
+	return apiOverrideStore.SetRawKey(apiOverrideKeyName(apiID), string(raw), 0)
+}
+
+// deleteAPIOverrides removes any persisted overrides for an API.
+func deleteAPIOverrides(apiID string) {
+	apiOverrideStore.DeleteRawKey(apiOverrideKeyName(apiID))
+}
+
+// applyAPIOverrides merges any persisted overrides onto spec, called during
+// API load so operational tweaks take effect without a definition push.
+func applyAPIOverrides(spec *APISpec) {
+	overrides, found := getAPIOverrides(spec.APIID)
+	if !found {
+		return
+	}
+
+	spec.overrides = overrides
+
+	if overrides.CacheTTL != nil {
+		spec.CacheOptions.CacheTimeout = *overrides.CacheTTL
+	}
+}
+
+// middlewareDisabledByOverride reports whether name has been disabled for
+// apiID via a persisted runtime override.
+func middlewareDisabledByOverride(spec *APISpec, name string) bool {
+	for _, disabled := range spec.overrides.DisabledMiddleware {
+		if disabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// apiOverridesHandler implements GET/POST/DELETE for /apis/{apiID}/overrides
+// so operators can set and persist runtime overrides via the control API.
+func apiOverridesHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := mux.Vars(r)["apiID"]
+
+	switch r.Method {
+	case http.MethodGet:
+		overrides, found := getAPIOverrides(apiID)
+		if !found {
+			doJSONWrite(w, http.StatusNotFound, apiError("No overrides set for this API"))
+			return
+		}
+		doJSONWrite(w, http.StatusOK, overrides)
+	case http.MethodPost, http.MethodPut:
+		var overrides APIOverrides
+		if err := json.NewDecoder(r.Body).Decode(&overrides); err != nil {
+			doJSONWrite(w, http.StatusBadRequest, apiError("Request malformed"))
+			return
+		}
+		if err := setAPIOverrides(apiID, overrides); err != nil {
+			doJSONWrite(w, http.StatusInternalServerError, apiError("Failed to persist overrides"))
+			return
+		}
+		doJSONWrite(w, http.StatusOK, apiOk("Overrides updated"))
+	case http.MethodDelete:
+		deleteAPIOverrides(apiID)
+		doJSONWrite(w, http.StatusOK, apiOk("Overrides removed"))
+	default:
+		doJSONWrite(w, http.StatusMethodNotAllowed, apiError("Method not supported"))
+	}
+}
@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// swaggerFileName is the OpenAPI description of the gateway's own control
+// API, generated from the swagger annotations in api.go via go-swagger and
+// checked in at the repo root so it can be shipped alongside the binary.
+const swaggerFileName = "swagger.yml"
+
+// swaggerPath resolves the location of the checked-in OpenAPI document
+// relative to the running binary, falling back to the working directory so
+// this also works when running the gateway from source.
+func swaggerPath() string {
+	if tykBin, err := os.Executable(); err == nil {
+		if candidate := filepath.Join(filepath.Dir(tykBin), swaggerFileName); fileExists(candidate) {
+			return candidate
+		}
+	}
+	return swaggerFileName
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// swaggerHandler serves the gateway's own OpenAPI description so control
+// API clients can be generated and contract-tested by automation tooling.
+func swaggerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		doJSONWrite(w, http.StatusMethodNotAllowed, apiError("Method not supported"))
+		return
+	}
+
+	http.ServeFile(w, r, swaggerPath())
+}
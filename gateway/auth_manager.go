@@ -151,6 +151,8 @@ func (b *DefaultAuthorisationManager) KeyAuthorised(keyName string) (user.Sessio
 		return newSession, false
 	}
 
+	newSession.Migrate()
+
 	return newSession, true
 }
 
@@ -322,6 +324,8 @@ func (b *DefaultSessionManager) SessionDetail(keyName string, hashed bool) (user
 		return session, false
 	}
 
+	session.Migrate()
+
 	return session, true
 }
 
@@ -8,8 +8,10 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/headers"
 )
 
 // RequestDefinition defines a batch request
@@ -32,6 +34,19 @@ type BatchReplyUnit struct {
 	Code        int         `json:"code"`
 	Headers     http.Header `json:"headers"`
 	Body        string      `json:"body"`
+	// Order is this item's index within the original batch request, so
+	// SDKs consuming parallel-executed batches can put results back in
+	// requested order.
+	Order int `json:"order"`
+	// LatencyMS is how long this sub-request took to complete, in
+	// milliseconds.
+	LatencyMS int64 `json:"latency_ms"`
+	// RateLimitLimit/Remaining echo the sub-request's applied rate limit
+	// status, so a batching SDK can throttle correctly per item instead of
+	// having to parse Headers itself. Empty when the sub-request wasn't
+	// rate limited.
+	RateLimitLimit     string `json:"rate_limit_limit,omitempty"`
+	RateLimitRemaining string `json:"rate_limit_remaining,omitempty"`
 }
 
 // BatchRequestHandler handles batch requests on /tyk/batch for any API Definition that has the feature enabled
@@ -40,7 +55,7 @@ type BatchRequestHandler struct {
 }
 
 // doRequest will make the same request but return a BatchReplyUnit
-func (b *BatchRequestHandler) doRequest(req *http.Request, relURL string) BatchReplyUnit {
+func (b *BatchRequestHandler) doRequest(req *http.Request, relURL string, order int) BatchReplyUnit {
 	tr := &http.Transport{TLSClientConfig: &tls.Config{}}
 
 	if cert := getUpstreamCertificate(req.Host, b.API); cert != nil {
@@ -55,24 +70,30 @@ func (b *BatchRequestHandler) doRequest(req *http.Request, relURL string) BatchR
 
 	client := &http.Client{Transport: tr}
 
+	started := time.Now()
 	resp, err := client.Do(req)
+	latencyMS := time.Since(started).Nanoseconds() / int64(time.Millisecond)
 	if err != nil {
 		log.Error("Webhook request failed: ", err)
-		return BatchReplyUnit{}
+		return BatchReplyUnit{RelativeURL: relURL, Order: order, LatencyMS: latencyMS}
 	}
 
 	defer resp.Body.Close()
 	content, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		log.Warning("Body read failure! ", err)
-		return BatchReplyUnit{}
+		return BatchReplyUnit{RelativeURL: relURL, Order: order, LatencyMS: latencyMS}
 	}
 
 	return BatchReplyUnit{
-		RelativeURL: relURL,
-		Code:        resp.StatusCode,
-		Headers:     resp.Header,
-		Body:        string(content),
+		RelativeURL:        relURL,
+		Code:               resp.StatusCode,
+		Headers:            resp.Header,
+		Body:               string(content),
+		Order:              order,
+		LatencyMS:          latencyMS,
+		RateLimitLimit:     resp.Header.Get(headers.XRateLimitLimit),
+		RateLimitRemaining: resp.Header.Get(headers.XRateLimitRemaining),
 	}
 }
 
@@ -124,7 +145,7 @@ func (b *BatchRequestHandler) MakeRequests(batchRequest BatchRequestStructure, r
 		replies := make(chan BatchReplyUnit)
 		for i, req := range requestSet {
 			go func(i int, req *http.Request) {
-				reply := b.doRequest(req, batchRequest.Requests[i].RelativeURL)
+				reply := b.doRequest(req, batchRequest.Requests[i].RelativeURL, i)
 				replies <- reply
 			}(i, req)
 		}
@@ -134,7 +155,7 @@ func (b *BatchRequestHandler) MakeRequests(batchRequest BatchRequestStructure, r
 		}
 	} else {
 		for i, req := range requestSet {
-			reply := b.doRequest(req, batchRequest.Requests[i].RelativeURL)
+			reply := b.doRequest(req, batchRequest.Requests[i].RelativeURL, i)
 			replySet = append(replySet, reply)
 		}
 	}
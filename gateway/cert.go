@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -15,6 +16,13 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// errCertPinningMismatch is returned by the pinned-key verifiers below when
+// none of the certificates presented by the upstream match a pinned SPKI
+// SHA-256 fingerprint. Its text is matched against in reverse_proxy.go to
+// fail the request closed with a distinct status code and analytics tag,
+// rather than falling through to a generic proxy error.
+var errCertPinningMismatch = errors.New("certificate public key pinning error: public key not in pinned set")
+
 type APICertificateStatusMessage struct {
 	CertID  string `json:"id"`
 	Status  string `json:"status"`
@@ -52,6 +60,31 @@ var cipherSuites = map[string]uint16{
 
 var certLog = log.WithField("prefix", "certs")
 
+// resolveVaultConfig turns a config.VaultConfig into a certs.VaultConfig,
+// reading the token from TokenPath when Token isn't set directly - the same
+// file-or-inline convention k8sRESTConfig uses for its service account
+// token.
+func resolveVaultConfig(vaultConf config.VaultConfig) certs.VaultConfig {
+	if !vaultConf.Enabled {
+		return certs.VaultConfig{}
+	}
+
+	token := vaultConf.Token
+	if token == "" && vaultConf.TokenPath != "" {
+		tokenBytes, err := ioutil.ReadFile(vaultConf.TokenPath)
+		if err != nil {
+			certLog.WithError(err).Error("Could not read Vault token file")
+		} else {
+			token = strings.TrimSpace(string(tokenBytes))
+		}
+	}
+
+	return certs.VaultConfig{
+		Address: vaultConf.Address,
+		Token:   token,
+	}
+}
+
 func getUpstreamCertificate(host string, spec *APISpec) (cert *tls.Certificate) {
 	var certID string
 
@@ -128,7 +161,7 @@ func verifyPeerCertificatePinnedCheck(spec *APISpec, tlsConfig *tls.Config) func
 			}
 		}
 
-		return errors.New("Certificate public key pinning error. Public keys do not match.")
+		return errCertPinningMismatch
 	}
 }
 
@@ -169,7 +202,7 @@ func dialTLSPinnedCheck(spec *APISpec, tc *tls.Config) func(network, addr string
 			}
 		}
 
-		return nil, errors.New("https://" + host + " certificate public key pinning error. Public keys do not match.")
+		return nil, fmt.Errorf("https://%s %s", host, errCertPinningMismatch)
 	}
 }
 
@@ -0,0 +1,44 @@
+package gateway
+
+import "time"
+
+// upstreamCertWatchInterval is how often loaded APIs' upstream/client
+// certificate IDs are re-read from their backing store, so a certificate
+// rotated at the source (file, Redis, or Vault) gets hot-swapped into the
+// running gateway without a restart or an operator having to hit the
+// control API's cert-delete endpoint to evict the old one from cache.
+const upstreamCertWatchInterval = 30 * time.Second
+
+// startUpstreamCertWatcher polls CertificateManager.Refresh forever. There's
+// no vendored filesystem-watch library in this tree, so rotation detection
+// is poll-based rather than push-based; upstreamCertWatchInterval bounds how
+// stale a swapped-in certificate can be.
+func startUpstreamCertWatcher() {
+	for range time.Tick(upstreamCertWatchInterval) {
+		refreshUpstreamCertificates()
+	}
+}
+
+// refreshUpstreamCertificates collects every upstream and client
+// certificate ID referenced by a currently loaded API and asks
+// CertificateManager to re-read and hot-swap any that changed.
+func refreshUpstreamCertificates() {
+	apisMu.RLock()
+	certIDs := make([]string, 0)
+	for _, spec := range apisByID {
+		if spec == nil {
+			continue
+		}
+		for _, id := range spec.UpstreamCertificates {
+			certIDs = append(certIDs, id)
+		}
+		certIDs = append(certIDs, spec.ClientCertificates...)
+	}
+	apisMu.RUnlock()
+
+	if len(certIDs) == 0 {
+		return
+	}
+
+	CertificateManager.Refresh(certIDs)
+}
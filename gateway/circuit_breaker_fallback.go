@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	cache "github.com/pmylund/go-cache"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/headers"
+)
+
+// lastGoodResponseCache stores the most recent successful upstream
+// response body per breaker target, so CircuitBreakerFallback's "cache"
+// mode has something to replay while the breaker is open.
+var lastGoodResponseCache = cache.New(10*time.Minute, 10*time.Minute)
+
+func lastGoodResponseKey(apiID, host string) string {
+	return apiID + "-" + host
+}
+
+// recordLastGoodResponse buffers a successful response's body so it can
+// be replayed later if the circuit for this target opens.
+func recordLastGoodResponse(apiID, host string, res *http.Response) {
+	if res == nil || res.StatusCode >= http.StatusInternalServerError {
+		return
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	lastGoodResponseCache.Set(lastGoodResponseKey(apiID, host), body, cache.DefaultExpiration)
+}
+
+// writeCircuitBreakerFallback writes the client-facing response to use
+// while a circuit breaker is open, per CircuitBreakerFallback.Mode. It
+// returns true if it wrote a response, false if the caller should fall
+// back to the default bare 503.
+func writeCircuitBreakerFallback(rw http.ResponseWriter, req *http.Request, apiID, host string, fallback apidef.CircuitBreakerFallback) bool {
+	switch fallback.Mode {
+	case "static":
+		code := fallback.StatusCode
+		if code == 0 {
+			code = http.StatusServiceUnavailable
+		}
+		if fallback.ContentType != "" {
+			rw.Header().Set(headers.ContentType, fallback.ContentType)
+		}
+		rw.WriteHeader(code)
+		rw.Write([]byte(fallback.Body))
+		return true
+	case "cache":
+		cached, found := lastGoodResponseCache.Get(lastGoodResponseKey(apiID, host))
+		if !found {
+			return false
+		}
+		rw.Header().Set("X-Tyk-Circuit-Breaker-Fallback", "cache")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write(cached.([]byte))
+		return true
+	case "redirect":
+		if fallback.RedirectURL == "" {
+			return false
+		}
+		http.Redirect(rw, req, fallback.RedirectURL, http.StatusFound)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// clusterHeartbeatStore holds a short-lived record per gateway node sharing
+// this Redis, so the fleet's convergence after a reload can be checked from
+// any one node instead of logging into each host in turn.
+var clusterHeartbeatStore = storage.RedisCluster{KeyPrefix: "cluster-heartbeat-"}
+
+// clusterHeartbeatTTL is how long a node's heartbeat key lives for - long
+// enough to absorb a missed tick, short enough that a node that's gone
+// drops out of the view quickly.
+const clusterHeartbeatTTL = 30 * time.Second
+
+const defaultClusterHeartbeatFrequency = 10 * time.Second
+
+// ClusterNode is a single gateway's last reported status, as returned by
+// GET /cluster/nodes.
+//
+// swagger:model
+type ClusterNode struct {
+	NodeID     string   `json:"node_id"`
+	Hostname   string   `json:"hostname"`
+	Version    string   `json:"version"`
+	Tags       []string `json:"tags"`
+	APIsLoaded int      `json:"apis_loaded"`
+	LastSeen   int64    `json:"last_seen"`
+}
+
+// reportClusterHeartbeat writes this node's current status to the shared
+// heartbeat keyspace, overwriting its previous entry and refreshing its TTL.
+func reportClusterHeartbeat() {
+	nodeID := GetNodeID()
+	if nodeID == "" {
+		log.Warning("Node not registered yet, skipping cluster heartbeat")
+		return
+	}
+
+	apisMu.RLock()
+	apisLoaded := len(apisByID)
+	apisMu.RUnlock()
+
+	node := ClusterNode{
+		NodeID:     nodeID,
+		Hostname:   hostDetails.Hostname,
+		Version:    VERSION,
+		Tags:       config.Global().DBAppConfOptions.Tags,
+		APIsLoaded: apisLoaded,
+		LastSeen:   time.Now().Unix(),
+	}
+
+	asJSON, err := json.Marshal(node)
+	if err != nil {
+		log.WithError(err).Error("Failed to encode cluster heartbeat payload")
+		return
+	}
+
+	if err := clusterHeartbeatStore.SetKey(nodeID, string(asJSON), int64(clusterHeartbeatTTL.Seconds())); err != nil {
+		log.WithError(err).Error("Failed to write cluster heartbeat")
+	}
+}
+
+var clusterHeartbeatOnce sync.Once
+
+// startClusterHeartbeat begins periodically publishing this node's status to
+// the shared heartbeat keyspace so clusterNodesHandler can list the fleet.
+func startClusterHeartbeat() {
+	go func() {
+		for {
+			reportClusterHeartbeat()
+			time.Sleep(defaultClusterHeartbeatFrequency)
+		}
+	}()
+}
+
+// listClusterNodes returns every node currently reporting a live heartbeat.
+func listClusterNodes() []ClusterNode {
+	nodes := []ClusterNode{}
+	for _, raw := range clusterHeartbeatStore.GetKeysAndValues() {
+		var node ClusterNode
+		if err := json.Unmarshal([]byte(raw), &node); err != nil {
+			log.WithError(err).Warning("Failed to decode cluster heartbeat entry, skipping")
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// clusterNodesHandler implements GET /cluster/nodes, listing every gateway
+// node that's reported a heartbeat recently, so a fleet-wide reload can be
+// confirmed as converged without logging into each host.
+func clusterNodesHandler(w http.ResponseWriter, r *http.Request) {
+	doJSONWrite(w, http.StatusOK, listClusterNodes())
+}
@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// consistentHashVirtualNodes is how many points each host gets on the hash
+// ring. More points spread a host's share of the keyspace more evenly,
+// at the cost of a bigger ring to build and search.
+const consistentHashVirtualNodes = 100
+
+type hashRingPoint struct {
+	hash uint32
+	host string
+}
+
+// consistentHashTarget picks a host from hosts by hashing key onto a ring:
+// each host owns several points scattered across the ring (see
+// consistentHashVirtualNodes), and key's own hash is matched to the next
+// point clockwise, wrapping around to the start. As long as hosts doesn't
+// change, the same key always lands on the same host - this is what gives
+// callers session affinity without a sticky cookie.
+func consistentHashTarget(hosts []string, key string) (string, error) {
+	if len(hosts) == 0 {
+		return "", errors.New("no hosts to hash against")
+	}
+	if len(hosts) == 1 {
+		return hosts[0], nil
+	}
+
+	ring := make([]hashRingPoint, 0, len(hosts)*consistentHashVirtualNodes)
+	for _, host := range hosts {
+		for i := 0; i < consistentHashVirtualNodes; i++ {
+			ring = append(ring, hashRingPoint{hash: fnv32a(host + "#" + strconv.Itoa(i)), host: host})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+
+	h := fnv32a(key)
+	idx := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= h })
+	if idx == len(ring) {
+		idx = 0
+	}
+	return ring[idx].host, nil
+}
+
+func fnv32a(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -35,6 +36,13 @@ type CoProcessMiddleware struct {
 	MiddlewareDriver apidef.MiddlewareDriver
 	RawBodyOnly      bool
 
+	// VersionName scopes this hook to one API version, when it was loaded
+	// from that version's own CustomMiddlewareBundle
+	// (apidef.VersionInfo.CustomMiddlewareBundle) rather than the
+	// API-level bundle. Empty means it's a base, API-wide hook, which
+	// only runs for versions that don't declare their own bundle.
+	VersionName string
+
 	successHandler *SuccessHandler
 }
 
@@ -265,6 +273,20 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 	logger := m.Logger()
 	logger.Debug("CoProcess Request, HookType: ", m.HookType)
 
+	if version, _, _, _ := m.Spec.Version(r); version != nil {
+		if m.VersionName == "" {
+			// Base, API-wide hook: skip it for versions that opted into
+			// their own CustomMiddlewareBundle instead.
+			if version.CustomMiddlewareBundle != "" {
+				return nil, http.StatusOK
+			}
+		} else if version.Name != m.VersionName {
+			// Hook loaded from a version-scoped bundle: only run for
+			// requests resolved to that version.
+			return nil, http.StatusOK
+		}
+	}
+
 	var extractor IdExtractor
 	if m.Spec.EnableCoProcessAuth && m.Spec.CustomMiddleware.IdExtractor.Extractor != nil {
 		extractor = m.Spec.CustomMiddleware.IdExtractor.Extractor.(IdExtractor)
@@ -301,6 +323,9 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 
 	if err != nil {
 		logger.WithError(err).Error("Dispatch error")
+		if err == errDispatchShed {
+			return errors.New("Middleware overloaded"), http.StatusServiceUnavailable
+		}
 		if m.HookType == coprocess.HookType_CustomKeyCheck {
 			return errors.New("Key not authorised"), 403
 		} else {
@@ -463,12 +488,57 @@ func (h *CustomMiddlewareResponseHook) HandleResponse(rw http.ResponseWriter, re
 	return nil
 }
 
+var (
+	dispatchSemaphoresMu sync.Mutex
+	dispatchSemaphores   = map[apidef.MiddlewareDriver]chan struct{}{}
+)
+
+// errDispatchShed is returned when a coprocess dispatch is shed because the
+// driver's concurrency limit was reached and no slot became free within the
+// queue timeout, instead of letting the caller pile up an unbounded number
+// of goroutines waiting on a slow plugin.
+var errDispatchShed = errors.New("coprocess dispatch shed: driver is overloaded")
+
+// dispatchSemaphoreFor returns the per-driver semaphore used to bound
+// concurrent Dispatch calls, or nil if no limit is configured.
+func dispatchSemaphoreFor(driver apidef.MiddlewareDriver) chan struct{} {
+	limit := config.Global().CoProcessOptions.MaxConcurrentDispatches
+	if limit <= 0 {
+		return nil
+	}
+
+	dispatchSemaphoresMu.Lock()
+	defer dispatchSemaphoresMu.Unlock()
+
+	sem, ok := dispatchSemaphores[driver]
+	if !ok {
+		sem = make(chan struct{}, limit)
+		dispatchSemaphores[driver] = sem
+	}
+	return sem
+}
+
 func (c *CoProcessor) Dispatch(object *coprocess.Object) (*coprocess.Object, error) {
 	dispatcher := loadedDrivers[c.Middleware.MiddlewareDriver]
 	if dispatcher == nil {
 		err := fmt.Errorf("Couldn't dispatch request, driver '%s' isn't available", c.Middleware.MiddlewareDriver)
 		return nil, err
 	}
+
+	if sem := dispatchSemaphoreFor(c.Middleware.MiddlewareDriver); sem != nil {
+		timeout := time.Duration(config.Global().CoProcessOptions.DispatchQueueTimeout) * time.Millisecond
+		if timeout <= 0 {
+			timeout = time.Second
+		}
+
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-time.After(timeout):
+			return nil, errDispatchShed
+		}
+	}
+
 	newObject, err := dispatcher.Dispatch(object)
 	if err != nil {
 		return nil, err
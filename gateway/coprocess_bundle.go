@@ -189,6 +189,13 @@ func (ZipBundleSaver) Save(bundle *Bundle, bundlePath string, spec *APISpec) err
 
 // fetchBundle will fetch a given bundle, using the right BundleGetter. The first argument is the bundle name, the base bundle URL will be used as prefix.
 func fetchBundle(spec *APISpec) (bundle Bundle, err error) {
+	return fetchBundleNamed(spec, spec.CustomMiddlewareBundle)
+}
+
+// fetchBundleNamed is fetchBundle parameterized by bundle name, so a
+// version's own CustomMiddlewareBundle can be fetched without touching
+// spec.CustomMiddlewareBundle.
+func fetchBundleNamed(spec *APISpec, bundleName string) (bundle Bundle, err error) {
 
 	if !config.Global().EnableBundleDownloader {
 		log.WithFields(logrus.Fields{
@@ -198,7 +205,7 @@ func fetchBundle(spec *APISpec) (bundle Bundle, err error) {
 		return bundle, err
 	}
 
-	bundleURL := config.Global().BundleBaseURL + spec.CustomMiddlewareBundle
+	bundleURL := config.Global().BundleBaseURL + bundleName
 
 	var getter BundleGetter
 
@@ -224,7 +231,7 @@ func fetchBundle(spec *APISpec) (bundle Bundle, err error) {
 
 	bundleData, err := getter.Get()
 
-	bundle.Name = spec.CustomMiddlewareBundle
+	bundle.Name = bundleName
 	bundle.Data = bundleData
 	bundle.Spec = spec
 	return bundle, err
@@ -250,7 +257,7 @@ func saveBundle(bundle *Bundle, destPath string, spec *APISpec) error {
 func loadBundleManifest(bundle *Bundle, spec *APISpec, skipVerification bool) error {
 	log.WithFields(logrus.Fields{
 		"prefix": "main",
-	}).Info("----> Loading bundle: ", spec.CustomMiddlewareBundle)
+	}).Info("----> Loading bundle: ", bundle.Name)
 
 	manifestPath := filepath.Join(bundle.Path, "manifest.json")
 	f, err := os.Open(manifestPath)
@@ -262,7 +269,7 @@ func loadBundleManifest(bundle *Bundle, spec *APISpec, skipVerification bool) er
 	if err := json.NewDecoder(f).Decode(&bundle.Manifest); err != nil {
 		log.WithFields(logrus.Fields{
 			"prefix": "main",
-		}).Info("----> Couldn't unmarshal the manifest file for bundle: ", spec.CustomMiddlewareBundle)
+		}).Info("----> Couldn't unmarshal the manifest file for bundle: ", bundle.Name)
 		return err
 	}
 
@@ -280,20 +287,36 @@ func loadBundleManifest(bundle *Bundle, spec *APISpec, skipVerification bool) er
 
 // loadBundle wraps the load and save steps, it will return if an error occurs at any point.
 func loadBundle(spec *APISpec) error {
+	bundle, err := loadBundleNamed(spec, spec.CustomMiddlewareBundle)
+	if err != nil || bundle == nil {
+		return err
+	}
+	bundle.AddToSpec()
+	return nil
+}
+
+// loadBundleNamed is loadBundle parameterized by bundle name: it fetches,
+// caches and verifies the named bundle exactly like loadBundle, but leaves
+// applying it to a spec up to the caller. This lets a version-scoped
+// CustomMiddlewareBundle (apidef.VersionInfo.CustomMiddlewareBundle) be
+// loaded without overwriting the API-level spec.CustomMiddleware that the
+// base bundle populated. It returns a nil bundle (and nil error) when
+// bundleName is empty, so callers can loadBundleNamed unconditionally.
+func loadBundleNamed(spec *APISpec, bundleName string) (*Bundle, error) {
 	// Skip if no custom middleware bundle name is set.
-	if spec.CustomMiddlewareBundle == "" {
-		return nil
+	if bundleName == "" {
+		return nil, nil
 	}
 
 	// Skip if no bundle base URL is set.
 	if config.Global().BundleBaseURL == "" {
-		return bundleError(spec, nil, "No bundle base URL set, skipping bundle")
+		return nil, bundleError(spec, nil, "No bundle base URL set, skipping bundle")
 	}
 
 	tykBundlePath := filepath.Join(config.Global().MiddlewarePath, "bundles")
 	// Skip if the bundle destination path already exists.
 	bundleNameHash := md5.New()
-	io.WriteString(bundleNameHash, spec.CustomMiddlewareBundle)
+	io.WriteString(bundleNameHash, bundleName)
 	bundlePath := fmt.Sprintf("%s_%x", spec.APIID, bundleNameHash.Sum(nil))
 	destPath := filepath.Join(tykBundlePath, bundlePath)
 
@@ -301,50 +324,47 @@ func loadBundle(spec *APISpec) error {
 	if _, err := os.Stat(destPath); err == nil {
 		log.WithFields(logrus.Fields{
 			"prefix": "main",
-		}).Info("Loading existing bundle: ", spec.CustomMiddlewareBundle)
+		}).Info("Loading existing bundle: ", bundleName)
 
-		bundle := Bundle{
-			Name: spec.CustomMiddlewareBundle,
+		bundle := &Bundle{
+			Name: bundleName,
 			Path: destPath,
 			Spec: spec,
 		}
 
-		err = loadBundleManifest(&bundle, spec, true)
-		if err != nil {
+		if err := loadBundleManifest(bundle, spec, true); err != nil {
 			log.WithFields(logrus.Fields{
 				"prefix": "main",
-			}).Info("----> Couldn't load bundle: ", spec.CustomMiddlewareBundle, " ", err)
+			}).Info("----> Couldn't load bundle: ", bundleName, " ", err)
 		}
 
 		log.WithFields(logrus.Fields{
 			"prefix": "main",
-		}).Info("----> Using bundle: ", spec.CustomMiddlewareBundle)
+		}).Info("----> Using bundle: ", bundleName)
 
-		bundle.AddToSpec()
-
-		return nil
+		return bundle, nil
 	}
 
 	log.WithFields(logrus.Fields{
 		"prefix": "main",
-	}).Info("----> Fetching Bundle: ", spec.CustomMiddlewareBundle)
+	}).Info("----> Fetching Bundle: ", bundleName)
 
-	bundle, err := fetchBundle(spec)
+	bundle, err := fetchBundleNamed(spec, bundleName)
 	if err != nil {
-		return bundleError(spec, err, "Couldn't fetch bundle")
+		return nil, bundleError(spec, err, "Couldn't fetch bundle")
 	}
 
 	if err := os.MkdirAll(destPath, 0700); err != nil {
-		return bundleError(spec, err, "Couldn't create bundle directory")
+		return nil, bundleError(spec, err, "Couldn't create bundle directory")
 	}
 
 	if err := saveBundle(&bundle, destPath, spec); err != nil {
-		return bundleError(spec, err, "Couldn't save bundle")
+		return nil, bundleError(spec, err, "Couldn't save bundle")
 	}
 
 	log.WithFields(logrus.Fields{
 		"prefix": "main",
-	}).Debug("----> Saving Bundle: ", spec.CustomMiddlewareBundle)
+	}).Debug("----> Saving Bundle: ", bundleName)
 
 	// Set the destination path:
 	bundle.Path = destPath
@@ -355,16 +375,14 @@ func loadBundle(spec *APISpec) error {
 		if err := os.RemoveAll(bundle.Path); err != nil {
 			bundleError(spec, err, "Couldn't remove bundle")
 		}
-		return nil
+		return nil, nil
 	}
 
 	log.WithFields(logrus.Fields{
 		"prefix": "main",
-	}).Info("----> Bundle is valid, adding to spec: ", spec.CustomMiddlewareBundle)
-
-	bundle.AddToSpec()
+	}).Info("----> Bundle is valid: ", bundleName)
 
-	return nil
+	return &bundle, nil
 }
 
 // bundleError is a log helper.
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"crypto/md5"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/mitchellh/mapstructure"
 	"github.com/sirupsen/logrus"
 	xmlpath "gopkg.in/xmlpath.v2"
@@ -48,6 +50,16 @@ func (e *BaseExtractor) ExtractHeader(r *http.Request) (headerValue string, err
 	return headerValue, err
 }
 
+// ExtractCookie is used when a CookieSource is specified.
+func (e *BaseExtractor) ExtractCookie(r *http.Request) (cookieValue string, err error) {
+	cookieName := e.Config.ExtractorConfig["cookie_name"].(string)
+	cookie, err := r.Cookie(cookieName)
+	if err != nil {
+		return "", errors.New("Bad cookie value.")
+	}
+	return cookie.Value, nil
+}
+
 // ExtractForm is used when a FormSource is specified.
 func (e *BaseExtractor) ExtractForm(r *http.Request, paramName string) (formValue string, err error) {
 	parseForm(r)
@@ -124,6 +136,8 @@ func (e *ValueExtractor) ExtractAndCheck(r *http.Request) (sessionID string, ret
 		extractorOutput, err = e.ExtractHeader(r)
 	case apidef.FormSource:
 		extractorOutput, err = e.ExtractForm(r, e.cfg.FormParamName)
+	case apidef.CookieSource:
+		extractorOutput, err = e.ExtractCookie(r)
 	}
 
 	if err != nil {
@@ -193,6 +207,8 @@ func (e *RegexExtractor) ExtractAndCheck(r *http.Request) (SessionID string, ret
 		extractorOutput, err = e.ExtractBody(r)
 	case apidef.FormSource:
 		extractorOutput, err = e.ExtractForm(r, e.cfg.FormParamName)
+	case apidef.CookieSource:
+		extractorOutput, err = e.ExtractCookie(r)
 	}
 	if err != nil {
 		returnOverrides = e.Error(r, err, "RegexExtractor error")
@@ -262,6 +278,8 @@ func (e *XPathExtractor) ExtractAndCheck(r *http.Request) (SessionID string, ret
 		extractorOutput, err = e.ExtractBody(r)
 	case apidef.FormSource:
 		extractorOutput, err = e.ExtractForm(r, e.cfg.FormParamName)
+	case apidef.CookieSource:
+		extractorOutput, err = e.ExtractCookie(r)
 	}
 	if err != nil {
 		returnOverrides = e.Error(r, err, "XPathExtractor error")
@@ -295,6 +313,97 @@ func (e *XPathExtractor) ExtractAndCheck(r *http.Request) (SessionID string, ret
 	return SessionID, returnOverrides
 }
 
+type JWTClaimExtractor struct {
+	BaseExtractor
+	cfg *JWTClaimExtractorConfig
+}
+
+type JWTClaimExtractorConfig struct {
+	HeaderName string `mapstructure:"header_name" bson:"header_name" json:"header_name"`
+	CookieName string `mapstructure:"cookie_name" bson:"cookie_name" json:"cookie_name"`
+	ClaimName  string `mapstructure:"claim_name" bson:"claim_name" json:"claim_name"`
+}
+
+// ExtractAndCheck reads the JWT out of the configured source, decodes its
+// claims without verifying the signature (verification, if wanted, is the
+// job of the JWT auth middleware itself) and uses the named claim's value
+// as the caller ID - so a coprocess API can identify its caller straight
+// from a claim already present on a JWT it doesn't itself need to validate.
+func (e *JWTClaimExtractor) ExtractAndCheck(r *http.Request) (SessionID string, returnOverrides ReturnOverrides) {
+	if e.cfg == nil {
+		config := &JWTClaimExtractorConfig{}
+		if err := mapstructure.Decode(e.Config.ExtractorConfig, config); err != nil {
+			returnOverrides = e.Error(r, err, "Can't decode JWTClaimExtractor configuration")
+			return SessionID, returnOverrides
+		}
+		e.cfg = config
+	}
+
+	var rawToken string
+	var err error
+	switch e.Config.ExtractFrom {
+	case apidef.HeaderSource:
+		rawToken, err = e.ExtractHeader(r)
+	case apidef.CookieSource:
+		rawToken, err = e.ExtractCookie(r)
+	default:
+		err = errors.New("JWTClaimExtractor only supports header or cookie sources")
+	}
+	if err != nil {
+		returnOverrides = e.Error(r, err, "JWTClaimExtractor error")
+		return SessionID, returnOverrides
+	}
+
+	rawToken = strings.TrimPrefix(rawToken, "Bearer ")
+
+	claims, err := decodeJWTClaimsUnverified(rawToken)
+	if err != nil {
+		returnOverrides = e.Error(r, err, "JWTClaimExtractor: couldn't decode token")
+		return SessionID, returnOverrides
+	}
+
+	claimValue, ok := claims[e.cfg.ClaimName].(string)
+	if !ok || claimValue == "" {
+		returnOverrides = e.Error(r, nil, "JWTClaimExtractor: claim not found")
+		return SessionID, returnOverrides
+	}
+
+	SessionID = e.GenerateSessionID(claimValue, e.BaseMid)
+
+	previousSession, keyExists := e.BaseMid.CheckSessionAndIdentityForValidKey(SessionID, r)
+	if keyExists {
+		if previousSession.IdExtractorDeadline > time.Now().Unix() {
+			ctxSetSession(r, &previousSession, SessionID, true)
+			returnOverrides = ReturnOverrides{
+				ResponseCode: 200,
+			}
+		}
+	}
+
+	return SessionID, returnOverrides
+}
+
+// decodeJWTClaimsUnverified base64-decodes a JWT's claims segment without
+// checking its signature.
+func decodeJWTClaimsUnverified(rawToken string) (map[string]interface{}, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token contains an invalid number of segments")
+	}
+
+	claimBytes, err := jwt.DecodeSegment(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{}
+	if err := json.Unmarshal(claimBytes, &claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
 // newExtractor is called from the CP middleware for every API that specifies extractor settings.
 func newExtractor(referenceSpec *APISpec, mw BaseMiddleware) {
 	var extractor IdExtractor
@@ -309,6 +418,8 @@ func newExtractor(referenceSpec *APISpec, mw BaseMiddleware) {
 		extractor = &RegexExtractor{baseExtractor, nil, nil}
 	case apidef.XPathExtractor:
 		extractor = &XPathExtractor{baseExtractor, nil, nil}
+	case apidef.JWTClaimExtractor:
+		extractor = &JWTClaimExtractor{baseExtractor, nil}
 	}
 
 	referenceSpec.CustomMiddleware.IdExtractor.Extractor = extractor
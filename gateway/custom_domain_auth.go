@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// customDomainAuthStore maps a customer-owned domain to the API key it
+// authenticates as, so the Host header itself can act as the credential for
+// low-risk, read-only white-label APIs.
+var customDomainAuthStore = storage.RedisCluster{KeyPrefix: "custom-domain-auth-"}
+
+func normaliseDomain(domain string) string {
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		domain = host
+	}
+	return strings.ToLower(domain)
+}
+
+func getCustomDomainKey(domain string) (string, bool) {
+	key, err := customDomainAuthStore.GetKey(normaliseDomain(domain))
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+func setCustomDomainKey(domain, key string) error {
+	return customDomainAuthStore.SetKey(normaliseDomain(domain), key, 0)
+}
+
+func deleteCustomDomainKey(domain string) {
+	customDomainAuthStore.DeleteKey(normaliseDomain(domain))
+}
+
+// CustomDomainAuth resolves the consumer identity from the request's Host
+// header via a registered custom domain, rather than an explicit credential
+// carried on the request. Intended for white-label, low-risk read APIs
+// where the domain has already been vetted as belonging to the key owner
+// (e.g. verified during onboarding), so the domain itself is the credential.
+type CustomDomainAuth struct {
+	BaseMiddleware
+}
+
+func (k *CustomDomainAuth) Name() string {
+	return "CustomDomainAuth"
+}
+
+func (k *CustomDomainAuth) EnabledForSpec() bool {
+	return k.Spec.UseCustomDomainAuth
+}
+
+func (k *CustomDomainAuth) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	key, ok := getCustomDomainKey(r.Host)
+	if !ok {
+		k.Logger().WithField("host", r.Host).Info("No custom domain mapping for host")
+		return errors.New("Access to this API has been disallowed"), http.StatusForbidden
+	}
+
+	session, keyExists := k.CheckSessionAndIdentityForValidKey(key, r)
+	if !keyExists {
+		k.Logger().WithField("host", r.Host).Info("Custom domain mapped to non-existent key")
+
+		AuthFailed(k, r, key)
+		reportHealthValue(k.Spec, KeyFailure, "1")
+
+		return errors.New("Access to this API has been disallowed"), http.StatusForbidden
+	}
+
+	switch k.Spec.BaseIdentityProvidedBy {
+	case apidef.AuthToken, apidef.UnsetAuth:
+		ctxSetSession(r, &session, key, false)
+	}
+
+	return nil, http.StatusOK
+}
+
+// customDomainAuthKeyRequest is the body accepted by POST/PUT on
+// /customdomains/{domain}.
+//
+// swagger:model
+type customDomainAuthKeyRequest struct {
+	Key string `json:"key"`
+}
+
+// customDomainAuthHandler implements GET/POST/PUT/DELETE for
+// /customdomains/{domain}, managing which key a custom domain resolves to.
+func customDomainAuthHandler(w http.ResponseWriter, r *http.Request) {
+	domain := mux.Vars(r)["domain"]
+
+	switch r.Method {
+	case http.MethodGet:
+		key, found := getCustomDomainKey(domain)
+		if !found {
+			doJSONWrite(w, http.StatusNotFound, apiError("No key mapped to this domain"))
+			return
+		}
+		doJSONWrite(w, http.StatusOK, customDomainAuthKeyRequest{Key: key})
+	case http.MethodPost, http.MethodPut:
+		var req customDomainAuthKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Key == "" {
+			doJSONWrite(w, http.StatusBadRequest, apiError("Request malformed"))
+			return
+		}
+		if err := setCustomDomainKey(domain, req.Key); err != nil {
+			doJSONWrite(w, http.StatusInternalServerError, apiError("Failed to persist domain mapping"))
+			return
+		}
+		doJSONWrite(w, http.StatusOK, apiOk("Domain mapping updated"))
+	case http.MethodDelete:
+		deleteCustomDomainKey(domain)
+		doJSONWrite(w, http.StatusOK, apiOk("Domain mapping removed"))
+	default:
+		doJSONWrite(w, http.StatusMethodNotAllowed, apiError("Method not supported"))
+	}
+}
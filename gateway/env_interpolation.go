@@ -0,0 +1,45 @@
+package gateway
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// envVarPattern matches ${VAR} or ${VAR:-default} references so the same API
+// definition can be promoted across environments without a templating step.
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnvVars replaces ${VAR} / ${VAR:-default} references in s with the
+// value of the named environment variable, falling back to the default (or
+// leaving the reference untouched if there's no default and it's unset).
+func expandEnvVars(s string) string {
+	if !strings.ContainsRune(s, '$') {
+		return s
+	}
+	return envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := envVarPattern.FindStringSubmatch(match)
+		if v, ok := os.LookupEnv(parts[1]); ok {
+			return v
+		}
+		if parts[2] != "" {
+			return parts[2]
+		}
+		return match
+	})
+}
+
+// interpolateEnvVars expands ${ENV_VAR} references in the API definition
+// fields that most commonly vary between environments - upstream targets and
+// the auth header name - at load time, so the same definition can be
+// promoted across environments unchanged.
+func interpolateEnvVars(def *apidef.APIDefinition) {
+	def.Proxy.TargetURL = expandEnvVars(def.Proxy.TargetURL)
+	for i, target := range def.Proxy.Targets {
+		def.Proxy.Targets[i] = expandEnvVars(target)
+	}
+	def.Proxy.Transport.ProxyURL = expandEnvVars(def.Proxy.Transport.ProxyURL)
+	def.AuthHeaderName = expandEnvVars(def.AuthHeaderName)
+}
@@ -0,0 +1,243 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/headers"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// Event handler names for the built-in alert notifiers, registered the same
+// way as EH_WebHook in EventHandlerByName.
+const (
+	EH_SlackHandler     apidef.TykEventHandlerName = "eh_slack_handler"
+	EH_PagerDutyHandler apidef.TykEventHandlerName = "eh_pagerduty_handler"
+	EH_EmailHandler     apidef.TykEventHandlerName = "eh_email_handler"
+)
+
+const (
+	defaultSlackTemplate     = `{"text": "[{{.Type}}] {{.Meta}}"}`
+	defaultPagerDutyTemplate = `{{.Meta}}`
+	defaultEmailTemplate     = "Tyk alert: {{.Type}}\n\n{{.Meta}}"
+)
+
+// alertCooldown suppresses repeat notifications for the same event type
+// within a window, so a burst of otherwise-identical events (a "spike" of
+// auth failures, say) produces one notification instead of one per
+// occurrence.
+type alertCooldown struct {
+	store        storage.Handler
+	cooldownSecs int64
+}
+
+func newAlertCooldown(keyPrefix string, cooldownSecs int64) *alertCooldown {
+	store := &storage.RedisCluster{KeyPrefix: keyPrefix}
+	store.Connect()
+	return &alertCooldown{store: store, cooldownSecs: cooldownSecs}
+}
+
+// allow reports whether a notification for this event type may be sent now
+// and, if so, starts the cooldown window.
+func (a *alertCooldown) allow(eventType apidef.TykEvent) bool {
+	if a.cooldownSecs <= 0 {
+		return true
+	}
+
+	key := string(eventType)
+	if _, err := a.store.GetKey(key); err == nil {
+		return false
+	}
+
+	a.store.SetKey(key, "1", a.cooldownSecs)
+	return true
+}
+
+// loadAlertTemplate parses a custom template if provided, otherwise falls
+// back to the given default, matching WebHookHandler.Init's fallback
+// behaviour.
+func loadAlertTemplate(prefix, templatePath, defaultBody string) *template.Template {
+	if templatePath != "" {
+		if tmpl, err := template.ParseFiles(templatePath); err == nil {
+			return tmpl
+		}
+		log.WithFields(logrus.Fields{
+			"prefix": prefix,
+		}).Warning("Custom template load failure, using default")
+	}
+
+	return template.Must(template.New(prefix + "_default").Parse(defaultBody))
+}
+
+func renderAlertBody(tmpl *template.Template, em config.EventMessage) string {
+	var buf bytes.Buffer
+	tmpl.Execute(&buf, em)
+	return buf.String()
+}
+
+// SlackEventHandler posts a templated message to a Slack incoming webhook URL.
+type SlackEventHandler struct {
+	conf     config.AlertHandlerConf
+	template *template.Template
+	cooldown *alertCooldown
+}
+
+func (s *SlackEventHandler) Init(handlerConf interface{}) error {
+	if err := unmarshalAlertHandlerConf(handlerConf, &s.conf); err != nil {
+		return err
+	}
+
+	s.template = loadAlertTemplate("slack-alerts", s.conf.TemplatePath, defaultSlackTemplate)
+	s.cooldown = newAlertCooldown("alert.slack.", s.conf.CooldownSecs)
+	return nil
+}
+
+func (s *SlackEventHandler) HandleEvent(em config.EventMessage) {
+	if !s.cooldown.allow(em.Type) {
+		return
+	}
+
+	body := renderAlertBody(s.template, em)
+	if err := postJSON(s.conf.TargetPath, body); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "slack-alerts",
+		}).Error("Failed to deliver Slack alert: ", err)
+	}
+}
+
+// PagerDutyEventHandler triggers a PagerDuty Events API v2 incident.
+type PagerDutyEventHandler struct {
+	conf     config.AlertHandlerConf
+	template *template.Template
+	cooldown *alertCooldown
+}
+
+func (p *PagerDutyEventHandler) Init(handlerConf interface{}) error {
+	if err := unmarshalAlertHandlerConf(handlerConf, &p.conf); err != nil {
+		return err
+	}
+
+	p.template = loadAlertTemplate("pagerduty-alerts", p.conf.TemplatePath, defaultPagerDutyTemplate)
+	p.cooldown = newAlertCooldown("alert.pagerduty.", p.conf.CooldownSecs)
+	return nil
+}
+
+func (p *PagerDutyEventHandler) HandleEvent(em config.EventMessage) {
+	if !p.cooldown.allow(em.Type) {
+		return
+	}
+
+	severity := p.conf.Severity
+	if severity == "" {
+		severity = "error"
+	}
+
+	payload := map[string]interface{}{
+		"routing_key":  p.conf.RoutingKey,
+		"event_action": "trigger",
+		"payload": map[string]interface{}{
+			"summary":  renderAlertBody(p.template, em),
+			"source":   "tyk-gateway",
+			"severity": severity,
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "pagerduty-alerts",
+		}).Error("Failed to encode PagerDuty payload: ", err)
+		return
+	}
+
+	targetPath := p.conf.TargetPath
+	if targetPath == "" {
+		targetPath = "https://events.pagerduty.com/v2/enqueue"
+	}
+
+	if err := postJSON(targetPath, string(body)); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "pagerduty-alerts",
+		}).Error("Failed to deliver PagerDuty alert: ", err)
+	}
+}
+
+// EmailEventHandler delivers a templated alert body over SMTP.
+type EmailEventHandler struct {
+	conf     config.AlertHandlerConf
+	template *template.Template
+	cooldown *alertCooldown
+}
+
+func (e *EmailEventHandler) Init(handlerConf interface{}) error {
+	if err := unmarshalAlertHandlerConf(handlerConf, &e.conf); err != nil {
+		return err
+	}
+
+	e.template = loadAlertTemplate("email-alerts", e.conf.TemplatePath, defaultEmailTemplate)
+	e.cooldown = newAlertCooldown("alert.email.", e.conf.CooldownSecs)
+	return nil
+}
+
+func (e *EmailEventHandler) HandleEvent(em config.EventMessage) {
+	if !e.cooldown.allow(em.Type) || len(e.conf.To) == 0 {
+		return
+	}
+
+	subject := "Subject: Tyk alert: " + string(em.Type)
+	body := renderAlertBody(e.template, em)
+	msg := []byte(subject + "\r\n\r\n" + body)
+
+	var auth smtp.Auth
+	if e.conf.SMTPUsername != "" {
+		host := e.conf.SMTPAddress
+		if idx := strings.Index(host, ":"); idx != -1 {
+			host = host[:idx]
+		}
+		auth = smtp.PlainAuth("", e.conf.SMTPUsername, e.conf.SMTPPassword, host)
+	}
+
+	if err := smtp.SendMail(e.conf.SMTPAddress, auth, e.conf.From, e.conf.To, msg); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "email-alerts",
+		}).Error("Failed to deliver email alert: ", err)
+	}
+}
+
+func unmarshalAlertHandlerConf(handlerConf interface{}, out *config.AlertHandlerConf) error {
+	asJSON, err := json.Marshal(handlerConf)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(asJSON, out)
+}
+
+func postJSON(targetURL, body string) error {
+	req, err := http.NewRequest(http.MethodPost, targetURL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(headers.ContentType, headers.ApplicationJSON)
+
+	cli := &http.Client{Timeout: 30 * time.Second}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert delivery failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
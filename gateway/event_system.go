@@ -33,6 +33,12 @@ const (
 	EventTokenCreated         apidef.TykEvent = "TokenCreated"
 	EventTokenUpdated         apidef.TykEvent = "TokenUpdated"
 	EventTokenDeleted         apidef.TykEvent = "TokenDeleted"
+	EventAPIAutoDisabled      apidef.TykEvent = "APIAutoDisabled"
+	EventRPCSourceDegraded    apidef.TykEvent = "RPCSourceDegraded"
+	EventResponseDiffMismatch apidef.TykEvent = "ResponseDiffMismatch"
+	EventQuotaOverage         apidef.TykEvent = "QuotaOverage"
+	EventTargetUnreachable    apidef.TykEvent = "TargetUnreachable"
+	EventWebSocketMessage     apidef.TykEvent = "WebSocketMessage"
 )
 
 // EventMetaDefault is a standard embedded struct to be used with custom event metadata types, gives an interface for
@@ -47,6 +53,35 @@ type EventHostStatusMeta struct {
 	HostInfo HostHealthReport
 }
 
+// EventTargetUnreachableMeta is fired when probeTargets can't reach an
+// API's upstream target at load time, so it's caught before customers hit it.
+type EventTargetUnreachableMeta struct {
+	EventMetaDefault
+	APIID     string
+	TargetURL string
+	Error     string
+}
+
+// EventWebSocketMessageMeta is fired for every client->upstream message on a
+// proxied WebSocket connection when APIDefinition.WebSocketHooks.FireEvents
+// is set, letting an event handler act on individual frames (e.g. audit
+// logging, or a coprocess-driven event handler doing custom validation).
+type EventWebSocketMessageMeta struct {
+	EventMetaDefault
+	APIID   string
+	Origin  string
+	Payload string
+}
+
+// EventAPIAutoDisabledMeta is fired when an API is automatically disabled
+// after repeatedly failing to load, so operators can see why it dropped
+// out of the router instead of it quietly disappearing.
+type EventAPIAutoDisabledMeta struct {
+	EventMetaDefault
+	APIID  string
+	Reason string
+}
+
 // EventKeyFailureMeta is the metadata structure for any failure related
 // to a key, such as quota or auth failures.
 type EventKeyFailureMeta struct {
@@ -56,6 +91,17 @@ type EventKeyFailureMeta struct {
 	Key    string
 }
 
+// EventQuotaOverageMeta fires when a request is let through past its quota
+// under a policy's overage allowance, so overage usage can be billed or
+// alerted on separately from a hard cutoff.
+type EventQuotaOverageMeta struct {
+	EventMetaDefault
+	Path    string
+	Origin  string
+	Key     string
+	Overage int64
+}
+
 // EventCurcuitBreakerMeta is the event status for a circuit breaker tripping
 type EventCurcuitBreakerMeta struct {
 	EventMetaDefault
@@ -87,6 +133,25 @@ type EventTokenMeta struct {
 	Key string
 }
 
+// EventRPCSourceDegradedMeta is fired when the RPC (MDCB) control plane is
+// unreachable and the gateway has fallen back to a locally persisted
+// snapshot of API definitions and policies, so operators can see that the
+// node is running on stale, degraded configuration.
+type EventRPCSourceDegradedMeta struct {
+	EventMetaDefault
+	Source string
+}
+
+// EventResponseDiffMismatchMeta is fired when a shadowed request's response
+// diverges from the primary response, so operators get a correctness signal
+// while migrating to a new upstream without having to comb through logs.
+type EventResponseDiffMismatchMeta struct {
+	EventMetaDefault
+	Path       string
+	StatusDiff string
+	Diff       []string
+}
+
 // EncodeRequestToEvent will write the request out in wire protocol and
 // encode it to base64 and store it in an Event object
 func EncodeRequestToEvent(r *http.Request) string {
@@ -109,6 +174,18 @@ func EventHandlerByName(handlerConf apidef.EventHandlerTriggerConfig, spec *APIS
 		h := &WebHookHandler{}
 		err := h.Init(conf)
 		return h, err
+	case EH_SlackHandler:
+		h := &SlackEventHandler{}
+		err := h.Init(conf)
+		return h, err
+	case EH_PagerDutyHandler:
+		h := &PagerDutyEventHandler{}
+		err := h.Init(conf)
+		return h, err
+	case EH_EmailHandler:
+		h := &EmailEventHandler{}
+		err := h.Init(conf)
+		return h, err
 	case EH_JSVMHandler:
 		// Load the globals and file here
 		if spec != nil {
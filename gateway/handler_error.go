@@ -35,6 +35,8 @@ type ErrorHandler struct {
 func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMsg string, errCode int, writeResponse bool) {
 	defer e.Base().UpdateRequestSession(r)
 
+	recordRequestMetric(e.Spec, r, errCode, mwTimingsTotalMs(r))
+
 	if writeResponse {
 		var templateExtension string
 		var contentType string
@@ -130,9 +132,17 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 			tags = tagHeaders(r, e.Spec.TagHeaders, tags)
 		}
 
+		if len(e.Spec.TagBaggageKeys) > 0 {
+			tags = tagBaggage(r, e.Spec.TagBaggageKeys, tags)
+		}
+
+		if ctxGetCertPinningMismatch(r) {
+			tags = append(tags, "cert-pinning-mismatch")
+		}
+
 		rawRequest := ""
 		rawResponse := ""
-		if recordDetail(r, e.Spec.GlobalConfig) {
+		if recordDetail(r, e.Spec.GlobalConfig) || selectiveDetailTrigger(e.Spec, errCode, nil) {
 			// Get the wire format representation
 			var wireFormatReq bytes.Buffer
 			r.Write(&wireFormatReq)
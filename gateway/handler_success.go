@@ -69,6 +69,25 @@ func tagHeaders(r *http.Request, th []string, tags []string) []string {
 	return tags
 }
 
+// tagBaggage tags a request with any requested W3C baggage members, the
+// same way tagHeaders tags on arbitrary headers, so an experiment started
+// upstream in the call chain shows up in analytics without needing its own
+// header convention.
+func tagBaggage(r *http.Request, keys []string, tags []string) []string {
+	if len(keys) == 0 {
+		return tags
+	}
+
+	baggage := parseBaggageHeader(r.Header.Get("baggage"))
+	for _, key := range keys {
+		if val, ok := baggage[key]; ok {
+			tags = append(tags, "baggage-"+key+"-"+val)
+		}
+	}
+
+	return tags
+}
+
 func addVersionHeader(w http.ResponseWriter, r *http.Request, globalConf config.Config) {
 	if ctxGetDefaultVersion(r) {
 		if vinfo := ctxGetVersionInfo(r); vinfo != nil {
@@ -98,6 +117,7 @@ func estimateTagsCapacity(session *user.SessionState, apiSpec *APISpec) int {
 	}
 
 	size += len(apiSpec.TagHeaders)
+	size += len(apiSpec.TagBaggageKeys)
 
 	return size
 }
@@ -123,10 +143,15 @@ func getSessionTags(session *user.SessionState) []string {
 
 func (s *SuccessHandler) RecordHit(r *http.Request, timing int64, code int, responseCopy *http.Response) {
 
+	recordUpstreamLoad(s.Spec.APIID, s.Spec.LoadShedding, responseCopy)
+	recordRequestMetric(s.Spec, r, code, float64(timing))
+
 	if s.Spec.DoNotTrack {
 		return
 	}
 
+	recordAnomalySample(s.Spec, code, timing)
+
 	ip := request.RealIP(r)
 	if s.Spec.GlobalConfig.StoreAnalytics(ip) {
 
@@ -156,14 +181,36 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing int64, code int, resp
 			tags = tagHeaders(r, s.Spec.TagHeaders, tags)
 		}
 
+		if len(s.Spec.TagBaggageKeys) > 0 {
+			tags = tagBaggage(r, s.Spec.TagBaggageKeys, tags)
+		}
+
+		if ctxGetQuotaOverage(r) > 0 {
+			tags = append(tags, "quota-overage")
+		}
+
 		rawRequest := ""
 		rawResponse := ""
 
-		if recordDetail(r, s.Spec.GlobalConfig) {
+		var resHeader http.Header
+		if responseCopy != nil {
+			resHeader = responseCopy.Header
+		}
+
+		if recordDetail(r, s.Spec.GlobalConfig) || selectiveDetailTrigger(s.Spec, code, resHeader) {
+			redaction := effectiveRedaction(s.Spec.GlobalConfig.AnalyticsConfig.Redaction, s.Spec.AnalyticsRedactionRules)
+			if redaction.Enabled {
+				redactHeaders(r.Header, redaction.Headers)
+			}
+
 			// Get the wire format representation
 			var wireFormatReq bytes.Buffer
 			r.Write(&wireFormatReq)
-			rawRequest = base64.StdEncoding.EncodeToString(wireFormatReq.Bytes())
+			reqBytes := wireFormatReq.Bytes()
+			if redaction.Enabled {
+				reqBytes = redactBytes(reqBytes, redaction.Regexes)
+			}
+			rawRequest = base64.StdEncoding.EncodeToString(reqBytes)
 			// responseCopy, unlike requestCopy, can be nil
 			// here - if the response was cached in
 			// mw_redis_cache, RecordHit gets passed a nil
@@ -179,11 +226,19 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing int64, code int, resp
 
 				responseCopy.Body = respBodyReader(r, responseCopy)
 
+				if redaction.Enabled {
+					redactHeaders(responseCopy.Header, redaction.Headers)
+				}
+
 				// Get the wire format representation
 				var wireFormatRes bytes.Buffer
 				responseCopy.Write(&wireFormatRes)
 				responseCopy.Body = ioutil.NopCloser(bytes.NewBuffer(contents))
-				rawResponse = base64.StdEncoding.EncodeToString(wireFormatRes.Bytes())
+				resBytes := wireFormatRes.Bytes()
+				if redaction.Enabled {
+					resBytes = redactBytes(resBytes, redaction.Regexes)
+				}
+				rawResponse = base64.StdEncoding.EncodeToString(resBytes)
 			}
 		}
 
@@ -199,6 +254,14 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing int64, code int, resp
 			host = s.Spec.target.Host
 		}
 
+		obfuscation := effectiveObfuscation(s.Spec.GlobalConfig.AnalyticsConfig.Obfuscation, s.Spec.AnalyticsObfuscationRules)
+		recordedToken := token
+		recordedIP := ip
+		if obfuscation.Enabled {
+			recordedToken = obfuscateKeyWith(token, obfuscation)
+			recordedIP = obfuscateIP(ip, obfuscation)
+		}
+
 		record := AnalyticsRecord{
 			r.Method,
 			host,
@@ -211,7 +274,7 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing int64, code int, resp
 			t.Year(),
 			t.Hour(),
 			code,
-			token,
+			recordedToken,
 			t,
 			version,
 			s.Spec.Name,
@@ -221,7 +284,7 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing int64, code int, resp
 			timing,
 			rawRequest,
 			rawResponse,
-			ip,
+			recordedIP,
 			GeoData{},
 			NetworkStats{},
 			tags,
@@ -277,6 +340,30 @@ func recordDetail(r *http.Request, globalConf config.Config) bool {
 	return ses.(user.SessionState).EnableDetailedRecording
 }
 
+// selectiveDetailTrigger reports whether spec's SelectiveDetailedRecording
+// rules mark this response as one to capture in detail even though
+// recordDetail alone wouldn't have, so bodies only get captured for the
+// hits that turned out to matter (an error status, or an upstream-flagged
+// debug header) instead of always-on detailed recording.
+func selectiveDetailTrigger(spec *APISpec, code int, resHeader http.Header) bool {
+	if spec == nil || !spec.SelectiveDetailedRecording.Enabled {
+		return false
+	}
+
+	rule := spec.SelectiveDetailedRecording
+	for _, c := range rule.StatusCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	if rule.TriggerHeader != "" && resHeader != nil && resHeader.Get(rule.TriggerHeader) != "" {
+		return true
+	}
+
+	return false
+}
+
 // ServeHTTP will store the request details in the analytics store if necessary and proxy the request to it's
 // final destination, this is invoked by the ProxyHandler or right at the start of a request chain if the URL
 // Spec states the path is Ignored
@@ -304,6 +391,12 @@ func (s *SuccessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) *http
 
 	addVersionHeader(w, r, s.Spec.GlobalConfig)
 
+	var shadowBody []byte
+	if s.Spec.TrafficShadowing.Enabled && r.Body != nil {
+		shadowBody, _ = ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(shadowBody))
+	}
+
 	t1 := time.Now()
 	resp := s.Proxy.ServeHTTP(w, r)
 	t2 := time.Now()
@@ -311,9 +404,16 @@ func (s *SuccessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) *http
 	millisec := float64(t2.UnixNano()-t1.UnixNano()) * 0.000001
 	log.Debug("Upstream request took (ms): ", millisec)
 
+	checkSlowLog(r, s.Spec, millisec)
+
 	if resp != nil {
 		s.RecordHit(r, int64(millisec), resp.StatusCode, resp)
 	}
+
+	if s.Spec.TrafficShadowing.Enabled {
+		go s.shadowRequest(r, shadowBody, resp)
+	}
+
 	log.Debug("Done proxy")
 	return nil
 }
@@ -31,6 +31,7 @@ type WSDialer struct {
 	*http.Transport
 	RW        http.ResponseWriter
 	TLSConfig *tls.Config
+	Spec      *APISpec
 }
 
 func (ws *WSDialer) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -103,8 +104,12 @@ func (ws *WSDialer) RoundTrip(req *http.Request) (*http.Response, error) {
 		_, err := io.Copy(dst, src)
 		errc <- err
 	}
-	go cp(d, nc)
 	go cp(nc, d)
+	if ws.Spec != nil && ws.Spec.WebSocketHooks.Enabled {
+		go relayWSMessages(d, nc, ws.Spec, errc)
+	} else {
+		go cp(d, nc)
+	}
 
 	for i := 0; i < 2; i++ {
 		cerr := <-errc
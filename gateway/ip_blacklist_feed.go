@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const ipBlacklistFeedMinRefreshInterval = 60 * time.Second
+
+// ipBlacklistFeed periodically fetches a plain-text CIDR/IP list from a
+// reputation feed and makes the latest successfully parsed copy available
+// via get(), without ever blocking a request on the network. A failed
+// fetch just leaves the previous (or empty) list in place until the next
+// tick succeeds.
+type ipBlacklistFeed struct {
+	cache  atomic.Value // []string
+	cancel context.CancelFunc
+	url    string
+	period time.Duration
+}
+
+func (f *ipBlacklistFeed) get() []string {
+	if v := f.cache.Load(); v != nil {
+		return v.([]string)
+	}
+	return nil
+}
+
+func (f *ipBlacklistFeed) loop(ctx context.Context, apiID string) {
+	f.refresh(apiID)
+	tick := time.NewTicker(f.period)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			f.refresh(apiID)
+		}
+	}
+}
+
+func (f *ipBlacklistFeed) refresh(apiID string) {
+	entries, err := fetchIPBlacklistFeed(f.url)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "ip-blacklist-feed",
+			"api_id": apiID,
+			"url":    f.url,
+		}).Error("Failed to refresh IP blacklist feed: ", err)
+		return
+	}
+	f.cache.Store(entries)
+}
+
+// fetchIPBlacklistFeed fetches url and parses it as a newline-separated
+// list of plain IPs and/or CIDR ranges. Blank lines and lines starting
+// with "#" are ignored, matching the format used by common feeds like
+// Spamhaus' DROP list.
+func fetchIPBlacklistFeed(url string) ([]string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d fetching feed", resp.StatusCode)
+	}
+
+	var entries []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+var ipBlacklistFeeds = struct {
+	mu    sync.Mutex
+	byAPI map[string]*ipBlacklistFeed
+}{byAPI: make(map[string]*ipBlacklistFeed)}
+
+// ensureIPBlacklistFeed starts (or restarts, if the URL/interval changed)
+// the background refresh loop for spec's IP blacklist feed. It's safe to
+// call on every reload of spec - a no-op if the feed is already running
+// with the same configuration.
+func ensureIPBlacklistFeed(spec *APISpec) {
+	cfg := spec.IPBlacklistFeed
+
+	ipBlacklistFeeds.mu.Lock()
+	defer ipBlacklistFeeds.mu.Unlock()
+
+	existing := ipBlacklistFeeds.byAPI[spec.APIID]
+
+	if !cfg.Enabled {
+		if existing != nil {
+			existing.cancel()
+			delete(ipBlacklistFeeds.byAPI, spec.APIID)
+		}
+		return
+	}
+
+	if existing != nil && existing.url == cfg.URL {
+		return
+	}
+
+	if existing != nil {
+		existing.cancel()
+	}
+
+	period := time.Duration(cfg.RefreshInterval) * time.Second
+	if period < ipBlacklistFeedMinRefreshInterval {
+		period = ipBlacklistFeedMinRefreshInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &ipBlacklistFeed{cancel: cancel, url: cfg.URL, period: period}
+	ipBlacklistFeeds.byAPI[spec.APIID] = f
+	go f.loop(ctx, spec.APIID)
+}
+
+// ipBlacklistFeedEntries returns the most recently fetched entries for
+// spec's feed, or nil if the feed isn't enabled or hasn't fetched
+// successfully yet.
+func ipBlacklistFeedEntries(apiID string) []string {
+	ipBlacklistFeeds.mu.Lock()
+	f := ipBlacklistFeeds.byAPI[apiID]
+	ipBlacklistFeeds.mu.Unlock()
+
+	if f == nil {
+		return nil
+	}
+	return f.get()
+}
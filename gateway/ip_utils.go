@@ -0,0 +1,40 @@
+package gateway
+
+import "net"
+
+// ipInList reports whether remoteIP matches any entry in ips, where each
+// entry may be a plain IP or a CIDR range, in either IPv4 or IPv6 form.
+// IPv4 and IPv4-mapped IPv6 addresses are normalised before comparison so a
+// mixed-family list behaves the same whether the client arrived over an
+// IPv4 or a dual-stack IPv6 connection.
+func ipInList(remoteIP net.IP, ips []string) bool {
+	if remoteIP == nil {
+		return false
+	}
+
+	if v4 := remoteIP.To4(); v4 != nil {
+		remoteIP = v4
+	}
+
+	for _, entry := range ips {
+		if listedIP, listedNet, err := net.ParseCIDR(entry); err == nil {
+			if listedNet.Contains(remoteIP) {
+				return true
+			}
+			continue
+		} else {
+			listedIP = net.ParseIP(entry)
+			if listedIP == nil {
+				continue
+			}
+			if v4 := listedIP.To4(); v4 != nil {
+				listedIP = v4
+			}
+			if listedIP.Equal(remoteIP) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
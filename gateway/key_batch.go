@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+)
+
+// keyBatchOp describes a single operation within a bulk key request.
+//
+// swagger:model keyBatchOp
+type keyBatchOp struct {
+	Method  string          `json:"method"`
+	KeyName string          `json:"key_name"`
+	APIID   string          `json:"api_id"`
+	Hashed  bool            `json:"hashed"`
+	Session json.RawMessage `json:"session,omitempty"`
+}
+
+// keyBatchOpResult carries the outcome of a single keyBatchOp so that a
+// partial failure in one item doesn't stop the rest of the batch from
+// being processed.
+//
+// swagger:model keyBatchOpResult
+type keyBatchOpResult struct {
+	KeyName string      `json:"key_name"`
+	Code    int         `json:"code"`
+	Result  interface{} `json:"result"`
+}
+
+const maxKeyBatchSize = 1000
+
+// handleKeyBatch applies a list of key operations one by one, in order,
+// and reports the result of each so that provisioning many keys doesn't
+// require one control API round trip per key.
+func handleKeyBatch(ops []keyBatchOp) ([]keyBatchOpResult, int) {
+	results := make([]keyBatchOpResult, len(ops))
+
+	for i, op := range ops {
+		var obj interface{}
+		var code int
+
+		switch op.Method {
+		case http.MethodPost, http.MethodPut:
+			req, err := http.NewRequest(op.Method, "/tyk/keys/"+op.KeyName, ioutil.NopCloser(bytes.NewReader(op.Session)))
+			if err != nil {
+				obj, code = apiError("Request malformed"), http.StatusBadRequest
+				break
+			}
+			obj, code = handleAddOrUpdate(op.KeyName, req, op.Hashed)
+		case http.MethodDelete:
+			if op.Hashed {
+				obj, code = handleDeleteHashedKey(op.KeyName, op.APIID, true)
+			} else {
+				obj, code = handleDeleteKey(op.KeyName, op.APIID, true)
+			}
+		default:
+			obj, code = apiError("unsupported method in batch operation: "+op.Method), http.StatusBadRequest
+		}
+
+		results[i] = keyBatchOpResult{KeyName: op.KeyName, Code: code, Result: obj}
+	}
+
+	return results, http.StatusOK
+}
+
+func keyBatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		doJSONWrite(w, http.StatusMethodNotAllowed, apiError("Method not supported"))
+		return
+	}
+
+	var ops []keyBatchOp
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Request malformed"))
+		return
+	}
+
+	if len(ops) == 0 {
+		doJSONWrite(w, http.StatusBadRequest, apiError("batch must contain at least one operation"))
+		return
+	}
+
+	if len(ops) > maxKeyBatchSize {
+		doJSONWrite(w, http.StatusBadRequest, apiError("batch too large"))
+		return
+	}
+
+	results, code := handleKeyBatch(ops)
+	doJSONWrite(w, code, results)
+}
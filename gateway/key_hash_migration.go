@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+// keyHashLengths maps the hex-encoded digest length produced by each hash
+// algorithm to its name, so a stored (already-hashed) key name can be
+// classified without needing the original, unhashed token.
+var keyHashLengths = map[int]string{
+	8:  "murmur32",
+	16: "murmur64",
+	32: "murmur128",
+	64: "sha256",
+}
+
+// keyHashMigrationStatus reports how many stored keys are hashed with each
+// algorithm, so operators can tell when a hash algorithm migration (e.g.
+// murmur64 -> sha256) has finished draining the old algorithm.
+//
+// swagger:model
+type keyHashMigrationStatus struct {
+	HashKeyFunction string         `json:"hash_key_function"`
+	HashingEnabled  bool           `json:"hashing_enabled"`
+	TotalKeys       int            `json:"total_keys"`
+	ByAlgorithm     map[string]int `json:"by_algorithm"`
+}
+
+// classifyKeyHash returns the hash algorithm name a stored key name was
+// likely produced with, based on its digest length, or "unknown" if it
+// doesn't match any known algorithm's output length.
+func classifyKeyHash(keyName string) string {
+	if algo, ok := keyHashLengths[len(keyName)]; ok {
+		return algo
+	}
+	return "unknown"
+}
+
+// keyHashMigrationHandler reports migration progress between key hash
+// algorithms by classifying every stored key by its digest length.
+func keyHashMigrationHandler(w http.ResponseWriter, r *http.Request) {
+	if !config.Global().HashKeys {
+		doJSONWrite(w, http.StatusOK, keyHashMigrationStatus{
+			HashKeyFunction: config.Global().HashKeyFunction,
+			HashingEnabled:  false,
+			ByAlgorithm:     map[string]int{},
+		})
+		return
+	}
+
+	apiID := r.URL.Query().Get("api_id")
+	sessionManager := FallbackKeySesionManager
+	if spec := getApiSpec(apiID); spec != nil {
+		sessionManager = spec.SessionManager
+	}
+
+	status := keyHashMigrationStatus{
+		HashKeyFunction: config.Global().HashKeyFunction,
+		HashingEnabled:  true,
+		ByAlgorithm:     map[string]int{},
+	}
+
+	for _, keyName := range sessionManager.Sessions("") {
+		if strings.HasPrefix(keyName, QuotaKeyPrefix) || strings.HasPrefix(keyName, RateLimitKeyPrefix) {
+			continue
+		}
+		status.TotalKeys++
+		status.ByAlgorithm[classifyKeyHash(keyName)]++
+	}
+
+	doJSONWrite(w, http.StatusOK, status)
+}
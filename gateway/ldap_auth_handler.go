@@ -229,3 +229,8 @@ func (l LDAPStorageHandler) RemoveSortedSetRange(keyName, scoreFrom, scoreTo str
 	log.Error("Not implemented")
 	return nil
 }
+
+func (l LDAPStorageHandler) RemoveFromSortedSet(keyName, value string) error {
+	log.Error("Not implemented")
+	return nil
+}
@@ -0,0 +1,39 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// listenPathAliasHandler lets alias.ListenPath keep routing to the API
+// registered under canonicalListenPath, by rewriting the request path from
+// the alias prefix to the canonical one before handing off to next - the
+// same middleware chain that already serves canonicalListenPath - so
+// everything downstream (path matching, StripListenPath) behaves exactly as
+// if the request had come in on canonicalListenPath to begin with.
+//
+// Only static (non-parameterized) listen paths are supported as aliases;
+// that covers the plain rename case this feature exists for.
+func listenPathAliasHandler(alias apidef.ListenPathAlias, canonicalListenPath string, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if alias.DeprecationHeader != "" {
+			w.Header().Set("Deprecation", alias.DeprecationHeader)
+		}
+
+		newPath := canonicalListenPath + strings.TrimPrefix(r.URL.Path, alias.ListenPath)
+
+		if alias.Redirect {
+			http.Redirect(w, r, newPath, http.StatusMovedPermanently)
+			return
+		}
+
+		r.URL.Path = newPath
+		if r.URL.RawPath != "" {
+			r.URL.RawPath = canonicalListenPath + strings.TrimPrefix(r.URL.RawPath, alias.ListenPath)
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
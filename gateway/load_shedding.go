@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// loadShedState tracks the most recently observed load level for one API,
+// either reported via LoadSheddingConfig.LoadHeaderName on its responses or
+// derived from its circuit breaker tripping.
+type loadShedState struct {
+	mu   sync.RWMutex
+	load float64
+}
+
+func (s *loadShedState) set(v float64) {
+	s.mu.Lock()
+	s.load = v
+	s.mu.Unlock()
+}
+
+func (s *loadShedState) get() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.load
+}
+
+var loadShedStates sync.Map // APIID -> *loadShedState
+
+func loadShedStateFor(apiID string) *loadShedState {
+	v, _ := loadShedStates.LoadOrStore(apiID, &loadShedState{})
+	return v.(*loadShedState)
+}
+
+// recordUpstreamLoad parses conf.LoadHeaderName off resp and remembers it as
+// apiID's current load level, for the load shedding middleware to consult on
+// subsequent requests. It's a no-op if load shedding isn't enabled, no
+// header name is configured, or the header is absent or unparseable.
+func recordUpstreamLoad(apiID string, conf apidef.LoadSheddingConfig, resp *http.Response) {
+	if !conf.Enabled || conf.LoadHeaderName == "" || resp == nil {
+		return
+	}
+
+	raw := resp.Header.Get(conf.LoadHeaderName)
+	if raw == "" {
+		return
+	}
+
+	load, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return
+	}
+
+	loadShedStateFor(apiID).set(load)
+}
+
+// recordBreakerLoad is called from the circuit breaker's event loop when
+// UseCircuitBreakerErrorRate is set, so a tripped breaker also counts as
+// maximum load for shedding purposes, and a reset breaker clears it again.
+func recordBreakerLoad(apiID string, tripped bool) {
+	load := 0.0
+	if tripped {
+		load = 1.0
+	}
+	loadShedStateFor(apiID).set(load)
+}
+
+// currentLoad returns the highest known load signal for spec's API.
+func currentLoad(spec *APISpec) float64 {
+	return loadShedStateFor(spec.APIID).get()
+}
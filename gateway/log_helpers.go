@@ -18,10 +18,7 @@ func obfuscateKey(keyName string) string {
 		return keyName
 	}
 
-	if len(keyName) > 4 {
-		return "****" + keyName[len(keyName)-4:]
-	}
-	return "--"
+	return obfuscateKeyWith(keyName, config.Global().AnalyticsConfig.Obfuscation)
 }
 
 func getLogEntryForRequest(logger *logrus.Entry, r *http.Request, key string, data map[string]interface{}) *logrus.Entry {
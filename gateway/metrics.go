@@ -0,0 +1,270 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metricsHistogramBoundsMs are the upper bounds, in milliseconds, of the
+// latency histogram buckets exposed at /metrics. They mirror Prometheus'
+// commonly used default buckets, scaled from seconds to the milliseconds
+// this package already measures latency in.
+var metricsHistogramBoundsMs = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// metricsHistogram is a minimal, hand-rolled Prometheus histogram: fixed
+// buckets, a running sum and a running count, all guarded by a mutex. It
+// deliberately doesn't pull in a Prometheus client library, since none is
+// vendored in this tree.
+type metricsHistogram struct {
+	mu     sync.Mutex
+	counts []uint64 // per-bucket counts, one more than metricsHistogramBoundsMs for the +Inf bucket
+	sum    float64
+	count  uint64
+}
+
+func newMetricsHistogram() *metricsHistogram {
+	return &metricsHistogram{counts: make([]uint64, len(metricsHistogramBoundsMs)+1)}
+}
+
+func (h *metricsHistogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sum += v
+	h.count++
+	for i, bound := range metricsHistogramBoundsMs {
+		if v <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+	h.counts[len(metricsHistogramBoundsMs)]++
+}
+
+// cumulativeCounts returns the bucket counts as Prometheus expects them:
+// each bucket's count includes every narrower bucket's.
+func (h *metricsHistogram) cumulativeCounts() ([]uint64, float64, uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cumulative := make([]uint64, len(h.counts))
+	var running uint64
+	for i, c := range h.counts {
+		running += c
+		cumulative[i] = running
+	}
+	return cumulative, h.sum, h.count
+}
+
+// requestMetricKey identifies one (API, endpoint) pair for the request
+// latency/count/error-rate metrics.
+type requestMetricKey struct {
+	apiID  string
+	name   string
+	method string
+	path   string
+}
+
+type requestMetricEntry struct {
+	latency *metricsHistogram
+
+	mu     sync.Mutex
+	total  uint64
+	status map[int]uint64
+}
+
+func newRequestMetricEntry() *requestMetricEntry {
+	return &requestMetricEntry{latency: newMetricsHistogram(), status: map[int]uint64{}}
+}
+
+func (e *requestMetricEntry) observe(latencyMs float64, statusCode int) {
+	e.latency.observe(latencyMs)
+
+	e.mu.Lock()
+	e.total++
+	e.status[statusCode]++
+	e.mu.Unlock()
+}
+
+func (e *requestMetricEntry) snapshotStatus() map[int]uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	out := make(map[int]uint64, len(e.status))
+	for code, n := range e.status {
+		out[code] = n
+	}
+	return out
+}
+
+var (
+	requestMetricsMu sync.Mutex
+	requestMetrics   = map[requestMetricKey]*requestMetricEntry{}
+
+	mwMetricsMu sync.Mutex
+	mwMetrics   = map[string]*metricsHistogram{}
+
+	rateLimitRejectionsMu sync.Mutex
+	rateLimitRejections   = map[string]uint64{}
+)
+
+// recordRequestMetric feeds one completed (or failed) request into the
+// per-API, per-endpoint metrics exposed at /metrics.
+func recordRequestMetric(spec *APISpec, r *http.Request, statusCode int, latencyMs float64) {
+	if spec == nil {
+		return
+	}
+
+	key := requestMetricKey{
+		apiID:  spec.APIID,
+		name:   spec.Name,
+		method: r.Method,
+		path:   r.URL.Path,
+	}
+
+	requestMetricsMu.Lock()
+	entry, ok := requestMetrics[key]
+	if !ok {
+		entry = newRequestMetricEntry()
+		requestMetrics[key] = entry
+	}
+	requestMetricsMu.Unlock()
+
+	entry.observe(latencyMs, statusCode)
+}
+
+// recordMiddlewareExecMetric feeds one middleware's execution time, from
+// createMiddleware, into the per-middleware-name histogram exposed at
+// /metrics. It also tallies rate-limit rejections, since the rate-limiting
+// middlewares (RateLimitAndQuotaCheck, ExternalRateLimitMiddleware, etc.)
+// all signal their rejection the same way: a 429 status returned from
+// ProcessRequest.
+func recordMiddlewareExecMetric(apiID, mwName string, d float64, statusCode int) {
+	mwMetricsMu.Lock()
+	h, ok := mwMetrics[mwName]
+	if !ok {
+		h = newMetricsHistogram()
+		mwMetrics[mwName] = h
+	}
+	mwMetricsMu.Unlock()
+	h.observe(d)
+
+	if statusCode == http.StatusTooManyRequests {
+		rateLimitRejectionsMu.Lock()
+		rateLimitRejections[apiID]++
+		rateLimitRejectionsMu.Unlock()
+	}
+}
+
+func sanitizeMetricLabel(v string) string {
+	v = strings.Replace(v, `\`, `\\`, -1)
+	v = strings.Replace(v, `"`, `\"`, -1)
+	v = strings.Replace(v, "\n", `\n`, -1)
+	return v
+}
+
+func writeHistogram(buf *strings.Builder, name string, labels string, h *metricsHistogram) {
+	cumulative, sum, count := h.cumulativeCounts()
+
+	for i, bound := range metricsHistogramBoundsMs {
+		fmt.Fprintf(buf, "%s_bucket{%sle=\"%g\"} %d\n", name, labels, bound, cumulative[i])
+	}
+	fmt.Fprintf(buf, "%s_bucket{%sle=\"+Inf\"} %d\n", name, labels, cumulative[len(cumulative)-1])
+	fmt.Fprintf(buf, "%s_sum{%s} %g\n", name, strings.TrimSuffix(labels, ","), sum)
+	fmt.Fprintf(buf, "%s_count{%s} %d\n", name, strings.TrimSuffix(labels, ","), count)
+}
+
+// metricsHandler renders every tracked metric in the Prometheus text
+// exposition format. It's registered on the control API's port by default,
+// or on config.Global().MetricsPort when that's set to a different port.
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	var buf strings.Builder
+
+	buf.WriteString("# HELP tyk_http_requests_total Total number of requests processed, by API and endpoint.\n")
+	buf.WriteString("# TYPE tyk_http_requests_total counter\n")
+	buf.WriteString("# HELP tyk_http_request_duration_ms Request latency in milliseconds, by API and endpoint.\n")
+	buf.WriteString("# TYPE tyk_http_request_duration_ms histogram\n")
+
+	requestMetricsMu.Lock()
+	keys := make([]requestMetricKey, 0, len(requestMetrics))
+	entries := make([]*requestMetricEntry, 0, len(requestMetrics))
+	for k, e := range requestMetrics {
+		keys = append(keys, k)
+		entries = append(entries, e)
+	}
+	requestMetricsMu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].apiID != keys[j].apiID {
+			return keys[i].apiID < keys[j].apiID
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].path < keys[j].path
+	})
+	byKey := make(map[requestMetricKey]*requestMetricEntry, len(keys))
+	for i, k := range keys {
+		byKey[k] = entries[i]
+	}
+
+	for _, k := range keys {
+		entry := byKey[k]
+		labels := fmt.Sprintf(`api_id="%s",api_name="%s",method="%s",path="%s",`,
+			sanitizeMetricLabel(k.apiID), sanitizeMetricLabel(k.name), sanitizeMetricLabel(k.method), sanitizeMetricLabel(k.path))
+
+		writeHistogram(&buf, "tyk_http_request_duration_ms", labels, entry.latency)
+
+		statusCounts := entry.snapshotStatus()
+		codes := make([]int, 0, len(statusCounts))
+		for code := range statusCounts {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(&buf, "tyk_http_requests_total{%sstatus=\"%s\"} %d\n", labels, strconv.Itoa(code), statusCounts[code])
+		}
+	}
+
+	buf.WriteString("# HELP tyk_middleware_duration_ms Middleware execution time in milliseconds, by middleware name.\n")
+	buf.WriteString("# TYPE tyk_middleware_duration_ms histogram\n")
+
+	mwMetricsMu.Lock()
+	mwNames := make([]string, 0, len(mwMetrics))
+	mwHistograms := make(map[string]*metricsHistogram, len(mwMetrics))
+	for name, h := range mwMetrics {
+		mwNames = append(mwNames, name)
+		mwHistograms[name] = h
+	}
+	mwMetricsMu.Unlock()
+	sort.Strings(mwNames)
+
+	for _, name := range mwNames {
+		labels := fmt.Sprintf(`middleware="%s",`, sanitizeMetricLabel(name))
+		writeHistogram(&buf, "tyk_middleware_duration_ms", labels, mwHistograms[name])
+	}
+
+	buf.WriteString("# HELP tyk_rate_limit_rejections_total Total number of requests rejected by rate limiting, by API.\n")
+	buf.WriteString("# TYPE tyk_rate_limit_rejections_total counter\n")
+
+	rateLimitRejectionsMu.Lock()
+	apiIDs := make([]string, 0, len(rateLimitRejections))
+	rejections := make(map[string]uint64, len(rateLimitRejections))
+	for apiID, n := range rateLimitRejections {
+		apiIDs = append(apiIDs, apiID)
+		rejections[apiID] = n
+	}
+	rateLimitRejectionsMu.Unlock()
+	sort.Strings(apiIDs)
+
+	for _, apiID := range apiIDs {
+		fmt.Fprintf(&buf, "tyk_rate_limit_rejections_total{api_id=\"%s\"} %d\n", sanitizeMetricLabel(apiID), rejections[apiID])
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(buf.String()))
+}
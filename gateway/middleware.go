@@ -20,6 +20,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/goplugin"
 	"github.com/TykTechnologies/tyk/request"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/trace"
@@ -133,6 +134,8 @@ func createMiddleware(actualMW TykMiddleware) func(http.Handler) http.Handler {
 				meta["error"] = err.Error()
 
 				finishTime := time.Since(startTime)
+				ctxAppendMWTiming(r, mw.Name(), finishTime)
+				recordMiddlewareExecMetric(mw.Base().Spec.APIID, mw.Name(), float64(finishTime.Nanoseconds())/1e6, errCode)
 
 				if instrumentationEnabled {
 					job.TimingKv("exec_time", finishTime.Nanoseconds(), meta)
@@ -144,6 +147,8 @@ func createMiddleware(actualMW TykMiddleware) func(http.Handler) http.Handler {
 			}
 
 			finishTime := time.Since(startTime)
+			ctxAppendMWTiming(r, mw.Name(), finishTime)
+			recordMiddlewareExecMetric(mw.Base().Spec.APIID, mw.Name(), float64(finishTime.Nanoseconds())/1e6, errCode)
 
 			if instrumentationEnabled {
 				job.TimingKv("exec_time", finishTime.Nanoseconds(), meta)
@@ -165,6 +170,9 @@ func createMiddleware(actualMW TykMiddleware) func(http.Handler) http.Handler {
 }
 
 func mwAppendEnabled(chain *[]alice.Constructor, mw TykMiddleware) bool {
+	if base := mw.Base(); base.Spec != nil && middlewareDisabledByOverride(base.Spec, mw.Name()) {
+		return false
+	}
 	if mw.EnabledForSpec() {
 		*chain = append(*chain, createMiddleware(mw))
 		return true
@@ -172,6 +180,24 @@ func mwAppendEnabled(chain *[]alice.Constructor, mw TykMiddleware) bool {
 	return false
 }
 
+// mwAppendGoPlugin registers a Go-plugin hook, picking the typed v2 contract
+// (GoPluginMiddlewareV2) when the hook definition asks for it and falling
+// back to the legacy raw net/http contract (GoPluginMiddleware) otherwise.
+func mwAppendGoPlugin(chain *[]alice.Constructor, baseMid BaseMiddleware, obj apidef.MiddlewareDefinition) bool {
+	if obj.APIVersion == goplugin.APIVersionV2 {
+		return mwAppendEnabled(chain, &GoPluginMiddlewareV2{
+			BaseMiddleware: baseMid,
+			Path:           obj.Path,
+			SymbolName:     obj.Name,
+		})
+	}
+	return mwAppendEnabled(chain, &GoPluginMiddleware{
+		BaseMiddleware: baseMid,
+		Path:           obj.Path,
+		SymbolName:     obj.Name,
+	})
+}
+
 func mwList(mws ...TykMiddleware) []alice.Constructor {
 	var list []alice.Constructor
 	for _, mw := range mws {
@@ -195,6 +221,10 @@ func (t BaseMiddleware) Logger() (logger *logrus.Entry) {
 		t.logger = logrus.NewEntry(log)
 	}
 
+	if t.Spec != nil && t.Spec.overrides.LogLevel != "" {
+		return t.logger.WithField("log_level_override", t.Spec.overrides.LogLevel)
+	}
+
 	return t.logger
 }
 
@@ -384,6 +414,20 @@ func (t BaseMiddleware) ApplyPolicies(session *user.SessionState) error {
 						}
 						r.AllowedURLs = append(r.AllowedURLs, v.AllowedURLs...)
 
+						for _, u := range v.DeniedURLs {
+							found := false
+							for di, du := range r.DeniedURLs {
+								if u.URL == du.URL {
+									found = true
+									rights[k].DeniedURLs[di].Methods = append(r.DeniedURLs[di].Methods, u.Methods...)
+								}
+							}
+
+							if !found {
+								r.DeniedURLs = append(r.DeniedURLs, u)
+							}
+						}
+
 						ar = &r
 					}
 
@@ -457,6 +501,19 @@ func (t BaseMiddleware) ApplyPolicies(session *user.SessionState) error {
 		} else if policy.IsInactive {
 			session.IsInactive = true
 		}
+
+		if policy.IdleTimeout > 0 {
+			session.IdleTimeout = policy.IdleTimeout
+			session.MaxLifetime = policy.MaxLifetime
+		}
+
+		// Highest priority across all applied policies wins, so stacking a
+		// low-priority base policy with a high-priority override policy
+		// protects the session from load shedding.
+		if policy.Priority > session.Priority {
+			session.Priority = policy.Priority
+		}
+
 		for _, tag := range policy.Tags {
 			tags[tag] = true
 		}
@@ -630,10 +687,20 @@ func responseProcessorByName(name string) TykResponseHandler {
 		return &ResponseTransformMiddleware{}
 	case "response_body_transform_jq":
 		return &ResponseTransformJQMiddleware{}
+	case "response_body_transform_jsonata":
+		return &ResponseTransformJSONataMiddleware{}
+	case "response_body_transform_chain":
+		return &ResponseTransformChainMiddleware{}
+	case "content_type_conversion":
+		return &ResponseTransformContentTypeMiddleware{}
 	case "header_transform":
 		return &HeaderTransform{}
 	case "custom_mw_res_hook":
 		return &CustomMiddlewareResponseHook{}
+	case "goplugin_response_hook":
+		return &GoPluginResponseHook{}
+	case "exec_filter":
+		return &ExecFilter{}
 	}
 	return nil
 }
@@ -643,7 +710,7 @@ func handleResponseChain(chain []TykResponseHandler, rw http.ResponseWriter, res
 	for _, rh := range chain {
 		if err := handleResponse(rh, rw, res, req, ses, traceIsEnabled); err != nil {
 			// Abort the request if this handler is a response middleware hook:
-			if rh.Name() == "CustomMiddlewareResponseHook" {
+			if rh.Name() == "CustomMiddlewareResponseHook" || rh.Name() == "GoPluginResponseHook" {
 				rh.HandleError(rw, req)
 				return true, err
 			}
@@ -3,6 +3,7 @@ package gateway
 import (
 	"errors"
 	"net/http"
+	"strings"
 )
 
 // AccessRightsCheck is a middleware that will check if the key bing used to access the API has
@@ -54,7 +55,32 @@ func (a *AccessRightsCheck) ProcessRequest(w http.ResponseWriter, r *http.Reques
 			a.Logger().Info("Attempted access to unauthorised API version.")
 			return errors.New("Access to this API has been disallowed"), http.StatusForbidden
 		}
+
+		if a.Spec.Protocol == "grpc" && len(versionList.AllowedGRPCMethods) > 0 {
+			if !grpcMethodAllowed(versionList.AllowedGRPCMethods, r.URL.Path) {
+				a.Logger().Info("Attempted access to unauthorised gRPC method.")
+				return errors.New("Access to this API has been disallowed"), http.StatusForbidden
+			}
+		}
 	}
 
 	return nil, 200
 }
+
+// grpcMethodAllowed reports whether path (the "package.Service/Method"
+// value gRPC sends as the HTTP/2 ":path", surfaced by net/http as
+// r.URL.Path) matches one of allowed. An entry ending in "/*" whitelists
+// every method on that service.
+func grpcMethodAllowed(allowed []string, path string) bool {
+	path = strings.TrimPrefix(path, "/")
+	for _, a := range allowed {
+		a = strings.TrimPrefix(a, "/")
+		if a == path {
+			return true
+		}
+		if service := strings.TrimSuffix(a, "*"); service != a && strings.HasPrefix(path, service) {
+			return true
+		}
+	}
+	return false
+}
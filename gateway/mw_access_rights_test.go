@@ -0,0 +1,24 @@
+package gateway
+
+import "testing"
+
+func TestGrpcMethodAllowed(t *testing.T) {
+	allowed := []string{"pkg.Service/Get", "pkg.Other/*"}
+
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{"pkg.Service/Get", true},
+		{"/pkg.Service/Get", true},
+		{"pkg.Service/Set", false},
+		{"pkg.Other/AnyMethod", true},
+		{"pkg.Unrelated/Get", false},
+	}
+
+	for _, c := range cases {
+		if got := grpcMethodAllowed(allowed, c.path); got != c.want {
+			t.Errorf("grpcMethodAllowed(%q) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
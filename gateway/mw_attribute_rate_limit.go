@@ -0,0 +1,121 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// RateLimitByAttribute rate-limits requests by a value pulled from the
+// request itself - a header, a JWT claim, or a JSON body field - instead
+// of by API key or IP, so a single shared service key can still get
+// per-tenant fairness.
+type RateLimitByAttribute struct {
+	BaseMiddleware
+}
+
+func (k *RateLimitByAttribute) Name() string {
+	return "RateLimitByAttribute"
+}
+
+func (k *RateLimitByAttribute) EnabledForSpec() bool {
+	cfg := k.Spec.AttributeRateLimit
+	return cfg.Enabled && cfg.Name != "" && cfg.Rate > 0
+}
+
+// extractAttribute reads the configured header, JWT claim, or JSON body
+// field from r, returning "" if it isn't present.
+func (k *RateLimitByAttribute) extractAttribute(r *http.Request) (string, error) {
+	cfg := k.Spec.AttributeRateLimit
+
+	switch cfg.Source {
+	case "header":
+		return r.Header.Get(cfg.Name), nil
+	case "claim":
+		if cnt := ctxGetData(r); cnt != nil {
+			if v, ok := cnt["jwt_claims_"+cfg.Name]; ok {
+				return fmt.Sprintf("%v", v), nil
+			}
+		}
+		return "", nil
+	case "body":
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return "", err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var parsed map[string]interface{}
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return "", nil
+		}
+
+		if v, ok := parsed[cfg.Name]; ok {
+			return fmt.Sprintf("%v", v), nil
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown attribute rate limit source: %s", cfg.Source)
+	}
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (k *RateLimitByAttribute) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if !ctxCheckLimits(r) {
+		return nil, http.StatusOK
+	}
+
+	cfg := k.Spec.AttributeRateLimit
+
+	attrValue, err := k.extractAttribute(r)
+	if err != nil {
+		k.Logger().WithError(err).Warning("Failed to extract attribute rate limit key")
+		return nil, http.StatusOK
+	}
+	if attrValue == "" {
+		// Nothing to key on for this request, so don't limit it.
+		return nil, http.StatusOK
+	}
+
+	keyName := "attr-ratelimit-" + k.Spec.APIID + "-" + cfg.Source + "-" + attrValue
+	attrSess := &user.SessionState{
+		Rate: cfg.Rate,
+		Per:  cfg.Per,
+	}
+	attrSess.SetKeyHash(storage.HashKey(keyName))
+
+	storeRef := k.Spec.SessionManager.Store()
+	reason := sessionLimiter.ForwardMessage(r, attrSess,
+		keyName,
+		storeRef,
+		true,
+		false,
+		&k.Spec.GlobalConfig,
+		k.Spec.APIID,
+		false,
+	)
+
+	if reason == sessionFailRateLimit {
+		k.Logger().WithField("attribute", cfg.Name).Info("Attribute rate limit exceeded.")
+
+		k.FireEvent(EventRateLimitExceeded, EventKeyFailureMeta{
+			EventMetaDefault: EventMetaDefault{Message: "Attribute Rate Limit Exceeded", OriginatingRequest: EncodeRequestToEvent(r)},
+			Path:             r.URL.Path,
+			Origin:           request.RealIP(r),
+			Key:              keyName,
+		})
+
+		reportHealthValue(k.Spec, Throttle, "-1")
+
+		return errors.New("Rate limit exceeded"), http.StatusTooManyRequests
+	}
+
+	return nil, http.StatusOK
+}
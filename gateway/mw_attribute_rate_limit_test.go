@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func attributeRateLimitSpec(t testing.TB, cfg apidef.AttributeRateLimit) *APISpec {
+	spec := CreateSpecTest(t, `{
+		"api_id": "1",
+		"org_id": "default",
+		"version_data": {
+			"not_versioned": true,
+			"versions": {
+				"v1": {"name": "v1"}
+			}
+		},
+		"proxy": {
+			"listen_path": "/v1",
+			"target_url": "`+TestHttpAny+`"
+		}
+	}`)
+	spec.AttributeRateLimit = cfg
+	return spec
+}
+
+func TestRateLimitByAttribute_ExtractHeader(t *testing.T) {
+	spec := attributeRateLimitSpec(t, apidef.AttributeRateLimit{Enabled: true, Source: "header", Name: "X-Tenant-ID", Rate: 1, Per: 60})
+	k := &RateLimitByAttribute{BaseMiddleware{Spec: spec}}
+
+	req := TestReq(t, "GET", "/v1/", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+
+	v, err := k.extractAttribute(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "acme" {
+		t.Errorf("expected %q, got %q", "acme", v)
+	}
+}
+
+func TestRateLimitByAttribute_ExtractBody(t *testing.T) {
+	spec := attributeRateLimitSpec(t, apidef.AttributeRateLimit{Enabled: true, Source: "body", Name: "tenant_id", Rate: 1, Per: 60})
+	k := &RateLimitByAttribute{BaseMiddleware{Spec: spec}}
+
+	req := TestReq(t, "POST", "/v1/", strings.NewReader(`{"tenant_id": "acme"}`))
+
+	v, err := k.extractAttribute(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "acme" {
+		t.Errorf("expected %q, got %q", "acme", v)
+	}
+
+	// The body must still be readable downstream.
+	body, _ := ioutil.ReadAll(req.Body)
+	if !strings.Contains(string(body), "acme") {
+		t.Errorf("expected body to still be readable after extraction, got %q", body)
+	}
+}
+
+func TestRateLimitByAttribute_ExtractUnknownSource(t *testing.T) {
+	spec := attributeRateLimitSpec(t, apidef.AttributeRateLimit{Enabled: true, Source: "nonsense", Name: "x", Rate: 1, Per: 60})
+	k := &RateLimitByAttribute{BaseMiddleware{Spec: spec}}
+
+	req := TestReq(t, "GET", "/v1/", nil)
+	if _, err := k.extractAttribute(req); err == nil {
+		t.Error("expected an error for an unrecognised attribute source")
+	}
+}
@@ -0,0 +1,98 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// AuthCompositionMiddleware evaluates an API's configured auth mechanisms
+// deterministically according to AuthComposition.Mode, instead of the
+// implicit "first enabled auth middleware wins" ordering the gateway
+// otherwise falls back to when several Use*Auth flags are set at once.
+type AuthCompositionMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *AuthCompositionMiddleware) Name() string {
+	return "AuthCompositionMiddleware"
+}
+
+func (m *AuthCompositionMiddleware) EnabledForSpec() bool {
+	cfg := m.Spec.AuthComposition
+	return cfg.Enabled && len(cfg.Mechanisms) > 0
+}
+
+// authComponent returns the auth middleware responsible for checking mech,
+// or nil if the mechanism isn't recognised. Components are constructed
+// directly rather than through createMiddleware, since composition needs to
+// run several of them inline against the same request instead of chaining
+// http.Handlers - so Init() is called here instead, which every TykMiddleware
+// implementation relies on to build state it can't build in a struct
+// literal (e.g. HMACMiddleware's compiled lowercase-header regexp, or
+// OpenIDMW's provider configuration).
+func (m *AuthCompositionMiddleware) authComponent(mech apidef.AuthTypeEnum) TykMiddleware {
+	var component TykMiddleware
+	switch mech {
+	case apidef.AuthToken:
+		component = &AuthKey{m.BaseMiddleware}
+	case apidef.HMACKey:
+		component = &HMACMiddleware{BaseMiddleware: m.BaseMiddleware}
+	case apidef.BasicAuthUser:
+		component = &BasicAuthKeyIsValid{BaseMiddleware: m.BaseMiddleware}
+	case apidef.JWTClaim:
+		component = &JWTMiddleware{m.BaseMiddleware}
+	case apidef.OIDCUser:
+		component = &OpenIDMW{BaseMiddleware: m.BaseMiddleware}
+	case apidef.OAuthKey:
+		component = &Oauth2KeyExists{m.BaseMiddleware}
+	case apidef.MutualTLS:
+		component = &CertificateCheckMW{BaseMiddleware: m.BaseMiddleware}
+	default:
+		return nil
+	}
+
+	component.Init()
+	component.SetName(component.Name())
+	return component
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *AuthCompositionMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	cfg := m.Spec.AuthComposition
+
+	var lastErr error
+	lastCode := http.StatusUnauthorized
+
+	for _, mech := range cfg.Mechanisms {
+		component := m.authComponent(mech)
+		if component == nil {
+			m.Logger().Warningf("Unknown auth composition mechanism: %s", mech)
+			continue
+		}
+
+		err, code := component.ProcessRequest(w, r, nil)
+		if err == nil {
+			if cfg.Mode == apidef.CompositionOR {
+				return nil, http.StatusOK
+			}
+			continue
+		}
+
+		lastErr, lastCode = err, code
+		if cfg.Mode == apidef.CompositionAND {
+			return err, code
+		}
+	}
+
+	if cfg.Mode == apidef.CompositionOR {
+		if lastErr == nil {
+			lastErr = errors.New("Authorization field missing")
+		}
+		return lastErr, lastCode
+	}
+
+	// CompositionAND: every mechanism passed.
+	return nil, http.StatusOK
+}
@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const authCompositionDef = `{
+	"api_id": "1",
+	"org_id": "default",
+	"auth_composition": {
+		"enabled": true,
+		"mode": "or",
+		"mechanisms": ["hmac_key"]
+	},
+	"auth": {"auth_header_name": "authorization"},
+	"version_data": {
+		"not_versioned": true,
+		"versions": {
+			"v1": {"name": "v1"}
+		}
+	},
+	"proxy": {
+		"listen_path": "/v1",
+		"target_url": "` + TestHttpAny + `"
+	}
+}`
+
+// TestAuthComposition_ComponentsAreInitialised guards against a regression
+// where authComponent constructed HMACMiddleware/OpenIDMW as bare struct
+// literals without calling Init(), which panics on the very first request
+// (HMACMiddleware.hasLowerCaseEscaped dereferences a nil lowercasePattern
+// built by Init()).
+func TestAuthComposition_ComponentsAreInitialised(t *testing.T) {
+	spec := CreateSpecTest(t, authCompositionDef)
+	mw := &AuthCompositionMiddleware{BaseMiddleware{Spec: spec}}
+
+	req := TestReq(t, "GET", "/", nil)
+	req.Header.Set("Authorization", "signature=\"bm90YXJlYWxzaWduYXR1cmU=\"")
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("ProcessRequest panicked, component wasn't Init()'d: %v", r)
+		}
+	}()
+
+	rec := httptest.NewRecorder()
+	err, code := mw.ProcessRequest(rec, req, nil)
+	if err == nil {
+		t.Error("expected an error for a non-matching HMAC signature, got none")
+	}
+	_ = code
+}
+
+func TestAuthComposition_UnknownMechanismSkipped(t *testing.T) {
+	spec := CreateSpecTest(t, authCompositionDef)
+	mw := &AuthCompositionMiddleware{BaseMiddleware{Spec: spec}}
+
+	if mw.authComponent(apidef.AuthTypeEnum("made_up")) != nil {
+		t.Error("expected an unrecognised mechanism to resolve to a nil component")
+	}
+}
@@ -172,8 +172,16 @@ func (k *BasicAuthKeyIsValid) ProcessRequest(w http.ResponseWriter, r *http.Requ
 	keyName := generateToken(k.Spec.OrgID, username)
 	logger := k.Logger().WithField("key", obfuscateKey(keyName))
 	session, keyExists := k.CheckSessionAndIdentityForValidKey(keyName, r)
+	updateSession := false
 	if !keyExists {
-		if config.Global().HashKeyFunction == "" {
+		if extSession, ok := k.trySessionFromExternalStore(username, password, logger); ok {
+			// Password has already been checked against the external
+			// store, so BasicAuthData.Hash is left unset below and the
+			// switch on it is a no-op for this session.
+			session = extSession
+			keyExists = true
+			updateSession = true
+		} else if config.Global().HashKeyFunction == "" {
 			logger.Warning("Attempted access with non-existent user.")
 			return k.handleAuthFail(w, r, token)
 		} else { // check for key with legacy format "org_id" + "user_name"
@@ -204,12 +212,54 @@ func (k *BasicAuthKeyIsValid) ProcessRequest(w http.ResponseWriter, r *http.Requ
 	// Set session state on context, we will need it later
 	switch k.Spec.BaseIdentityProvidedBy {
 	case apidef.BasicAuthUser, apidef.UnsetAuth:
-		ctxSetSession(r, &session, keyName, false)
+		ctxSetSession(r, &session, keyName, updateSession)
 	}
 
 	return nil, http.StatusOK
 }
 
+// trySessionFromExternalStore validates username/password against
+// BasicAuth.ExtAuth's external user store (htpasswd/LDAP/HTTP) and, on
+// success, builds a session for it from ExtAuth.DefaultPolicies. This lets
+// an operator authenticate users that exist only in that external store
+// instead of having to mirror every one of them into a Tyk session.
+func (k *BasicAuthKeyIsValid) trySessionFromExternalStore(username, password string, logger *logrus.Entry) (user.SessionState, bool) {
+	ext := k.Spec.BasicAuth.ExtAuth
+	if !ext.Enabled {
+		return user.SessionState{}, false
+	}
+
+	ok, err := k.validateAgainstExternalStore(username, password)
+	if err != nil {
+		logger.WithError(err).Error("External basic auth validation failed")
+		return user.SessionState{}, false
+	}
+	if !ok {
+		return user.SessionState{}, false
+	}
+
+	if len(ext.DefaultPolicies) == 0 {
+		logger.Error("External basic auth is enabled but has no default_policies configured")
+		return user.SessionState{}, false
+	}
+
+	session, err := generateSessionFromPolicy(ext.DefaultPolicies[0], k.Spec.OrgID, true)
+	if err != nil {
+		logger.WithError(err).Error("Failed to generate session from external basic auth default policy")
+		return user.SessionState{}, false
+	}
+
+	session.SetPolicies(ext.DefaultPolicies...)
+	if err := k.ApplyPolicies(&session); err != nil {
+		logger.WithError(err).Error("Failed to apply external basic auth default policies")
+		return user.SessionState{}, false
+	}
+
+	session.Alias = username
+
+	return session, true
+}
+
 func (k *BasicAuthKeyIsValid) handleAuthFail(w http.ResponseWriter, r *http.Request, token string) (error, int) {
 
 	// Fire Authfailed Event
@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/justinas/alice"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+var (
+	concurrencyLimitMu sync.Mutex
+	concurrencySem     chan struct{}
+	concurrencySemSize int
+	queuedRequests     int32
+)
+
+// concurrencySemaphore returns the process-wide semaphore sized to the
+// currently configured MaxConcurrentRequests, recreating it if the limit
+// has changed since it was last built (e.g. on a config reload).
+func concurrencySemaphore(limit int) chan struct{} {
+	concurrencyLimitMu.Lock()
+	defer concurrencyLimitMu.Unlock()
+
+	if concurrencySem == nil || concurrencySemSize != limit {
+		concurrencySem = make(chan struct{}, limit)
+		concurrencySemSize = limit
+	}
+	return concurrencySem
+}
+
+// handleRequestConcurrencyControl adds a gateway-wide guardrail on the
+// number of requests in flight across every loaded API, so a traffic spike
+// sheds load with fast 503s instead of piling up unbounded goroutines,
+// memory and latency. It's a no-op unless RequestConcurrencyControl is
+// configured, and applies globally rather than per API since the resource
+// being protected - gateway memory and goroutines - is shared.
+func handleRequestConcurrencyControl(chain *[]alice.Constructor) {
+	cfg := config.Global().RequestConcurrencyControl
+	if cfg.MaxConcurrentRequests <= 0 {
+		return
+	}
+
+	*chain = append(*chain, func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sem := concurrencySemaphore(cfg.MaxConcurrentRequests)
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				h.ServeHTTP(w, r)
+				return
+			default:
+			}
+
+			if atomic.AddInt32(&queuedRequests, 1) > int32(cfg.MaxQueuedRequests) {
+				atomic.AddInt32(&queuedRequests, -1)
+				shedOverloadedRequest(w)
+				return
+			}
+			defer atomic.AddInt32(&queuedRequests, -1)
+
+			timeout := time.Duration(cfg.QueueTimeoutMs) * time.Millisecond
+			if timeout <= 0 {
+				timeout = time.Second
+			}
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				h.ServeHTTP(w, r)
+			case <-time.After(timeout):
+				shedOverloadedRequest(w)
+			}
+		})
+	})
+}
+
+// shedOverloadedRequest rejects a request that couldn't get a concurrency
+// slot in time, hinting at a short backoff rather than leaving the client
+// to guess.
+func shedOverloadedRequest(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write([]byte("Gateway is overloaded, please try again shortly"))
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// ContentTypeConversionMiddleware rewrites a request body between XML and
+// JSON on configured paths, so an API can front a legacy XML upstream for
+// consumers that only speak JSON (or the reverse) without a hand-written
+// template.
+type ContentTypeConversionMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *ContentTypeConversionMiddleware) Name() string {
+	return "ContentTypeConversionMiddleware"
+}
+
+func (m *ContentTypeConversionMiddleware) EnabledForSpec() bool {
+	for _, v := range m.Spec.VersionData.Versions {
+		if len(v.ExtendedPaths.ContentTypeConversion) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *ContentTypeConversionMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	_, versionPaths, _, _ := m.Spec.Version(r)
+	found, meta := m.Spec.CheckSpecMatchesStatus(r, versionPaths, ContentTypeConversion)
+	if !found {
+		return nil, http.StatusOK
+	}
+	ctMeta := meta.(*apidef.ContentTypeConversionMeta)
+
+	bodyBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.New("Body read failed"), http.StatusBadRequest
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+
+	converted, err := convertContentType(bodyBytes, ctMeta.From, ctMeta.To, ctMeta.XMLRootTag)
+	if err != nil {
+		m.Logger().WithError(err).Warning("Content type conversion failed")
+		return err, http.StatusBadRequest
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(converted))
+	r.ContentLength = int64(len(converted))
+	r.Header.Set("Content-Length", "")
+
+	return nil, http.StatusOK
+}
+
+// convertContentType translates body between "xml" and "json". It's a
+// no-op if from and to are the same.
+func convertContentType(body []byte, from, to, xmlRootTag string) ([]byte, error) {
+	if from == to {
+		return body, nil
+	}
+
+	switch from {
+	case "xml":
+		if to != "json" {
+			return nil, errors.New("unsupported content type conversion target")
+		}
+		xmlMap, err := mxj.NewMapXml(body)
+		if err != nil {
+			return nil, err
+		}
+		return xmlMap.Json()
+	case "json":
+		if to != "xml" {
+			return nil, errors.New("unsupported content type conversion target")
+		}
+		var parsed interface{}
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return nil, err
+		}
+		jsonMap, ok := parsed.(map[string]interface{})
+		if !ok {
+			jsonMap = map[string]interface{}{"array": parsed}
+		}
+		rootTag := xmlRootTag
+		if rootTag == "" {
+			rootTag = "root"
+		}
+		return mxj.Map(jsonMap).Xml(rootTag)
+	default:
+		return nil, errors.New("unsupported content type conversion source")
+	}
+}
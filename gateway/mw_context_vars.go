@@ -46,6 +46,8 @@ func (m *MiddlewareContextVars) ProcessRequest(w http.ResponseWriter, r *http.Re
 		contextDataObject[name] = c.Value
 	}
 
+	addTracingBaggageToContextData(r, contextDataObject)
+
 	ctxSetData(r, contextDataObject)
 
 	return nil, http.StatusOK
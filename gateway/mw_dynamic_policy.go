@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// DynamicPolicyMiddleware attaches additional policies to the authenticated
+// session for the duration of a single request, based on declarative rules
+// matched against request attributes (header values, path prefix, mTLS
+// client cert OU). This lets a caller pick up contextual privileges without
+// duplicating keys per context.
+type DynamicPolicyMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *DynamicPolicyMiddleware) Name() string {
+	return "DynamicPolicyMiddleware"
+}
+
+func (m *DynamicPolicyMiddleware) EnabledForSpec() bool {
+	return len(m.Spec.DynamicPolicyRules) > 0
+}
+
+func (m *DynamicPolicyMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	session := ctxGetSession(r)
+	if session == nil {
+		return nil, http.StatusOK
+	}
+
+	changed := false
+	for _, rule := range m.Spec.DynamicPolicyRules {
+		if !ruleMatches(r, rule) {
+			continue
+		}
+		for _, polID := range rule.PolicyIDs {
+			if !contains(session.ApplyPolicies, polID) {
+				session.ApplyPolicies = append(session.ApplyPolicies, polID)
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return nil, http.StatusOK
+	}
+
+	if err := m.ApplyPolicies(session); err != nil {
+		m.Logger().WithError(err).Error("Failed to apply dynamic policies")
+		return errors.New("failed to apply dynamic policies"), http.StatusInternalServerError
+	}
+
+	ctxSetSession(r, session, ctxGetAuthToken(r), false)
+
+	return nil, http.StatusOK
+}
+
+// ruleMatches reports whether every condition rule sets is satisfied by r.
+// A rule with no conditions set never matches.
+func ruleMatches(r *http.Request, rule apidef.DynamicPolicyRule) bool {
+	matchedAny := false
+
+	if rule.HeaderName != "" {
+		if r.Header.Get(rule.HeaderName) != rule.HeaderValue {
+			return false
+		}
+		matchedAny = true
+	}
+
+	if rule.PathPrefix != "" {
+		if !strings.HasPrefix(r.URL.Path, rule.PathPrefix) {
+			return false
+		}
+		matchedAny = true
+	}
+
+	if rule.ClientCertOU != "" {
+		if !clientCertHasOU(r, rule.ClientCertOU) {
+			return false
+		}
+		matchedAny = true
+	}
+
+	return matchedAny
+}
+
+func clientCertHasOU(r *http.Request, ou string) bool {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return false
+	}
+	for _, v := range r.TLS.PeerCertificates[0].Subject.OrganizationalUnit {
+		if v == ou {
+			return true
+		}
+	}
+	return false
+}
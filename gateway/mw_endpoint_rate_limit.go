@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const (
+	EndpointRateLimitKeyPrefix = "endpoint-rate-limit-"
+	EndpointQuotaKeyPrefix     = "endpoint-quota-"
+)
+
+// EndpointRateLimitMiddleware enforces the rate and quota limits declared
+// in ExtendedPaths.RateLimit, on top of (not instead of) whatever
+// RateLimitAndQuotaCheck already enforces for the key as a whole. Every
+// limited path/method tracks its own usage under a composite Redis key of
+// keyhash:apiid:path:method, so a key that's blocked on one endpoint can
+// still use the rest of the API.
+type EndpointRateLimitMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *EndpointRateLimitMiddleware) Name() string {
+	return "EndpointRateLimitMiddleware"
+}
+
+func (m *EndpointRateLimitMiddleware) EnabledForSpec() bool {
+	for _, version := range m.Spec.VersionData.Versions {
+		if len(version.ExtendedPaths.RateLimit) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *EndpointRateLimitMiddleware) compositeKey(meta *apidef.EndpointRateLimitMeta, r *http.Request) string {
+	session := ctxGetSession(r)
+	return session.KeyHash() + ":" + m.Spec.APIID + ":" + meta.Path + ":" + meta.Method
+}
+
+func (m *EndpointRateLimitMiddleware) checkRate(meta *apidef.EndpointRateLimitMeta, r *http.Request) bool {
+	if meta.Rate <= 0 {
+		return false
+	}
+
+	store := m.Spec.SessionManager.Store()
+	key := EndpointRateLimitKeyPrefix + m.compositeKey(meta, r)
+	sentinelKey := key + ".BLOCKED"
+
+	pipeline := m.Spec.GlobalConfig.EnableNonTransactionalRateLimiter
+	ratePerPeriodNow, _ := store.SetRollingWindow(key, int64(meta.Per), "-1", pipeline)
+
+	if ratePerPeriodNow > int(meta.Rate)-1 {
+		store.SetRawKey(sentinelKey, "1", int64(meta.Per))
+		return true
+	}
+
+	return false
+}
+
+func (m *EndpointRateLimitMiddleware) checkQuota(meta *apidef.EndpointRateLimitMeta, r *http.Request) bool {
+	if meta.QuotaMax <= 0 {
+		return false
+	}
+
+	store := m.Spec.SessionManager.Store()
+	key := EndpointQuotaKeyPrefix + m.compositeKey(meta, r)
+
+	renewalRate := meta.QuotaRenewalRate
+	if renewalRate <= 0 {
+		renewalRate = 60
+	}
+
+	current := store.IncrememntWithExpire(key, renewalRate)
+	return current > meta.QuotaMax
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *EndpointRateLimitMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	vInfo, versionPaths, _, _ := m.Spec.Version(r)
+	if len(vInfo.ExtendedPaths.RateLimit) == 0 {
+		return nil, http.StatusOK
+	}
+
+	found, rawMeta := m.Spec.CheckSpecMatchesStatus(r, versionPaths, EndpointRateLimit)
+	if !found {
+		return nil, http.StatusOK
+	}
+	meta := rawMeta.(*apidef.EndpointRateLimitMeta)
+
+	if m.checkRate(meta, r) {
+		m.Logger().WithField("path", meta.Path).Info("Endpoint rate limit exceeded.")
+		reportHealthValue(m.Spec, Throttle, "-1")
+		return errors.New("Rate limit exceeded"), http.StatusTooManyRequests
+	}
+
+	if m.checkQuota(meta, r) {
+		m.Logger().WithField("path", meta.Path).Info("Endpoint quota exceeded.")
+		reportHealthValue(m.Spec, QuotaViolation, "-1")
+		return errors.New("Quota exceeded"), http.StatusForbidden
+	}
+
+	return nil, http.StatusOK
+}
@@ -0,0 +1,97 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/ratelimitservice"
+)
+
+var (
+	errRateLimitExceededExternally  = errors.New("Rate limit exceeded")
+	errExternalRateLimitUnavailable = errors.New("External rate limit service unavailable")
+)
+
+// ExternalRateLimitMiddleware delegates the rate-limit decision for the
+// request to an external service speaking the Envoy RLS gRPC protocol,
+// instead of (or alongside) Tyk's own RateLimitAndQuotaCheck, so a limit
+// configured once on that service applies the same way across an Envoy
+// fleet and this gateway.
+type ExternalRateLimitMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *ExternalRateLimitMiddleware) Name() string {
+	return "ExternalRateLimitMiddleware"
+}
+
+func (m *ExternalRateLimitMiddleware) EnabledForSpec() bool {
+	return m.Spec.ExternalRateLimit.Enabled
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *ExternalRateLimitMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	limitConf := m.Spec.ExternalRateLimit
+
+	conn, err := m.dial(limitConf.ServerAddress)
+	if err != nil {
+		m.Logger().WithError(err).Error("Could not connect to external rate limit service")
+		return m.handleUnreachable(limitConf, err)
+	}
+	defer conn.Close()
+
+	client := ratelimitservice.NewRateLimitServiceClient(conn)
+
+	descriptor := &ratelimitservice.RateLimitDescriptor{}
+	for _, d := range limitConf.Descriptors {
+		descriptor.Entries = append(descriptor.Entries, &ratelimitservice.RateLimitDescriptor_Entry{
+			Key:   d.Key,
+			Value: replaceTykVariables(r, d.Value, false),
+		})
+	}
+
+	req := &ratelimitservice.RateLimitRequest{
+		Domain:      limitConf.Domain,
+		Descriptors: []*ratelimitservice.RateLimitDescriptor{descriptor},
+		HitsAddend:  1,
+	}
+
+	timeout := time.Duration(limitConf.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := client.ShouldRateLimit(ctx, req)
+	if err != nil {
+		m.Logger().WithError(err).Error("External rate limit service call failed")
+		return m.handleUnreachable(limitConf, err)
+	}
+
+	if resp.OverallCode == ratelimitservice.RateLimitResponse_OVER_LIMIT {
+		m.Logger().Info("Request rejected by external rate limit service")
+		return errRateLimitExceededExternally, http.StatusTooManyRequests
+	}
+
+	return nil, http.StatusOK
+}
+
+func (m *ExternalRateLimitMiddleware) dial(addr string) (*grpc.ClientConn, error) {
+	return grpc.Dial(addr, grpc.WithInsecure())
+}
+
+// handleUnreachable decides what to do when the external rate limit service
+// can't be reached or errors out: let the request through if FailOpen is
+// set, otherwise fail the request rather than silently skip the limit.
+func (m *ExternalRateLimitMiddleware) handleUnreachable(limitConf apidef.ExternalRateLimit, err error) (error, int) {
+	if limitConf.FailOpen {
+		return nil, http.StatusOK
+	}
+	return errExternalRateLimitUnavailable, http.StatusServiceUnavailable
+}
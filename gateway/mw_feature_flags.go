@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"net/http"
+	"strconv"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const featureFlagHeaderPrefix = "X-Tyk-Feature-"
+
+// FeatureFlagsMiddleware evaluates the API's FeatureFlags per consumer and
+// exposes the result both as upstream headers and as a "feature_flags"
+// context var, so backends and templates alike can trust a single
+// gateway-evaluated value instead of each re-implementing rollout logic.
+type FeatureFlagsMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *FeatureFlagsMiddleware) Name() string {
+	return "FeatureFlagsMiddleware"
+}
+
+func (m *FeatureFlagsMiddleware) EnabledForSpec() bool {
+	return m.Spec.FeatureFlags.Enabled && len(m.Spec.FeatureFlags.Flags) > 0
+}
+
+// featureFlagBucket deterministically maps a key and flag name to a value in
+// [0, 100), so a given key's rollout bucket for a flag stays stable across
+// requests instead of flapping.
+func featureFlagBucket(key, flagName string) int {
+	sum := md5.Sum([]byte(key + ":" + flagName))
+	return int(binary.BigEndian.Uint32(sum[:4]) % 100)
+}
+
+// sessionFlagOverride looks for an explicit override of the named flag in
+// the session's MetaData, under a "feature_flags" map, so operators can pin
+// a flag for a specific key/policy without waiting on a rollout bucket.
+func sessionFlagOverride(session *user.SessionState, name string) (bool, bool) {
+	if session == nil || session.MetaData == nil {
+		return false, false
+	}
+
+	raw, ok := session.MetaData["feature_flags"]
+	if !ok {
+		return false, false
+	}
+
+	overrides, ok := raw.(map[string]interface{})
+	if !ok {
+		return false, false
+	}
+
+	val, ok := overrides[name]
+	if !ok {
+		return false, false
+	}
+
+	enabled, ok := val.(bool)
+	return enabled, ok
+}
+
+func (m *FeatureFlagsMiddleware) evaluate(flag apidef.FeatureFlagMeta, session *user.SessionState, bucketKey string) bool {
+	if enabled, ok := sessionFlagOverride(session, flag.Name); ok {
+		return enabled
+	}
+
+	if flag.RolloutPercentage <= 0 {
+		return flag.DefaultEnabled
+	}
+	if flag.RolloutPercentage >= 100 {
+		return true
+	}
+
+	return featureFlagBucket(bucketKey, flag.Name) < flag.RolloutPercentage
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *FeatureFlagsMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	session := ctxGetSession(r)
+
+	bucketKey := ctxGetAuthToken(r)
+	if bucketKey == "" {
+		bucketKey = request.RealIP(r)
+	}
+
+	flags := make(map[string]interface{}, len(m.Spec.FeatureFlags.Flags))
+	for _, flag := range m.Spec.FeatureFlags.Flags {
+		enabled := m.evaluate(flag, session, bucketKey)
+		flags[flag.Name] = enabled
+		r.Header.Set(featureFlagHeaderPrefix+flag.Name, strconv.FormatBool(enabled))
+	}
+
+	contextData := ctxGetData(r)
+	if contextData == nil {
+		contextData = make(map[string]interface{})
+	}
+	contextData["feature_flags"] = flags
+	ctxSetData(r, contextData)
+
+	return nil, http.StatusOK
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/goplugin"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// GoPluginResponseHook lets a Go plugin registered as a response hook
+// (MiddlewareSection.Response with Driver == apidef.GoPluginDriver) mutate
+// the upstream response before it reaches the client, with full access to
+// the caller's session state. It implements TykResponseHandler the same way
+// CustomMiddlewareResponseHook does for coprocess-driven response hooks, but
+// loads and calls a Go-plugin symbol instead of dispatching to a coprocess.
+type GoPluginResponseHook struct {
+	Spec       *APISpec
+	Path       string
+	SymbolName string
+	handler    goplugin.ResponseHookFunc
+	logger     *logrus.Entry
+}
+
+func (h *GoPluginResponseHook) Init(mwDef interface{}, spec *APISpec) error {
+	mwDefinition := mwDef.(apidef.MiddlewareDefinition)
+	h.Spec = spec
+	h.Path = mwDefinition.Path
+	h.SymbolName = mwDefinition.Name
+	h.logger = log.WithFields(logrus.Fields{
+		"mwPath":       h.Path,
+		"mwSymbolName": h.SymbolName,
+	})
+
+	var err error
+	if h.handler, err = goplugin.GetResponseHandler(h.Path, h.SymbolName); err != nil {
+		h.logger.WithError(err).Error("Could not load Go-plugin response hook")
+		return err
+	}
+
+	return nil
+}
+
+func (h *GoPluginResponseHook) Name() string {
+	return "GoPluginResponseHook"
+}
+
+func (h *GoPluginResponseHook) HandleError(rw http.ResponseWriter, req *http.Request) {
+	handler := ErrorHandler{BaseMiddleware{Spec: h.Spec}}
+	handler.HandleError(rw, req, "Middleware error", http.StatusInternalServerError, true)
+}
+
+func (h *GoPluginResponseHook) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) (err error) {
+	// make sure tyk recovers in case the Go-plugin function panics, same as
+	// the request-phase Go-plugin middleware does
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("%v", e)
+			h.logger.WithError(err).Error("Recovered from panic while running Go-plugin response hook")
+		}
+	}()
+
+	return h.handler(res, req, ses)
+}
@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/goplugin"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// pluginKVStore adapts storage.RedisCluster to goplugin.KVStore, which
+// plugins see as an opaque helper rather than a gateway storage type.
+type pluginKVStore struct {
+	store storage.RedisCluster
+}
+
+func (s *pluginKVStore) Get(key string) (string, error) {
+	return s.store.GetKey(key)
+}
+
+func (s *pluginKVStore) Set(key, value string, ttlSeconds int64) error {
+	return s.store.SetKey(key, value, ttlSeconds)
+}
+
+func (s *pluginKVStore) Delete(key string) error {
+	if !s.store.DeleteKey(key) {
+		return fmt.Errorf("key not found: %s", key)
+	}
+	return nil
+}
+
+// GoPluginMiddlewareV2 runs a Go-plugin built against the typed PluginV2
+// contract, instead of the legacy raw func(http.ResponseWriter, *http.Request)
+// symbol used by GoPluginMiddleware.
+type GoPluginMiddlewareV2 struct {
+	BaseMiddleware
+	Path       string
+	SymbolName string
+	handler    goplugin.PluginV2
+	logger     *logrus.Entry
+	kv         goplugin.KVStore
+}
+
+func (m *GoPluginMiddlewareV2) Name() string {
+	return "GoPluginMiddlewareV2: " + m.Path + ":" + m.SymbolName
+}
+
+func (m *GoPluginMiddlewareV2) EnabledForSpec() bool {
+	m.logger = log.WithFields(logrus.Fields{
+		"mwPath":       m.Path,
+		"mwSymbolName": m.SymbolName,
+	})
+
+	if m.handler != nil {
+		m.logger.Info("Go-plugin v2 middleware is already initialized")
+		return true
+	}
+
+	var err error
+	if m.handler, err = goplugin.GetHandlerV2(m.Path, m.SymbolName); err != nil {
+		m.logger.WithError(err).Error("Could not load Go-plugin v2")
+		return false
+	}
+
+	kvStore := storage.RedisCluster{KeyPrefix: "goplugin-kv-" + m.Spec.APIID}
+	kvStore.Connect()
+	m.kv = &pluginKVStore{store: kvStore}
+
+	return true
+}
+
+func (m *GoPluginMiddlewareV2) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (err error, respCode int) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("%v", e)
+			respCode = http.StatusInternalServerError
+			m.logger.WithError(err).Error("Recovered from panic while running Go-plugin v2 middleware func")
+		}
+	}()
+
+	nopCloseRequestBody(r)
+
+	ctx := &goplugin.PluginContext{
+		Req:     &goplugin.TypedRequest{Request: r},
+		Resp:    &goplugin.TypedResponseWriter{ResponseWriter: w},
+		Session: ctxGetSession(r),
+		Logger:  m.logger,
+		KV:      m.kv,
+	}
+
+	result := m.handler.HandleRequest(ctx)
+	if result.Continue {
+		return nil, http.StatusOK
+	}
+
+	if result.Err != nil {
+		return result.Err, http.StatusInternalServerError
+	}
+
+	// Plugin already wrote its own response via ctx.Resp.
+	return nil, mwStatusRespond
+}
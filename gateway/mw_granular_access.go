@@ -21,11 +21,50 @@ func (m *GranularAccessMiddleware) ProcessRequest(w http.ResponseWriter, r *http
 	logger := m.Logger()
 	session := ctxGetSession(r)
 
+	if m.Spec.AccessRule.Enabled && m.Spec.AccessRule.Rule != "" {
+		ctx := map[string]interface{}{
+			"claims": session.MetaData,
+			"request": map[string]interface{}{
+				"method": r.Method,
+				"path":   r.URL.Path,
+			},
+		}
+
+		allowed, err := evalAccessRule(m.Spec.AccessRule.Rule, ctx)
+		if err != nil {
+			logger.WithError(err).Error("Failed to evaluate access rule")
+			return errors.New("Access to this resource has been disallowed"), http.StatusForbidden
+		}
+		if !allowed {
+			logger.Info("Attempted access denied by access rule (Granular).")
+			return errors.New("Access to this resource has been disallowed"), http.StatusForbidden
+		}
+	}
+
 	sessionVersionData, foundAPI := session.AccessRights[m.Spec.APIID]
 	if !foundAPI {
 		return nil, http.StatusOK
 	}
 
+	for _, accessSpec := range sessionVersionData.DeniedURLs {
+		asRegex, err := regexp.Compile(accessSpec.URL)
+		if err != nil {
+			logger.WithError(err).Error("Regex error")
+			continue
+		}
+
+		if !asRegex.MatchString(r.URL.Path) {
+			continue
+		}
+
+		for _, method := range accessSpec.Methods {
+			if method == r.Method {
+				logger.Info("Attempted access to explicitly denied endpoint (Granular).")
+				return errors.New("Access to this resource has been disallowed"), http.StatusForbidden
+			}
+		}
+	}
+
 	if len(sessionVersionData.AllowedURLs) == 0 {
 		return nil, http.StatusOK
 	}
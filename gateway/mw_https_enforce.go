@@ -0,0 +1,50 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// HTTPSEnforceMiddleware rejects or redirects plain HTTP requests for an
+// API whose listener accepts both schemes, so scheme enforcement doesn't
+// depend on an upstream load balancer being configured correctly.
+type HTTPSEnforceMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *HTTPSEnforceMiddleware) Name() string {
+	return "HTTPSEnforceMiddleware"
+}
+
+func (m *HTTPSEnforceMiddleware) EnabledForSpec() bool {
+	return m.Spec.HTTPS.Enabled
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *HTTPSEnforceMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if r.TLS != nil {
+		maxAge := m.Spec.HTTPS.STSMaxAgeSeconds
+		if maxAge <= 0 {
+			maxAge = 63072000
+		}
+
+		sts := "max-age=" + strconv.FormatInt(maxAge, 10)
+		if m.Spec.HTTPS.STSIncludeSubdomains {
+			sts += "; includeSubDomains"
+		}
+		w.Header().Set("Strict-Transport-Security", sts)
+
+		return nil, http.StatusOK
+	}
+
+	if m.Spec.HTTPS.Redirect {
+		target := "https://" + r.Host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return nil, mwStatusRespond
+	}
+
+	return errHTTPSRequired, http.StatusForbidden
+}
+
+var errHTTPSRequired = errors.New("plain HTTP is not permitted for this API")
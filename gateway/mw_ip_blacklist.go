@@ -18,32 +18,22 @@ func (i *IPBlackListMiddleware) Name() string {
 }
 
 func (i *IPBlackListMiddleware) EnabledForSpec() bool {
-	return i.Spec.EnableIpBlacklisting && len(i.Spec.BlacklistedIPs) > 0
+	return i.Spec.EnableIpBlacklisting && (len(i.Spec.BlacklistedIPs) > 0 || i.Spec.IPBlacklistFeed.Enabled)
 }
 
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
 func (i *IPBlackListMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
 	remoteIP := net.ParseIP(request.RealIP(r))
 
-	// Enabled, check incoming IP address
-	for _, ip := range i.Spec.BlacklistedIPs {
-		// Might be CIDR, try this one first then fallback to IP parsing later
-		blockedIP, blockedNet, err := net.ParseCIDR(ip)
-		if err != nil {
-			blockedIP = net.ParseIP(ip)
-		}
-
-		// Check CIDR if possible
-		if blockedNet != nil && blockedNet.Contains(remoteIP) {
-
-			return i.handleError(r, remoteIP.String())
-		}
-
-		// We parse the IP to manage IPv4 and IPv6 easily
-		if blockedIP.Equal(remoteIP) {
+	// Enabled, check incoming IP address against a mixed IPv4/IPv6 list of
+	// plain IPs and CIDR ranges, combining the statically configured list
+	// with whatever IPBlacklistFeed last fetched successfully.
+	if ipInList(remoteIP, i.Spec.BlacklistedIPs) {
+		return i.handleError(r, remoteIP.String())
+	}
 
-			return i.handleError(r, remoteIP.String())
-		}
+	if i.Spec.IPBlacklistFeed.Enabled && ipInList(remoteIP, ipBlacklistFeedEntries(i.Spec.APIID)) {
+		return i.handleError(r, remoteIP.String())
 	}
 
 	return nil, http.StatusOK
@@ -25,25 +25,11 @@ func (i *IPWhiteListMiddleware) EnabledForSpec() bool {
 func (i *IPWhiteListMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
 	remoteIP := net.ParseIP(request.RealIP(r))
 
-	// Enabled, check incoming IP address
-	for _, ip := range i.Spec.AllowedIPs {
-		// Might be CIDR, try this one first then fallback to IP parsing later
-		allowedIP, allowedNet, err := net.ParseCIDR(ip)
-		if err != nil {
-			allowedIP = net.ParseIP(ip)
-		}
-
-		// Check CIDR if possible
-		if allowedNet != nil && allowedNet.Contains(remoteIP) {
-			// matched, pass through
-			return nil, http.StatusOK
-		}
-
-		// We parse the IP to manage IPv4 and IPv6 easily
-		if allowedIP.Equal(remoteIP) {
-			// matched, pass through
-			return nil, http.StatusOK
-		}
+	// Enabled, check incoming IP address against a mixed IPv4/IPv6 list of
+	// plain IPs and CIDR ranges
+	if ipInList(remoteIP, i.Spec.AllowedIPs) {
+		// matched, pass through
+		return nil, http.StatusOK
 	}
 
 	// Fire Authfailed Event
@@ -260,6 +260,21 @@ func getScopeFromClaim(claims jwt.MapClaims, scopeClaimName string) []string {
 	return nil
 }
 
+// defaultJWTPolicies returns the default policies to apply when a token has
+// no policy claim, preferring a list scoped to the token's issuer ("iss"
+// claim) over the API-wide default.
+func (k *JWTMiddleware) defaultJWTPolicies(claims jwt.MapClaims) []string {
+	if len(k.Spec.JWTDefaultPoliciesByIssuer) > 0 {
+		if iss, ok := claims["iss"].(string); ok && iss != "" {
+			if pols, ok := k.Spec.JWTDefaultPoliciesByIssuer[iss]; ok && len(pols) > 0 {
+				return pols
+			}
+		}
+	}
+
+	return k.Spec.JWTDefaultPolicies
+}
+
 func mapScopeToPolicies(mapping map[string]string, scope []string) []string {
 	polIDs := []string{}
 
@@ -304,13 +319,14 @@ func (k *JWTMiddleware) processCentralisedJWT(r *http.Request, token *jwt.Token)
 
 		// We need a base policy as a template, either get it from the token itself OR a proxy client ID within Tyk
 		basePolicyID, foundPolicy := k.getBasePolicyID(r, claims)
+		defaultPolicies := k.defaultJWTPolicies(claims)
 		if !foundPolicy {
-			if len(k.Spec.JWTDefaultPolicies) == 0 {
+			if len(defaultPolicies) == 0 {
 				k.reportLoginFailure(baseFieldData, r)
 				return errors.New("key not authorized: no matching policy found"), http.StatusForbidden
 			} else {
 				isDefaultPol = true
-				basePolicyID = k.Spec.JWTDefaultPolicies[0]
+				basePolicyID = defaultPolicies[0]
 			}
 		}
 
@@ -320,7 +336,7 @@ func (k *JWTMiddleware) processCentralisedJWT(r *http.Request, token *jwt.Token)
 
 		// If base policy is one of the defaults, apply other ones as well
 		if isDefaultPol {
-			for _, pol := range k.Spec.JWTDefaultPolicies {
+			for _, pol := range defaultPolicies {
 				if !contains(session.ApplyPolicies, pol) {
 					session.ApplyPolicies = append(session.ApplyPolicies, pol)
 				}
@@ -380,13 +396,14 @@ func (k *JWTMiddleware) processCentralisedJWT(r *http.Request, token *jwt.Token)
 	} else {
 		// extract policy ID from JWT token
 		policyID, foundPolicy := k.getBasePolicyID(r, claims)
+		defaultPolicies := k.defaultJWTPolicies(claims)
 		if !foundPolicy {
-			if len(k.Spec.JWTDefaultPolicies) == 0 {
+			if len(defaultPolicies) == 0 {
 				k.reportLoginFailure(baseFieldData, r)
 				return errors.New("key not authorized: no matching policy found"), http.StatusForbidden
 			} else {
 				isDefaultPol = true
-				policyID = k.Spec.JWTDefaultPolicies[0]
+				policyID = defaultPolicies[0]
 			}
 		}
 		// check if we received a valid policy ID in claim
@@ -412,12 +429,12 @@ func (k *JWTMiddleware) processCentralisedJWT(r *http.Request, token *jwt.Token)
 			// check a policy is removed/added from/to default policies
 
 			for _, pol := range session.PolicyIDs() {
-				if !contains(k.Spec.JWTDefaultPolicies, pol) && policyID != pol {
+				if !contains(defaultPolicies, pol) && policyID != pol {
 					defaultPolicyListChanged = true
 				}
 			}
 
-			for _, defPol := range k.Spec.JWTDefaultPolicies {
+			for _, defPol := range defaultPolicies {
 				if !contains(session.PolicyIDs(), defPol) {
 					defaultPolicyListChanged = true
 				}
@@ -435,7 +452,7 @@ func (k *JWTMiddleware) processCentralisedJWT(r *http.Request, token *jwt.Token)
 			session.SetPolicies(policyID)
 
 			if isDefaultPol {
-				for _, pol := range k.Spec.JWTDefaultPolicies {
+				for _, pol := range defaultPolicies {
 					if !contains(session.ApplyPolicies, pol) {
 						session.ApplyPolicies = append(session.ApplyPolicies, pol)
 					}
@@ -501,47 +518,15 @@ func (k *JWTMiddleware) processOneToOneTokenMap(r *http.Request, token *jwt.Toke
 	return nil, http.StatusOK
 }
 
-func (k *JWTMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+// parseJWT verifies rawJWT against the API's configured signing method and
+// secret/key source, factored out of ProcessRequest so other entry points
+// (e.g. the RFC 8693 token exchange handler) that already have a raw JWT in
+// hand can reuse the same verification logic instead of duplicating it.
+func (k *JWTMiddleware) parseJWT(rawJWT string, r *http.Request) (*jwt.Token, error) {
 	logger := k.Logger()
-	config := k.Spec.Auth
-	var tykId string
-
-	// Get the token
-	rawJWT := r.Header.Get(config.AuthHeaderName)
-	if config.UseParam {
-		// Set hte header name
-		rawJWT = r.URL.Query().Get(config.AuthHeaderName)
-	}
-
-	if config.UseCookie {
-		authCookie, err := r.Cookie(config.AuthHeaderName)
-		if err != nil {
-			rawJWT = ""
-		} else {
-			rawJWT = authCookie.Value
-		}
-	}
-
-	if rawJWT == "" {
-		// No header value, fail
-		logger.Info("Attempted access with malformed header, no JWT auth header found.")
-
-		log.Debug("Looked in: ", config.AuthHeaderName)
-		log.Debug("Raw data was: ", rawJWT)
-		log.Debug("Headers are: ", r.Header)
-
-		k.reportLoginFailure(tykId, r)
-		return errors.New("Authorization field missing"), http.StatusBadRequest
-	}
-
-	// enable bearer token format
-	rawJWT = stripBearer(rawJWT)
-
-	// Use own validation logic, see below
 	parser := &jwt.Parser{SkipClaimsValidation: true}
 
-	// Verify the token
-	token, err := parser.Parse(rawJWT, func(token *jwt.Token) (interface{}, error) {
+	return parser.Parse(rawJWT, func(token *jwt.Token) (interface{}, error) {
 		// Don't forget to validate the alg is what you expect:
 		switch k.Spec.JWTSigningMethod {
 		case HMACSign:
@@ -580,6 +565,46 @@ func (k *JWTMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 
 		return val, nil
 	})
+}
+
+func (k *JWTMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	logger := k.Logger()
+	config := k.Spec.Auth
+	var tykId string
+
+	// Get the token
+	rawJWT := r.Header.Get(config.AuthHeaderName)
+	if config.UseParam {
+		// Set hte header name
+		rawJWT = r.URL.Query().Get(config.AuthHeaderName)
+	}
+
+	if config.UseCookie {
+		authCookie, err := r.Cookie(config.AuthHeaderName)
+		if err != nil {
+			rawJWT = ""
+		} else {
+			rawJWT = authCookie.Value
+		}
+	}
+
+	if rawJWT == "" {
+		// No header value, fail
+		logger.Info("Attempted access with malformed header, no JWT auth header found.")
+
+		log.Debug("Looked in: ", config.AuthHeaderName)
+		log.Debug("Raw data was: ", rawJWT)
+		log.Debug("Headers are: ", r.Header)
+
+		k.reportLoginFailure(tykId, r)
+		return errors.New("Authorization field missing"), http.StatusBadRequest
+	}
+
+	// enable bearer token format
+	rawJWT = stripBearer(rawJWT)
+
+	// Verify the token
+	token, err := k.parseJWT(rawJWT, r)
 
 	if err == nil && token.Valid {
 		if jwtErr := k.timeValidateJWTClaims(token.Claims.(jwt.MapClaims)); jwtErr != nil {
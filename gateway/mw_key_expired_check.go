@@ -42,6 +42,11 @@ func (k *KeyExpired) ProcessRequest(w http.ResponseWriter, r *http.Request, _ in
 	}
 
 	if !k.Spec.AuthManager.KeyExpired(session) {
+		if session.SlideExpiry() {
+			if err := k.Spec.SessionManager.UpdateSession(token, session, session.Lifetime(k.Spec.SessionLifetime), false); err != nil {
+				logger.WithError(err).Warning("Failed to persist slid session expiry")
+			}
+		}
 		return nil, http.StatusOK
 	}
 	logger.Info("Attempted access from expired key.")
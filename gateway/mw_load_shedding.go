@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+)
+
+// LoadSheddingMiddleware probabilistically rejects low-priority requests
+// once an API's upstream has signalled it is under load, so a struggling
+// backend degrades gracefully instead of being driven over the edge by
+// undifferentiated traffic.
+type LoadSheddingMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *LoadSheddingMiddleware) Name() string {
+	return "LoadSheddingMiddleware"
+}
+
+func (m *LoadSheddingMiddleware) EnabledForSpec() bool {
+	return m.Spec.LoadShedding.Enabled
+}
+
+func (m *LoadSheddingMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	conf := m.Spec.LoadShedding
+
+	load := currentLoad(m.Spec)
+	if load <= conf.LoadThreshold {
+		return nil, http.StatusOK
+	}
+
+	priority := 0
+	if session := ctxGetSession(r); session != nil {
+		priority = session.Priority
+	}
+	if priority >= conf.MinPriority {
+		return nil, http.StatusOK
+	}
+
+	// Scale shedding probability linearly from 0% at LoadThreshold to 100%
+	// at full load (1.0).
+	headroom := 1 - conf.LoadThreshold
+	if headroom <= 0 {
+		return errors.New("service under load"), http.StatusServiceUnavailable
+	}
+
+	shedProbability := (load - conf.LoadThreshold) / headroom
+	if rand.Float64() < shedProbability {
+		return errors.New("service under load"), http.StatusServiceUnavailable
+	}
+
+	return nil, http.StatusOK
+}
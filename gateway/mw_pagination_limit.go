@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const (
+	defaultPaginationLimitParam  = "limit"
+	defaultPaginationOffsetParam = "offset"
+)
+
+// PaginationLimitMiddleware enforces the pagination guards declared in
+// ExtendedPaths.PaginationLimits, clamping or rejecting limit/offset query
+// parameters that exceed the declared maxima before the request reaches
+// the upstream.
+type PaginationLimitMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *PaginationLimitMiddleware) Name() string {
+	return "PaginationLimitMiddleware"
+}
+
+func (m *PaginationLimitMiddleware) EnabledForSpec() bool {
+	for _, version := range m.Spec.VersionData.Versions {
+		if len(version.ExtendedPaths.PaginationLimits) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkParam validates the named query parameter against max, clamping it
+// in place when rewrite is true. It returns false if the request should be
+// rejected.
+func (m *PaginationLimitMiddleware) checkParam(values url.Values, name string, max int64, rewrite bool) bool {
+	if name == "" || max <= 0 {
+		return true
+	}
+
+	raw := values.Get(name)
+	if raw == "" {
+		return true
+	}
+
+	val, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return true
+	}
+
+	if val <= max {
+		return true
+	}
+
+	if !rewrite {
+		return false
+	}
+
+	values.Set(name, strconv.FormatInt(max, 10))
+	return true
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *PaginationLimitMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	vInfo, versionPaths, _, _ := m.Spec.Version(r)
+	if len(vInfo.ExtendedPaths.PaginationLimits) == 0 {
+		return nil, http.StatusOK
+	}
+
+	found, rawMeta := m.Spec.CheckSpecMatchesStatus(r, versionPaths, PaginationLimit)
+	if !found {
+		return nil, http.StatusOK
+	}
+	meta := rawMeta.(*apidef.PaginationLimitMeta)
+
+	limitParam := meta.LimitParam
+	offsetParam := meta.OffsetParam
+	if limitParam == "" && offsetParam == "" {
+		limitParam = defaultPaginationLimitParam
+		offsetParam = defaultPaginationOffsetParam
+	}
+
+	values := r.URL.Query()
+
+	limitOk := m.checkParam(values, limitParam, meta.MaxLimit, meta.RewriteInsteadOfReject)
+	offsetOk := m.checkParam(values, offsetParam, meta.MaxOffset, meta.RewriteInsteadOfReject)
+
+	if !limitOk || !offsetOk {
+		m.Logger().WithField("path", meta.Path).Info("Pagination limit exceeded.")
+		return errors.New("Pagination parameters exceed the allowed limit"), http.StatusBadRequest
+	}
+
+	r.URL.RawQuery = values.Encode()
+
+	return nil, http.StatusOK
+}
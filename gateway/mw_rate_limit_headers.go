@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+// RateLimitHeaders adds rate limit visibility headers to responses for
+// requests that made it past RateLimitAndQuotaCheck, so well-behaved
+// clients can see how close they are to being throttled and back off
+// before they ever get a 429. It must run after RateLimitAndQuotaCheck in
+// the chain, since that's what resolves the session and per-API limit
+// override this reads.
+//
+// Both the long-established X-RateLimit-* headers and the equivalent
+// draft IETF RateLimit-* headers (draft-ietf-httpapi-ratelimit-headers)
+// are emitted, since clients have settled on both conventions and there
+// isn't yet a single widely-supported standard.
+type RateLimitHeaders struct {
+	BaseMiddleware
+}
+
+func (h *RateLimitHeaders) Name() string {
+	return "RateLimitHeaders"
+}
+
+func (h *RateLimitHeaders) EnabledForSpec() bool {
+	return h.Spec.EnableRateLimitHeaders && !h.Spec.DisableRateLimit
+}
+
+func (h *RateLimitHeaders) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	session := ctxGetSession(r)
+	if session == nil {
+		return nil, http.StatusOK
+	}
+
+	rate := session.Rate
+	per := session.Per
+	if len(session.AccessRights) > 0 {
+		if rights, ok := session.AccessRights[h.Spec.APIID]; ok && rights.Limit != nil {
+			rate = rights.Limit.Rate
+			per = rights.Limit.Per
+		}
+	}
+	if rate <= 0 {
+		return nil, http.StatusOK
+	}
+
+	store := h.Spec.SessionManager.Store()
+	rateLimiterKey := RateLimitKeyPrefix + session.KeyHash()
+	used, _ := store.GetRollingWindow(rateLimiterKey, int64(per), config.Global().EnableNonTransactionalRateLimiter)
+
+	remaining := int64(rate) - int64(used)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	// GetExp returns a TTL (seconds remaining), not an absolute time, so
+	// convert it into the unix timestamp clients expect a "reset" header to
+	// carry.
+	ttl, err := store.GetExp(rateLimiterKey)
+	if err != nil || ttl <= 0 {
+		ttl = int64(per)
+	}
+	reset := time.Now().Unix() + ttl
+
+	limitStr := strconv.FormatInt(int64(rate), 10)
+	remainingStr := strconv.FormatInt(remaining, 10)
+	resetStr := strconv.FormatInt(reset, 10)
+
+	w.Header().Set("X-RateLimit-Limit", limitStr)
+	w.Header().Set("X-RateLimit-Remaining", remainingStr)
+	w.Header().Set("X-RateLimit-Reset", resetStr)
+
+	w.Header().Set("RateLimit-Limit", limitStr)
+	w.Header().Set("RateLimit-Remaining", remainingStr)
+	w.Header().Set("RateLimit-Reset", resetStr)
+
+	return nil, http.StatusOK
+}
@@ -59,6 +59,21 @@ func (k *RateLimitAndQuotaCheck) handleQuotaFailure(r *http.Request, token strin
 	return errors.New("Quota exceeded"), http.StatusForbidden
 }
 
+// handleQuotaOverage tags a request that was let through past its quota
+// under a policy's overage allowance, so overage volume can be billed or
+// alerted on separately rather than looking identical to in-quota traffic.
+func (k *RateLimitAndQuotaCheck) handleQuotaOverage(r *http.Request, token string, overage int64) {
+	k.Logger().WithField("key", obfuscateKey(token)).WithField("overage", overage).Info("Key over quota, allowed under overage allowance.")
+
+	k.FireEvent(EventQuotaOverage, EventQuotaOverageMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Key Quota Overage", OriginatingRequest: EncodeRequestToEvent(r)},
+		Path:             r.URL.Path,
+		Origin:           request.RealIP(r),
+		Key:              token,
+		Overage:          overage,
+	})
+}
+
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
 func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
 	// Skip rate limiting and quotas for looping
@@ -96,6 +111,9 @@ func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.R
 
 	switch reason {
 	case sessionFailNone:
+		if overage := ctxGetQuotaOverage(r); overage > 0 {
+			k.handleQuotaOverage(r, token, overage)
+		}
 	case sessionFailRateLimit:
 		err, errCode := k.handleRateLimitFailure(r, token)
 		if throttleRetryLimit > 0 {
@@ -6,10 +6,14 @@ import (
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -48,11 +52,20 @@ func (m *RedisCacheMiddleware) EnabledForSpec() bool {
 	return m.Spec.CacheOptions.EnableCache
 }
 
-func (m *RedisCacheMiddleware) CreateCheckSum(req *http.Request, keyName string, regex string) (string, error) {
+func (m *RedisCacheMiddleware) CreateCheckSum(req *http.Request, keyName string, regex string, byFields []string, fieldsSource string) (string, error) {
 	h := md5.New()
 	io.WriteString(h, req.Method)
 	io.WriteString(h, "-")
 	io.WriteString(h, req.URL.String())
+
+	if len(byFields) > 0 && fieldsSource == "query" {
+		normalized := normalizedCacheFieldValues(req.URL.Query(), byFields)
+		mm := murmur3.New128()
+		io.WriteString(h, "-")
+		mm.Write(normalized)
+		io.WriteString(h, hex.EncodeToString(mm.Sum(nil)))
+	}
+
 	if req.Method == http.MethodPost {
 		if req.Body != nil {
 			bodyBytes, err := ioutil.ReadAll(req.Body)
@@ -64,12 +77,27 @@ func (m *RedisCacheMiddleware) CreateCheckSum(req *http.Request, keyName string,
 			defer req.Body.Close()
 			req.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
 
-			m := murmur3.New128()
-			if regex == "" {
+			mm := murmur3.New128()
+			switch {
+			case len(byFields) > 0 && fieldsSource != "query":
+				normalized, err := normalizedCacheBodyFields(bodyBytes, byFields)
+				if err == nil {
+					io.WriteString(h, "-")
+					mm.Write(normalized)
+					io.WriteString(h, hex.EncodeToString(mm.Sum(nil)))
+				} else {
+					// Body isn't valid JSON, or the fields couldn't be
+					// extracted - fall back to hashing the raw body so we
+					// still produce a usable (if less shareable) cache key.
+					io.WriteString(h, "-")
+					mm.Write(bodyBytes)
+					io.WriteString(h, hex.EncodeToString(mm.Sum(nil)))
+				}
+			case regex == "":
 				io.WriteString(h, "-")
-				m.Write(bodyBytes)
-				io.WriteString(h, hex.EncodeToString(m.Sum(nil)))
-			} else {
+				mm.Write(bodyBytes)
+				io.WriteString(h, hex.EncodeToString(mm.Sum(nil)))
+			default:
 				r, err := regexp.Compile(regex)
 				if err != nil {
 					return "", err
@@ -77,8 +105,8 @@ func (m *RedisCacheMiddleware) CreateCheckSum(req *http.Request, keyName string,
 				match := r.Find(bodyBytes)
 				if match != nil {
 					io.WriteString(h, "-")
-					m.Write(match)
-					io.WriteString(h, hex.EncodeToString(m.Sum(nil)))
+					mm.Write(match)
+					io.WriteString(h, hex.EncodeToString(mm.Sum(nil)))
 				}
 			}
 		}
@@ -88,6 +116,52 @@ func (m *RedisCacheMiddleware) CreateCheckSum(req *http.Request, keyName string,
 	return m.Spec.APIID + keyName + reqChecksum, nil
 }
 
+// normalizedCacheFieldValues extracts fields from query, sorted by name, and
+// joins them into a stable representation so requests differing only in
+// field order (or in fields not listed) still produce the same bytes.
+func normalizedCacheFieldValues(query url.Values, fields []string) []byte {
+	sorted := append([]string(nil), fields...)
+	sort.Strings(sorted)
+	parts := make([]string, 0, len(sorted))
+	for _, f := range sorted {
+		parts = append(parts, f+"="+query.Get(f))
+	}
+	return []byte(strings.Join(parts, "&"))
+}
+
+// normalizedCacheBodyFields parses body as JSON and extracts fields (dot
+// notation for nested objects), sorted by name, into a stable representation
+// so requests differing only in field order (or in fields not listed) still
+// produce the same bytes.
+func normalizedCacheBodyFields(body []byte, fields []string) ([]byte, error) {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+
+	sorted := append([]string(nil), fields...)
+	sort.Strings(sorted)
+	parts := make([]string, 0, len(sorted))
+	for _, f := range sorted {
+		parts = append(parts, fmt.Sprintf("%s=%v", f, lookupCacheBodyField(parsed, f)))
+	}
+	return []byte(strings.Join(parts, "&")), nil
+}
+
+// lookupCacheBodyField resolves a dot-notation path against parsed JSON,
+// returning nil if any segment is missing or not an object.
+func lookupCacheBodyField(parsed map[string]interface{}, path string) interface{} {
+	var cur interface{} = parsed
+	for _, segment := range strings.Split(path, ".") {
+		asMap, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = asMap[segment]
+	}
+	return cur
+}
+
 func (m *RedisCacheMiddleware) getTimeTTL(cacheTTL int64) string {
 	timeNow := time.Now().Unix()
 	newTTL := timeNow + cacheTTL
@@ -135,6 +209,82 @@ func (m *RedisCacheMiddleware) decodePayload(payload string) (string, string, er
 	return "", "", errors.New("Decoding failed, array length wrong")
 }
 
+// cacheBypassRequested reports whether the client asked to skip the cache via
+// Cache-Control: no-cache or Pragma: no-cache.
+func cacheBypassRequested(r *http.Request) bool {
+	if strings.Contains(strings.ToLower(r.Header.Get(headers.CacheControl)), "no-cache") {
+		return true
+	}
+	return strings.EqualFold(r.Header.Get(headers.Pragma), "no-cache")
+}
+
+// clientCacheBypassAllowed reports whether this request is allowed to force a
+// fresh upstream fetch (and cache refresh) instead of an operator having to
+// purge the Redis cache entry manually. It requires CacheOptions.AllowClientBypass,
+// a no-cache request, and, if BypassCachePolicies is non-empty, a session
+// carrying one of the listed policy IDs.
+func (m *RedisCacheMiddleware) clientCacheBypassAllowed(r *http.Request) bool {
+	if !m.Spec.CacheOptions.AllowClientBypass || !cacheBypassRequested(r) {
+		return false
+	}
+
+	if len(m.Spec.CacheOptions.BypassCachePolicies) == 0 {
+		return true
+	}
+
+	session := ctxGetSession(r)
+	if session == nil {
+		return false
+	}
+
+	for _, id := range session.PolicyIDs() {
+		for _, allowed := range m.Spec.CacheOptions.BypassCachePolicies {
+			if id == allowed {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cacheLRUKey is the sorted set that tracks the insertion order of this API's
+// own cache entries, used by trackAndEvictCacheKey to enforce MaxCachedItems
+// independently of whatever eviction policy the shared Redis cache database
+// applies globally.
+func (m *RedisCacheMiddleware) cacheLRUKey() string {
+	return "cache-lru-" + m.Spec.APIID
+}
+
+// trackAndEvictCacheKey records key as this API's most recently cached entry
+// and, once the API's tracked count exceeds CacheOptions.MaxCachedItems,
+// evicts the oldest entries so one busy API can't grow its footprint in the
+// shared cache database without bound and starve or evict another API's
+// entries out from under it. A MaxCachedItems of 0 leaves the cache
+// unbounded, same as before this tracking existed.
+func (m *RedisCacheMiddleware) trackAndEvictCacheKey(key string) {
+	maxItems := m.Spec.CacheOptions.MaxCachedItems
+	if maxItems <= 0 {
+		return
+	}
+
+	lruKey := m.cacheLRUKey()
+	m.CacheStore.AddToSortedSet(lruKey, key, float64(time.Now().UnixNano()))
+
+	elements, _, err := m.CacheStore.GetSortedSetRange(lruKey, "-inf", "+inf")
+	if err != nil {
+		log.Error("Failed to get cache LRU set range: ", err)
+		return
+	}
+
+	excess := int64(len(elements)) - maxItems
+	for i := int64(0); i < excess; i++ {
+		evictKey := elements[i]
+		m.CacheStore.DeleteKey(evictKey)
+		m.CacheStore.RemoveFromSortedSet(lruKey, evictKey)
+	}
+}
+
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
 func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
 	// Only allow idempotent (safe) methods
@@ -144,6 +294,9 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 
 	var stat RequestStatus
 	var cacheKeyRegex string
+	var perEndpointTTL int64
+	var cacheByFields []string
+	var cacheFieldsSource string
 
 	_, versionPaths, _, _ := m.Spec.Version(r)
 	isVirtual, _ := m.Spec.CheckSpecMatchesStatus(r, versionPaths, VirtualPath)
@@ -159,6 +312,9 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 			cacheMeta := meta.(*EndPointCacheMeta)
 			stat = StatusCached
 			cacheKeyRegex = cacheMeta.CacheKeyRegex
+			perEndpointTTL = cacheMeta.CacheTTL
+			cacheByFields = cacheMeta.CacheByFields
+			cacheFieldsSource = cacheMeta.CacheFieldsSource
 		}
 	}
 
@@ -175,10 +331,13 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 
 	var errCreatingChecksum bool
 	var retBlob string
-	key, err := m.CreateCheckSum(r, token, cacheKeyRegex)
+	key, err := m.CreateCheckSum(r, token, cacheKeyRegex, cacheByFields, cacheFieldsSource)
 	if err != nil {
 		log.Debug("Error creating checksum. Skipping cache check")
 		errCreatingChecksum = true
+	} else if m.clientCacheBypassAllowed(r) {
+		log.Debug("Client requested cache bypass, forcing refresh")
+		err = errors.New("cache bypass requested")
 	} else {
 		retBlob, err = m.CacheStore.GetKey(key)
 		v, sfErr, _ := m.singleFlight.Do(key, func() (interface{}, error) {
@@ -193,6 +352,7 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 			log.Debug("Cache enabled, but record not found")
 		}
 		// Pass through to proxy AND CACHE RESULT
+		ctxSetCacheStatus(r, "MISS")
 
 		var resVal *http.Response
 		if isVirtual {
@@ -208,6 +368,9 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 
 		cacheThisRequest := true
 		cacheTTL := m.Spec.CacheOptions.CacheTimeout
+		if perEndpointTTL > 0 {
+			cacheTTL = perEndpointTTL
+		}
 
 		if resVal == nil {
 			log.Warning("Upstream request must have failed, response is empty")
@@ -263,6 +426,7 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 			ts := m.getTimeTTL(cacheTTL)
 			toStore := m.encodePayload(wireFormatReq.String(), ts)
 			go m.CacheStore.SetKey(key, toStore, cacheTTL)
+			go m.trackAndEvictCacheKey(key)
 		}
 
 		return nil, mwStatusRespond
@@ -304,6 +468,9 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		w.Header().Set(headers.XRateLimitReset, strconv.Itoa(int(quotaRenews)))
 	}
 	w.Header().Set("x-tyk-cached-response", "1")
+	if m.Spec.EnableRequestBudgetHeaders {
+		w.Header().Set(headers.XTykCacheStatus, "HIT")
+	}
 
 	if reqEtag := r.Header.Get("If-None-Match"); reqEtag != "" {
 		if respEtag := newRes.Header.Get("Etag"); respEtag != "" {
@@ -0,0 +1,107 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// shadowRequest mirrors r to Spec.TrafficShadowing.TargetURL and, if
+// diffing is enabled, compares the shadow response against primaryResp.
+// It is fired asynchronously from ServeHTTP so shadow traffic never adds
+// latency to the client-facing request, and its result never reaches the
+// client either.
+func (s *SuccessHandler) shadowRequest(r *http.Request, body []byte, primaryResp *http.Response) {
+	opts := s.Spec.TrafficShadowing
+	if !opts.Enabled || opts.TargetURL == "" || primaryResp == nil {
+		return
+	}
+
+	shadowReq, err := http.NewRequest(r.Method, opts.TargetURL+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		log.WithError(err).Warning("Failed to build shadow traffic request")
+		return
+	}
+	shadowReq.Header = r.Header.Clone()
+
+	shadowResp, err := http.DefaultClient.Do(shadowReq)
+	if err != nil {
+		log.WithError(err).Warning("Shadow traffic request failed")
+		return
+	}
+	defer shadowResp.Body.Close()
+
+	if !opts.Diff.Enabled {
+		return
+	}
+
+	if diff := diffResponses(primaryResp, shadowResp, opts.Diff); len(diff) > 0 {
+		s.Spec.FireEvent(EventResponseDiffMismatch, EventResponseDiffMismatchMeta{
+			EventMetaDefault: EventMetaDefault{
+				Message:            "Shadow response diverged from primary response",
+				OriginatingRequest: EncodeRequestToEvent(r),
+			},
+			Path:       r.URL.Path,
+			StatusDiff: fmt.Sprintf("%d != %d", primaryResp.StatusCode, shadowResp.StatusCode),
+			Diff:       diff,
+		})
+	}
+}
+
+// diffResponses compares status code, the headers listed in opts.CompareHeaders,
+// and (unless opts.IgnoreBody) the normalized JSON body of primary and shadow,
+// returning a human-readable description of each mismatch found.
+func diffResponses(primary, shadow *http.Response, opts apidef.ResponseDiffOptions) []string {
+	var diff []string
+
+	if primary.StatusCode != shadow.StatusCode {
+		diff = append(diff, fmt.Sprintf("status: %d != %d", primary.StatusCode, shadow.StatusCode))
+	}
+
+	for _, h := range opts.CompareHeaders {
+		if pv, sv := primary.Header.Get(h), shadow.Header.Get(h); pv != sv {
+			diff = append(diff, fmt.Sprintf("header %s: %q != %q", h, pv, sv))
+		}
+	}
+
+	if opts.IgnoreBody {
+		return diff
+	}
+
+	primaryBody, err := readAndRestoreBody(primary)
+	if err != nil {
+		return diff
+	}
+	shadowBody, err := readAndRestoreBody(shadow)
+	if err != nil {
+		return diff
+	}
+
+	var primaryJSON, shadowJSON interface{}
+	if json.Unmarshal(primaryBody, &primaryJSON) != nil || json.Unmarshal(shadowBody, &shadowJSON) != nil {
+		if !bytes.Equal(primaryBody, shadowBody) {
+			diff = append(diff, "body: raw content differs")
+		}
+		return diff
+	}
+
+	if !reflect.DeepEqual(primaryJSON, shadowJSON) {
+		diff = append(diff, "body: normalized JSON differs")
+	}
+
+	return diff
+}
+
+func readAndRestoreBody(resp *http.Response) ([]byte, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
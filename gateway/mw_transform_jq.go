@@ -1,3 +1,4 @@
+//go:build jq
 // +build jq
 
 package gateway
@@ -152,6 +153,42 @@ type TransformJQSpec struct {
 	JQFilter *JQ
 }
 
+// compileChainJQStep compiles a ResponseTransformStep's JQFilter for use in a
+// ResponseTransformChain, mirroring how compileTransformJQPathSpec compiles
+// the standalone TransformJQ/TransformJQResponse lists.
+func compileChainJQStep(filter string) (*JQ, error) {
+	return NewJQ(filter)
+}
+
+// runChainJQStep applies a chain step's compiled JQ filter to body, returning
+// the transformed body and any headers the filter asked to have rewritten.
+func runChainJQStep(spec *APISpec, jq *JQ, req *http.Request, body []byte) ([]byte, map[string]string, error) {
+	var bodyObj interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &bodyObj); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	jqObj := map[string]interface{}{
+		"body":         bodyObj,
+		"_tyk_context": ctxGetData(req),
+	}
+
+	ts := &TransformJQSpec{JQFilter: jq}
+	jqResult, err := lockedJQTransform(spec, ts, jqObj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transformed, err := json.Marshal(jqResult.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transformed, jqResult.RewriteHeaders, nil
+}
+
 func (a *APIDefinitionLoader) compileTransformJQPathSpec(paths []apidef.TransformJQMeta, stat URLStatus) []URLSpec {
 	urlSpec := []URLSpec{}
 
@@ -1,8 +1,10 @@
+//go:build !jq
 // +build !jq
 
 package gateway
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/TykTechnologies/tyk/apidef"
@@ -39,3 +41,16 @@ type TransformJQSpec struct {
 func (a *APIDefinitionLoader) compileTransformJQPathSpec(paths []apidef.TransformJQMeta, stat URLStatus) []URLSpec {
 	return []URLSpec{}
 }
+
+// JQ stands in for the cgo-backed jq filter type when this build doesn't
+// have the jq build tag, so a ResponseTransformChain step can still be
+// compiled (and cleanly rejected) instead of failing to build.
+type JQ struct{}
+
+func compileChainJQStep(filter string) (*JQ, error) {
+	return nil, errors.New("JQ transform not supported in this build")
+}
+
+func runChainJQStep(spec *APISpec, jq *JQ, req *http.Request, body []byte) ([]byte, map[string]string, error) {
+	return nil, nil, errors.New("JQ transform not supported in this build")
+}
@@ -0,0 +1,147 @@
+//go:build jsonata
+// +build jsonata
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xiatechs/jsonata-go"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+type TransformJSONataMiddleware struct {
+	BaseMiddleware
+}
+
+func (t *TransformJSONataMiddleware) Name() string {
+	return "TransformJSONataMiddleware"
+}
+
+func (t *TransformJSONataMiddleware) EnabledForSpec() bool {
+	for _, version := range t.Spec.VersionData.Versions {
+		if len(version.ExtendedPaths.TransformJSONata) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (t *TransformJSONataMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	_, versionPaths, _, _ := t.Spec.Version(r)
+	found, meta := t.Spec.CheckSpecMatchesStatus(r, versionPaths, TransformedJSONata)
+	if !found {
+		return nil, http.StatusOK
+	}
+
+	err := t.transformJSONataBody(r, meta.(*TransformJSONataSpec))
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix":      "inbound-transform-jsonata",
+			"server_name": t.Spec.Proxy.TargetURL,
+			"api_id":      t.Spec.APIID,
+			"path":        r.URL.Path,
+		}).Error(err)
+		return err, http.StatusUnsupportedMediaType
+	}
+	return nil, http.StatusOK
+}
+
+func (t *TransformJSONataMiddleware) transformJSONataBody(r *http.Request, ts *TransformJSONataSpec) error {
+	defer r.Body.Close()
+
+	var bodyObj interface{}
+	dec := json.NewDecoder(r.Body)
+	err := dec.Decode(&bodyObj)
+
+	// Do not fail if the body is empty
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	result, err := ts.Expr.Eval(bodyObj)
+	if err != nil {
+		return err
+	}
+
+	transformed, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	bodyBuffer := bytes.NewBuffer(transformed)
+	r.Body = ioutil.NopCloser(bodyBuffer)
+	r.ContentLength = int64(bodyBuffer.Len())
+
+	return nil
+}
+
+type TransformJSONataSpec struct {
+	apidef.TransformJSONataMeta
+	Expr *jsonata.Expr
+}
+
+// chainJSONataExpr is the compiled JSONata expression type stored on a
+// ResponseTransformChain step, aliased so it has the same name whether or
+// not this build has the jsonata build tag.
+type chainJSONataExpr = jsonata.Expr
+
+// compileChainJSONataStep compiles a ResponseTransformStep's JSONata
+// expression for use in a ResponseTransformChain, mirroring
+// compileTransformJSONataPathSpec.
+func compileChainJSONataStep(expression string) (*chainJSONataExpr, error) {
+	return jsonata.Compile(expression)
+}
+
+// runChainJSONataStep evaluates a chain step's compiled JSONata expression
+// against body.
+func runChainJSONataStep(expr *chainJSONataExpr, body []byte) ([]byte, error) {
+	var bodyObj interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &bodyObj); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := expr.Eval(bodyObj)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(result)
+}
+
+func (a *APIDefinitionLoader) compileTransformJSONataPathSpec(paths []apidef.TransformJSONataMeta, stat URLStatus) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	log.Debug("Checking for JSONata transform paths ...")
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+		newTransformSpec := TransformJSONataSpec{TransformJSONataMeta: stringSpec}
+
+		expr, err := jsonata.Compile(stringSpec.Expression)
+		if err != nil {
+			log.Error("JSONata expression load failure! Skipping transformation: ", err)
+			continue
+		}
+		newTransformSpec.Expr = expr
+
+		if stat == TransformedJSONata {
+			newSpec.TransformJSONataAction = newTransformSpec
+		} else {
+			newSpec.TransformJSONataResponseAction = newTransformSpec
+		}
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
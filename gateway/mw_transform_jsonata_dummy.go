@@ -0,0 +1,57 @@
+//go:build !jsonata
+// +build !jsonata
+
+package gateway
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+type TransformJSONataMiddleware struct {
+	BaseMiddleware
+}
+
+func (t *TransformJSONataMiddleware) Name() string {
+	return "TransformJSONataMiddleware"
+}
+
+func (t *TransformJSONataMiddleware) EnabledForSpec() bool {
+	for _, version := range t.Spec.VersionData.Versions {
+		if len(version.ExtendedPaths.TransformJSONata) > 0 {
+			log.Warning("JSONata transform not supported.")
+			return false
+		}
+	}
+
+	return false
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (t *TransformJSONataMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	return nil, http.StatusOK
+}
+
+type TransformJSONataSpec struct {
+	apidef.TransformJSONataMeta
+}
+
+func (a *APIDefinitionLoader) compileTransformJSONataPathSpec(paths []apidef.TransformJSONataMeta, stat URLStatus) []URLSpec {
+	return []URLSpec{}
+}
+
+// chainJSONataExpr stands in for the real compiled JSONata expression type
+// when this build doesn't have the jsonata build tag, so a
+// ResponseTransformChain step can still be compiled (and cleanly rejected)
+// instead of failing to build.
+type chainJSONataExpr struct{}
+
+func compileChainJSONataStep(expression string) (*chainJSONataExpr, error) {
+	return nil, errors.New("JSONata transform not supported in this build")
+}
+
+func runChainJSONataStep(expr *chainJSONataExpr, body []byte) ([]byte, error) {
+	return nil, errors.New("JSONata transform not supported in this build")
+}
@@ -0,0 +1,36 @@
+// +build jsonata
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func testPrepareJSONataMiddleware() {
+	BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.ExtendedPaths.TransformJSONata = []apidef.TransformJSONataMeta{{
+				Path:       "/jsonata",
+				Method:     "POST",
+				Expression: `{"foo": foo, "transformed": true}`,
+			}}
+		})
+	})
+}
+
+func TestJSONataMiddleware(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	testPrepareJSONataMiddleware()
+
+	ts.Run(t, []test.TestCase{
+		{Path: "/jsonata", Method: "POST", Data: `{"foo": "bar"}`, Code: 200, BodyMatch: `"transformed":true`},
+		{Path: "/jsonata", Method: "POST", Data: `wrong json`, Code: 415},
+	}...)
+}
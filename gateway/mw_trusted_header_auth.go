@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	cache "github.com/pmylund/go-cache"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/request"
+)
+
+// TrustedHeaderAuth resolves the caller's identity from a header set by a
+// trusted upstream SSO proxy/load balancer, for intranet deployments where
+// the LB has already authenticated the user and Tyk only needs to map that
+// identity onto a session and policy set. The header is only honoured when
+// the request's source IP falls within one of the configured trusted
+// CIDRs, so it can't be spoofed by a client that reaches the gateway
+// directly.
+type TrustedHeaderAuth struct {
+	BaseMiddleware
+}
+
+func (k *TrustedHeaderAuth) Name() string {
+	return "TrustedHeaderAuth"
+}
+
+func (k *TrustedHeaderAuth) EnabledForSpec() bool {
+	return k.Spec.UseTrustedHeaderAuth
+}
+
+// remoteConnIP returns the IP address Tyk actually accepted the TCP
+// connection from, ignoring any client-supplied X-Real-IP/X-Forwarded-For
+// headers. Unlike request.RealIP, which is meant for logging/rate-limiting
+// and trusts those headers by default, this is used for the trust decision
+// in sourceIsTrusted and must not be spoofable by the client.
+func remoteConnIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (k *TrustedHeaderAuth) sourceIsTrusted(r *http.Request) bool {
+	remoteIP := net.ParseIP(remoteConnIP(r))
+	if remoteIP == nil {
+		return false
+	}
+
+	for _, cidr := range k.Spec.TrustedHeaderAuth.TrustedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			k.Logger().WithError(err).Warning("Invalid trusted header auth CIDR, skipping")
+			continue
+		}
+		if network.Contains(remoteIP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k *TrustedHeaderAuth) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	conf := k.Spec.TrustedHeaderAuth
+
+	if !k.sourceIsTrusted(r) {
+		k.Logger().WithField("ip", request.RealIP(r)).Info("Trusted header auth rejected: source not in a trusted CIDR")
+		return errors.New("Access to this API has been disallowed"), http.StatusForbidden
+	}
+
+	identity := r.Header.Get(conf.HeaderName)
+	if identity == "" {
+		k.Logger().Info("Trusted header auth: identity header missing or empty")
+		return errors.New("Authorization field missing"), http.StatusUnauthorized
+	}
+
+	// Generate a virtual token for this identity so it gets a normal Tyk
+	// session, the same way JWTMiddleware does for centralised JWTs.
+	keyID := fmt.Sprintf("%x", md5.Sum([]byte(identity)))
+	sessionID := generateToken(k.Spec.OrgID, keyID)
+
+	session, exists := k.CheckSessionAndIdentityForValidKey(sessionID, r)
+	updateSession := false
+	if !exists {
+		if len(conf.DefaultPolicies) == 0 {
+			k.Logger().Error("Trusted header auth: no default policies configured")
+			return errors.New("key not authorized: no matching policy found"), http.StatusForbidden
+		}
+
+		var err error
+		session, err = generateSessionFromPolicy(conf.DefaultPolicies[0], k.Spec.OrgID, true)
+		if err != nil {
+			k.Logger().WithError(err).Error("Trusted header auth: could not find a valid policy to apply")
+			return errors.New("key not authorized: no matching policy"), http.StatusForbidden
+		}
+
+		for _, pol := range conf.DefaultPolicies {
+			if !contains(session.ApplyPolicies, pol) {
+				session.ApplyPolicies = append(session.ApplyPolicies, pol)
+			}
+		}
+
+		if err := k.ApplyPolicies(&session); err != nil {
+			return errors.New("failed to create key: " + err.Error()), http.StatusInternalServerError
+		}
+
+		session.Alias = identity
+		updateSession = true
+	}
+
+	switch k.Spec.BaseIdentityProvidedBy {
+	case apidef.TrustedHeader, apidef.UnsetAuth:
+		ctxSetSession(r, &session, sessionID, updateSession)
+	}
+
+	if updateSession {
+		SessionCache.Set(session.KeyHash(), session, cache.DefaultExpiration)
+	}
+
+	return nil, http.StatusOK
+}
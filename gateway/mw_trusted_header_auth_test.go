@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func testPrepareTrustedHeaderAuthMw() *TrustedHeaderAuth {
+	spec := BuildAPI(func(spec *APISpec) {
+		spec.UseTrustedHeaderAuth = true
+		spec.TrustedHeaderAuth = apidef.TrustedHeaderAuthMeta{
+			HeaderName:   "X-Identity",
+			TrustedCIDRs: []string{"10.0.0.0/24"},
+		}
+	})[0]
+
+	mw := &TrustedHeaderAuth{}
+	mw.Spec = spec
+	return mw
+}
+
+func TestTrustedHeaderAuth_sourceIsTrusted(t *testing.T) {
+	mw := testPrepareTrustedHeaderAuthMw()
+
+	cases := []struct {
+		name        string
+		remoteAddr  string
+		forwardedIP string
+		realIP      string
+		wantTrusted bool
+	}{
+		{"remote addr in trusted CIDR", "10.0.0.5:12345", "", "", true},
+		{"remote addr outside trusted CIDR", "203.0.113.9:12345", "", "", false},
+		{
+			name:        "spoofed X-Forwarded-For does not grant trust",
+			remoteAddr:  "203.0.113.9:12345",
+			forwardedIP: "10.0.0.5",
+			wantTrusted: false,
+		},
+		{
+			name:        "spoofed X-Real-IP does not grant trust",
+			remoteAddr:  "203.0.113.9:12345",
+			realIP:      "10.0.0.5",
+			wantTrusted: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := TestReq(t, "GET", "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.forwardedIP != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwardedIP)
+			}
+			if tc.realIP != "" {
+				req.Header.Set("X-Real-IP", tc.realIP)
+			}
+
+			if got := mw.sourceIsTrusted(req); got != tc.wantTrusted {
+				t.Errorf("sourceIsTrusted() = %v, want %v", got, tc.wantTrusted)
+			}
+		})
+	}
+}
+
+func TestTrustedHeaderAuth_ProcessRequest_rejectsSpoofedHeader(t *testing.T) {
+	mw := testPrepareTrustedHeaderAuthMw()
+
+	req := TestReq(t, "GET", "/", nil)
+	req.RemoteAddr = "203.0.113.9:12345"
+	req.Header.Set("X-Forwarded-For", "10.0.0.5")
+	req.Header.Set("X-Identity", "admin")
+
+	_, code := mw.ProcessRequest(nil, req, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("ProcessRequest code = %d, want %d", code, http.StatusForbidden)
+	}
+}
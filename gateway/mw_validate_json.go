@@ -1,10 +1,14 @@
 package gateway
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/TykTechnologies/gojsonschema"
 	"github.com/TykTechnologies/tyk/apidef"
@@ -54,6 +58,23 @@ func (k *ValidateJSON) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 		return err, http.StatusBadRequest
 	}
 	defer r.Body.Close()
+
+	if vPathMeta.CoerceNumericStrings || vPathMeta.CoerceBooleanStrings {
+		var parsed interface{}
+		if err := json.Unmarshal(bodyBytes, &parsed); err != nil {
+			return fmt.Errorf("JSON parsing error: %v", err), http.StatusBadRequest
+		}
+
+		coerced := coerceJSONTypes(parsed, vPathMeta.CoerceNumericStrings, vPathMeta.CoerceBooleanStrings)
+
+		bodyBytes, err = json.Marshal(coerced)
+		if err != nil {
+			return fmt.Errorf("JSON encoding error: %v", err), http.StatusInternalServerError
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+	}
+
 	inputLoader := gojsonschema.NewBytesLoader(bodyBytes)
 
 	// Perform validation
@@ -75,15 +96,66 @@ func (k *ValidateJSON) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 	return nil, http.StatusOK
 }
 
-func (k *ValidateJSON) formatError(schemaErrors []gojsonschema.ResultError) error {
-	errStr := ""
-	for i, desc := range schemaErrors {
-		if i == 0 {
-			errStr = desc.String()
-		} else {
-			errStr = errStr + "; " + desc.String()
+// coerceJSONTypes walks a decoded JSON value, converting string leaves to
+// numbers and/or booleans where requested, so schemas that expect native
+// types still validate against clients that only ever send strings.
+func coerceJSONTypes(v interface{}, numeric, boolean bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range val {
+			val[k] = coerceJSONTypes(sub, numeric, boolean)
+		}
+		return val
+	case []interface{}:
+		for i, sub := range val {
+			val[i] = coerceJSONTypes(sub, numeric, boolean)
+		}
+		return val
+	case string:
+		if boolean {
+			switch strings.ToLower(val) {
+			case "true":
+				return true
+			case "false":
+				return false
+			}
 		}
+		if numeric {
+			if f, err := strconv.ParseFloat(val, 64); err == nil {
+				return f
+			}
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+// jsonValidationError is a single schema validation failure, expressed as
+// a JSON pointer path so a client can map it straight back to the field
+// it submitted instead of parsing a human-readable sentence.
+type jsonValidationError struct {
+	Path     string      `json:"path"`
+	Message  string      `json:"message"`
+	Expected string      `json:"expected,omitempty"`
+	Given    interface{} `json:"given,omitempty"`
+}
+
+func (k *ValidateJSON) formatError(schemaErrors []gojsonschema.ResultError) error {
+	details := make([]jsonValidationError, 0, len(schemaErrors))
+	for _, e := range schemaErrors {
+		details = append(details, jsonValidationError{
+			Path:     "/" + strings.Replace(e.Field(), ".", "/", -1),
+			Message:  e.Description(),
+			Expected: e.Type(),
+			Given:    e.Value(),
+		})
+	}
+
+	asJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode validation errors: %v", err)
 	}
 
-	return errors.New(errStr)
+	return errors.New(string(asJSON))
 }
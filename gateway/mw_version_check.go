@@ -3,6 +3,7 @@ package gateway
 import (
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/TykTechnologies/tyk/apidef"
@@ -40,6 +41,13 @@ func (v *VersionCheck) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 	// Check versioning, blacklist, whitelist and ignored status
 	requestValid, stat, meta := v.Spec.RequestValid(r)
 	if !requestValid {
+		if stat == StatusMethodNotAllowed {
+			if allowed, ok := meta.(*apidef.AllowedMethodsMeta); ok && len(allowed.Methods) > 0 {
+				w.Header().Set("Allow", strings.Join(allowed.Methods, ", "))
+			}
+			return errors.New(string(stat)), http.StatusMethodNotAllowed
+		}
+
 		// Fire a versioning failure event
 		v.FireEvent(EventVersionFailure, EventVersionFailureMeta{
 			EventMetaDefault: EventMetaDefault{
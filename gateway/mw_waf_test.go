@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const wafBlockDef = `{
+	"api_id": "1",
+	"org_id": "default",
+	"waf": {
+		"enabled": true,
+		"mode": "block",
+		"anomaly_threshold": 5
+	},
+	"version_data": {
+		"not_versioned": true,
+		"versions": {
+			"v1": {"name": "v1"}
+		}
+	},
+	"proxy": {
+		"listen_path": "/v1",
+		"target_url": "` + TestHttpAny + `"
+	}
+}`
+
+func TestWAF_AllowsBenignRequest(t *testing.T) {
+	spec := CreateSpecTest(t, wafBlockDef)
+	mw := &WAFMiddleware{BaseMiddleware{Spec: spec}}
+
+	req := TestReq(t, "GET", "/v1/hello?name=world", nil)
+	rec := httptest.NewRecorder()
+	if err, _ := mw.ProcessRequest(rec, req, nil); err != nil {
+		t.Errorf("expected benign request to pass, got error: %v", err)
+	}
+}
+
+func TestWAF_BlocksSQLInjectionInBlockMode(t *testing.T) {
+	spec := CreateSpecTest(t, wafBlockDef)
+	mw := &WAFMiddleware{BaseMiddleware{Spec: spec}}
+
+	req := TestReq(t, "GET", "/v1/users?id=1' UNION SELECT username,password FROM users--", nil)
+	rec := httptest.NewRecorder()
+	err, code := mw.ProcessRequest(rec, req, nil)
+	if err == nil {
+		t.Fatal("expected SQL injection payload to be blocked")
+	}
+	if code != 403 {
+		t.Errorf("expected 403, got %d", code)
+	}
+}
+
+func TestWAF_MonitorModeDoesNotBlock(t *testing.T) {
+	spec := CreateSpecTest(t, strings.Replace(wafBlockDef, `"mode": "block"`, `"mode": "monitor"`, 1))
+	mw := &WAFMiddleware{BaseMiddleware{Spec: spec}}
+
+	req := TestReq(t, "GET", "/v1/users?id=1' UNION SELECT username,password FROM users--", nil)
+	rec := httptest.NewRecorder()
+	if err, _ := mw.ProcessRequest(rec, req, nil); err != nil {
+		t.Errorf("expected monitor mode to only report, not block, got error: %v", err)
+	}
+}
+
+func TestWAF_OnlyBuffersUpToScanCapButPreservesFullBody(t *testing.T) {
+	spec := CreateSpecTest(t, wafBlockDef)
+	mw := &WAFMiddleware{BaseMiddleware{Spec: spec}}
+
+	// A benign body far bigger than maxWAFScanBytes must not be truncated
+	// for whatever runs after WAFMiddleware, even though WAFMiddleware
+	// itself only scans the first maxWAFScanBytes of it.
+	body := strings.Repeat("a", maxWAFScanBytes*2)
+	req := TestReq(t, "POST", "/v1/hello", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	if err, _ := mw.ProcessRequest(rec, req, nil); err != nil {
+		t.Errorf("expected large benign body to pass, got error: %v", err)
+	}
+
+	got, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("failed to read body after WAF middleware: %v", err)
+	}
+	if len(got) != len(body) {
+		t.Errorf("body length after WAF middleware = %d, want %d (body must not be truncated)", len(got), len(body))
+	}
+}
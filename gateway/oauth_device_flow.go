@@ -0,0 +1,247 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	deviceCodePrefix = "oauth-device-code."
+	userCodePrefix   = "oauth-user-code."
+)
+
+// deviceAuthorization is the server-side state for one in-flight device
+// authorization grant (RFC 8628) request.
+type deviceAuthorization struct {
+	DeviceCode   string `json:"device_code"`
+	UserCode     string `json:"user_code"`
+	ClientID     string `json:"client_id"`
+	Scope        string `json:"scope"`
+	ExpiresAt    int64  `json:"expires_at"`
+	IntervalSecs int64  `json:"interval"`
+	Approved     bool   `json:"approved"`
+	Denied       bool   `json:"denied"`
+	SessionData  string `json:"session_data"`
+}
+
+// DeviceFlowHandlers implements the OAuth 2.0 device authorization grant
+// (RFC 8628) endpoints for one API's embedded OAuth provider: device code
+// issuance, end-user verification, and the client's polling token endpoint.
+type DeviceFlowHandlers struct {
+	Manager OAuthManager
+	Store   storage.Handler
+}
+
+func (d *DeviceFlowHandlers) conf() apidef.DeviceFlowConfig {
+	return d.Manager.API.Oauth2Meta.DeviceFlow
+}
+
+func randomToken(byteLen int) string {
+	b := make([]byte, byteLen)
+	rand.Read(b)
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+}
+
+// generateUserCode produces a short, easy-to-type code split into two
+// halves by a dash (e.g. "WDJB-MJHT"), per RFC 8628's UX guidance for the
+// code a user types in at VerificationURI. It avoids visually ambiguous
+// characters.
+func generateUserCode(length int) string {
+	if length <= 0 {
+		length = 8
+	}
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	raw := make([]byte, length)
+	rand.Read(raw)
+	code := make([]byte, length)
+	for i, b := range raw {
+		code[i] = alphabet[int(b)%len(alphabet)]
+	}
+	if length > 4 {
+		mid := length / 2
+		return string(code[:mid]) + "-" + string(code[mid:])
+	}
+	return string(code)
+}
+
+func (d *DeviceFlowHandlers) saveAuthorization(auth deviceAuthorization, ttl int64) error {
+	encoded, err := json.Marshal(auth)
+	if err != nil {
+		return err
+	}
+	return d.Store.SetKey(deviceCodePrefix+auth.DeviceCode, string(encoded), ttl)
+}
+
+// HandleDeviceCodeRequest implements the device authorization endpoint: a
+// client posts its client_id (and optionally scope) and gets back a
+// device_code, user_code, verification_uri and polling interval.
+func (d *DeviceFlowHandlers) HandleDeviceCodeRequest(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Could not parse form"))
+		return
+	}
+
+	clientID := r.Form.Get("client_id")
+	if clientID == "" {
+		doJSONWrite(w, http.StatusBadRequest, apiError("client_id is required"))
+		return
+	}
+
+	if _, err := d.Manager.OsinServer.Storage.GetClient(clientID); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Unknown client_id"))
+		return
+	}
+
+	conf := d.conf()
+	expiry := conf.CodeExpirySeconds
+	if expiry <= 0 {
+		expiry = 600
+	}
+	interval := conf.PollIntervalSeconds
+	if interval <= 0 {
+		interval = 5
+	}
+
+	auth := deviceAuthorization{
+		DeviceCode:   randomToken(32),
+		UserCode:     generateUserCode(conf.UserCodeLength),
+		ClientID:     clientID,
+		Scope:        r.Form.Get("scope"),
+		ExpiresAt:    time.Now().Unix() + expiry,
+		IntervalSecs: interval,
+	}
+
+	if err := d.saveAuthorization(auth, expiry); err != nil {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Could not store device authorization"))
+		return
+	}
+	d.Store.SetKey(userCodePrefix+auth.UserCode, auth.DeviceCode, expiry)
+
+	doJSONWrite(w, http.StatusOK, map[string]interface{}{
+		"device_code":               auth.DeviceCode,
+		"user_code":                 auth.UserCode,
+		"verification_uri":          conf.VerificationURI,
+		"verification_uri_complete": conf.VerificationURI + "?user_code=" + auth.UserCode,
+		"expires_in":                expiry,
+		"interval":                  interval,
+	})
+}
+
+// HandleVerification lets an already-authenticated end user approve or deny
+// the device sitting at VerificationURI, identified by the short user_code
+// it is displaying. session_data is whatever the operator's own login page
+// collected to identify the approving user, mirroring the session argument
+// HandleGenerateAuthCodeData already accepts for the authorization code flow.
+func (d *DeviceFlowHandlers) HandleVerification(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Could not parse form"))
+		return
+	}
+
+	userCode := r.Form.Get("user_code")
+	if userCode == "" {
+		doJSONWrite(w, http.StatusBadRequest, apiError("user_code is required"))
+		return
+	}
+
+	deviceCode, err := d.Store.GetKey(userCodePrefix + userCode)
+	if err != nil {
+		doJSONWrite(w, http.StatusNotFound, apiError("Unknown or expired user_code"))
+		return
+	}
+
+	raw, err := d.Store.GetKey(deviceCodePrefix + deviceCode)
+	if err != nil {
+		doJSONWrite(w, http.StatusNotFound, apiError("Unknown or expired user_code"))
+		return
+	}
+
+	var auth deviceAuthorization
+	if err := json.Unmarshal([]byte(raw), &auth); err != nil {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Could not decode device authorization"))
+		return
+	}
+
+	ttl := auth.ExpiresAt - time.Now().Unix()
+	if ttl <= 0 {
+		doJSONWrite(w, http.StatusNotFound, apiError("user_code has expired"))
+		return
+	}
+
+	if r.Form.Get("deny") == "true" {
+		auth.Denied = true
+	} else {
+		auth.Approved = true
+		auth.SessionData = r.Form.Get("session_data")
+	}
+
+	if err := d.saveAuthorization(auth, ttl); err != nil {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Could not store device authorization"))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, apiOk("device authorized"))
+}
+
+// HandlePollingTokenRequest implements the client's polling side of the
+// device flow: it exchanges device_code for a token once the end user has
+// approved it, returning RFC 8628's authorization_pending/access_denied/
+// expired_token errors while it waits.
+func (d *DeviceFlowHandlers) HandlePollingTokenRequest(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Could not parse form"))
+		return
+	}
+
+	deviceCode := r.Form.Get("device_code")
+	if deviceCode == "" {
+		doJSONWrite(w, http.StatusBadRequest, apiError("device_code is required"))
+		return
+	}
+
+	raw, err := d.Store.GetKey(deviceCodePrefix + deviceCode)
+	if err != nil {
+		doJSONWrite(w, http.StatusBadRequest, map[string]string{"error": "expired_token"})
+		return
+	}
+
+	var auth deviceAuthorization
+	if err := json.Unmarshal([]byte(raw), &auth); err != nil {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Could not decode device authorization"))
+		return
+	}
+
+	if auth.Denied {
+		d.Store.DeleteKey(deviceCodePrefix + deviceCode)
+		doJSONWrite(w, http.StatusBadRequest, map[string]string{"error": "access_denied"})
+		return
+	}
+
+	if !auth.Approved {
+		doJSONWrite(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+		return
+	}
+
+	accessData, err := d.Manager.GrantAccessForDevice(auth.ClientID, auth.Scope, auth.SessionData)
+	if err != nil {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Could not issue access token"))
+		return
+	}
+	d.Store.DeleteKey(deviceCodePrefix + deviceCode)
+
+	resp := map[string]interface{}{
+		"access_token": accessData.AccessToken,
+		"token_type":   "bearer",
+		"expires_in":   accessData.ExpiresIn,
+	}
+	if accessData.RefreshToken != "" {
+		resp["refresh_token"] = accessData.RefreshToken
+	}
+	doJSONWrite(w, http.StatusOK, resp)
+}
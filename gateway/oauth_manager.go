@@ -50,6 +50,13 @@ type OAuthClient struct {
 	MetaData          interface{} `json:"meta_data,omitempty"`
 	PolicyID          string      `json:"policyid"`
 	Description       string      `json:"description"`
+	// PreviousClientSecret holds the secret that was replaced by the most
+	// recent rotation, so callers rolling out the new secret gradually
+	// don't get cut off mid-deploy.
+	PreviousClientSecret string `json:"previous_secret,omitempty"`
+	// PreviousSecretExpires is when PreviousClientSecret stops being
+	// accepted. Zero means no rotation has happened yet.
+	PreviousSecretExpires int64 `json:"previous_secret_expires,omitempty"`
 }
 
 func (oc *OAuthClient) GetId() string {
@@ -60,6 +67,18 @@ func (oc *OAuthClient) GetSecret() string {
 	return oc.ClientSecret
 }
 
+// SecretValid returns true if secret is the client's current secret, or its
+// previous secret within the rotation grace period.
+func (oc *OAuthClient) SecretValid(secret string) bool {
+	if secret == oc.ClientSecret {
+		return true
+	}
+	if oc.PreviousClientSecret == "" || secret != oc.PreviousClientSecret {
+		return false
+	}
+	return time.Now().Unix() < oc.PreviousSecretExpires
+}
+
 func (oc *OAuthClient) GetRedirectUri() string {
 	return oc.ClientRedirectURI
 }
@@ -393,6 +412,42 @@ func (o *OAuthManager) HandleAccess(r *http.Request) *osin.Response {
 	return resp
 }
 
+// GrantAccessForDevice issues an access token for a device that has
+// completed the device authorization grant (RFC 8628): clientID identifies
+// the polling client, scope is whatever it originally requested, and
+// sessionData, when set, is the approving user's session (as produced by the
+// verification step), mirroring how UserData is threaded through the
+// authorization code flow.
+func (o *OAuthManager) GrantAccessForDevice(clientID, scope, sessionData string) (*osin.AccessData, error) {
+	client, err := o.OsinServer.Storage.GetClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessData := &osin.AccessData{
+		Client:    client,
+		Scope:     scope,
+		ExpiresIn: int32(o.OsinServer.Config.AccessExpiration),
+		CreatedAt: time.Now(),
+	}
+	if sessionData != "" {
+		accessData.UserData = sessionData
+	}
+
+	accessToken, refreshToken, err := o.OsinServer.AccessTokenGen.GenerateAccessToken(accessData, true)
+	if err != nil {
+		return nil, err
+	}
+	accessData.AccessToken = accessToken
+	accessData.RefreshToken = refreshToken
+
+	if err := o.OsinServer.Storage.SaveAccess(accessData); err != nil {
+		return nil, err
+	}
+
+	return accessData, nil
+}
+
 // These enums fix the prefix to use when storing various OAuth keys and data, since we
 // delegate everything to the osin framework
 const (
@@ -0,0 +1,240 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+var oasHTTPMethods = []string{"get", "post", "put", "delete", "patch", "head", "options"}
+
+// oasOperation is the subset of an OpenAPI 3.0 Operation Object this
+// importer reads: the request body's JSON schema (for GenerateValidation)
+// and each response's JSON content (for GenerateMocks).
+type oasOperation struct {
+	RequestBody struct {
+		Content map[string]struct {
+			Schema map[string]interface{} `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Example interface{} `json:"example"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+// oasDocument is the subset of an OpenAPI 3.0 Document Object this importer
+// reads. Path items are decoded as raw messages so that non-operation keys
+// (parameters, $ref, summary, ...) don't fail decoding into oasOperation.
+type oasDocument struct {
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// fetchOASDocument reads an OpenAPI document from a local file path or an
+// http(s) URL.
+func fetchOASDocument(source string) ([]byte, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := ioutil.ReadAll(resp.Body)
+			return nil, fmt.Errorf("unexpected status %d fetching OpenAPI document: %s", resp.StatusCode, string(body))
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	return ioutil.ReadFile(source)
+}
+
+// normalizeYAMLValue converts the map[interface{}]interface{} nodes
+// produced by yaml.v2 into map[string]interface{}, so a YAML document can
+// be re-marshaled to JSON and decoded like one, and so any schema pulled
+// out of it is safe to hand to gojsonschema later.
+func normalizeYAMLValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalizeYAMLValue(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeYAMLValue(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// parseOASDocument accepts either JSON or YAML, since OpenAPI documents are
+// commonly authored in either.
+func parseOASDocument(raw []byte) (*oasDocument, error) {
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		if yerr := yaml.Unmarshal(raw, &generic); yerr != nil {
+			return nil, fmt.Errorf("document is not valid JSON or YAML: %v", yerr)
+		}
+		generic = normalizeYAMLValue(generic)
+	}
+
+	normalized, err := json.Marshal(generic)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc oasDocument
+	if err := json.Unmarshal(normalized, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// mockActionFromResponses builds a Reply action from the first 2xx response
+// that declares a JSON example, so GenerateMocks has something concrete to
+// serve.
+func mockActionFromResponses(responses map[string]struct {
+	Content map[string]struct {
+		Example interface{} `json:"example"`
+	} `json:"content"`
+}) (apidef.EndpointMethodMeta, bool) {
+	for _, code := range []string{"200", "201", "202", "204"} {
+		resp, ok := responses[code]
+		if !ok {
+			continue
+		}
+		content, ok := resp.Content["application/json"]
+		if !ok || content.Example == nil {
+			continue
+		}
+		body, err := json.Marshal(content.Example)
+		if err != nil {
+			continue
+		}
+
+		statusCode := 200
+		fmt.Sscanf(code, "%d", &statusCode)
+
+		return apidef.EndpointMethodMeta{
+			Action:  apidef.Reply,
+			Code:    statusCode,
+			Data:    string(body),
+			Headers: map[string]string{"Content-Type": "application/json"},
+		}, true
+	}
+	return apidef.EndpointMethodMeta{}, false
+}
+
+// applyOASImport populates def's target version's ExtendedPaths from the
+// OpenAPI 3.0 document referenced by def.OASImport, so common
+// whitelist/validation/mock setup doesn't have to be hand-maintained
+// alongside a spec that already describes it. This reads only the handful
+// of OAS fields it needs (paths, requestBody/response JSON schemas and
+// examples) by hand rather than through a full OpenAPI parser - no OAS
+// library is vendored in this repository.
+func applyOASImport(def *apidef.APIDefinition, logger *logrus.Entry) {
+	imp := def.OASImport
+	if !imp.Enabled || imp.Source == "" {
+		return
+	}
+
+	raw, err := fetchOASDocument(imp.Source)
+	if err != nil {
+		logger.WithError(err).Error("Failed to fetch OpenAPI document for OAS import")
+		return
+	}
+
+	doc, err := parseOASDocument(raw)
+	if err != nil {
+		logger.WithError(err).Error("Failed to parse OpenAPI document for OAS import")
+		return
+	}
+
+	versionName := imp.TargetVersion
+	if versionName == "" {
+		versionName = def.VersionData.DefaultVersion
+	}
+
+	version, ok := def.VersionData.Versions[versionName]
+	if !ok {
+		logger.Errorf("OAS import target version %q not found in VersionData.Versions", versionName)
+		return
+	}
+
+	pathMetas := map[string]*apidef.EndPointMeta{}
+	pathMeta := func(path string) *apidef.EndPointMeta {
+		if m, ok := pathMetas[path]; ok {
+			return m
+		}
+		m := &apidef.EndPointMeta{Path: path, MethodActions: map[string]apidef.EndpointMethodMeta{}}
+		pathMetas[path] = m
+		return m
+	}
+
+	for path, pathItem := range doc.Paths {
+		for _, method := range oasHTTPMethods {
+			raw, ok := pathItem[method]
+			if !ok {
+				continue
+			}
+
+			var op oasOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				logger.WithError(err).Warnf("Failed to decode OAS operation %s %s, skipping", method, path)
+				continue
+			}
+
+			upperMethod := strings.ToUpper(method)
+			needsWhiteList := imp.GenerateWhiteList
+
+			if imp.GenerateValidation {
+				if content, ok := op.RequestBody.Content["application/json"]; ok && len(content.Schema) > 0 {
+					version.ExtendedPaths.ValidateJSON = append(version.ExtendedPaths.ValidateJSON, apidef.ValidatePathMeta{
+						Path:   path,
+						Method: upperMethod,
+						Schema: content.Schema,
+					})
+				}
+			}
+
+			if imp.GenerateMocks {
+				if action, ok := mockActionFromResponses(op.Responses); ok {
+					pathMeta(path).MethodActions[upperMethod] = action
+					needsWhiteList = true
+				}
+			}
+
+			if needsWhiteList {
+				meta := pathMeta(path)
+				if _, exists := meta.MethodActions[upperMethod]; !exists {
+					meta.MethodActions[upperMethod] = apidef.EndpointMethodMeta{Action: apidef.NoAction}
+				}
+			}
+		}
+	}
+
+	for _, meta := range pathMetas {
+		version.ExtendedPaths.WhiteList = append(version.ExtendedPaths.WhiteList, *meta)
+	}
+
+	if len(pathMetas) > 0 {
+		version.UseExtendedPaths = true
+	}
+
+	def.VersionData.Versions[versionName] = version
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// orgSessionExportEntry is one session in an orgSessionExport payload.
+type orgSessionExportEntry struct {
+	KeyName string            `json:"key_name"`
+	Hashed  bool              `json:"hashed"`
+	Session user.SessionState `json:"session"`
+}
+
+// orgSessionExport is the payload produced by orgSessionExportHandler and
+// consumed by orgSessionImportHandler.
+//
+// swagger:model
+type orgSessionExport struct {
+	OrgID           string                  `json:"org_id"`
+	HashKeyFunction string                  `json:"hash_key_function"`
+	Sessions        []orgSessionExportEntry `json:"sessions"`
+}
+
+// orgSessionExportHandler dumps every session belonging to an org so it can
+// be replayed into another gateway's Redis via orgSessionImportHandler, e.g.
+// when migrating an org between environments.
+//
+// Sessions stored under a hashed key name (config.Global().HashKeys) are
+// exported as-is: hashing is one-way, so there's no original token left to
+// re-hash for the target. Those entries are marked Hashed, and only keep
+// working after import if the target gateway uses the exact same
+// HashKeyFunction - see orgSessionImportHandler.
+func orgSessionExportHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := r.URL.Query().Get("org_id")
+	if orgID == "" {
+		doJSONWrite(w, http.StatusBadRequest, apiError("org_id is required"))
+		return
+	}
+
+	apiID := r.URL.Query().Get("api_id")
+	sessionManager := FallbackKeySesionManager
+	if spec := getApiSpec(apiID); spec != nil {
+		sessionManager = spec.SessionManager
+	}
+
+	hashed := config.Global().HashKeys
+	export := orgSessionExport{OrgID: orgID, HashKeyFunction: config.Global().HashKeyFunction}
+
+	for _, keyName := range sessionManager.Sessions(orgID) {
+		if strings.HasPrefix(keyName, QuotaKeyPrefix) || strings.HasPrefix(keyName, RateLimitKeyPrefix) {
+			continue
+		}
+
+		session, found := sessionManager.SessionDetail(keyName, hashed)
+		if !found {
+			continue
+		}
+
+		export.Sessions = append(export.Sessions, orgSessionExportEntry{
+			KeyName: keyName,
+			Hashed:  hashed,
+			Session: session,
+		})
+	}
+
+	doJSONWrite(w, http.StatusOK, export)
+}
+
+// orgSessionImportStatus reports how an orgSessionImportHandler run went.
+//
+// swagger:model
+type orgSessionImportStatus struct {
+	TotalKeys int `json:"total_keys"`
+	// Imported counts sessions written with a plaintext key name, which
+	// this gateway re-hashed according to its own HashKeys/HashKeyFunction
+	// settings on write, same as any other key.
+	Imported int `json:"imported"`
+	// CarriedOver counts sessions that were already hashed on export and
+	// were written through unchanged, since there was no plaintext token
+	// left to re-hash.
+	CarriedOver int `json:"carried_over"`
+	Errors      int `json:"errors"`
+}
+
+// orgSessionImportHandler writes back an orgSessionExportHandler payload
+// into this gateway's session store. Unhashed sessions are re-hashed
+// according to this gateway's own config.Global().HashKeys /
+// HashKeyFunction settings, the same way any other key write is hashed -
+// this is what makes migrating an org between two gateways with different
+// hashing configurations possible. Sessions that were already hashed on
+// export are written through as-is; see orgSessionExportHandler.
+func orgSessionImportHandler(w http.ResponseWriter, r *http.Request) {
+	var payload orgSessionExport
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Malformed export payload: "+err.Error()))
+		return
+	}
+
+	apiID := r.URL.Query().Get("api_id")
+	sessionManager := FallbackKeySesionManager
+	if spec := getApiSpec(apiID); spec != nil {
+		sessionManager = spec.SessionManager
+	}
+
+	status := orgSessionImportStatus{}
+	for _, entry := range payload.Sessions {
+		status.TotalKeys++
+
+		session := entry.Session
+		if err := sessionManager.UpdateSession(entry.KeyName, &session, 0, entry.Hashed); err != nil {
+			log.WithFields(logrus.Fields{
+				"prefix": "org-session-import",
+				"org_id": payload.OrgID,
+			}).WithError(err).Error("Failed to import session")
+			status.Errors++
+			continue
+		}
+
+		if entry.Hashed {
+			status.CarriedOver++
+		} else {
+			status.Imported++
+		}
+	}
+
+	doJSONWrite(w, http.StatusOK, status)
+}
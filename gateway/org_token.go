@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	uuid "github.com/satori/go.uuid"
+)
+
+// orgAPITokenResponse is returned when a new organisation-scoped control
+// API token is minted.
+//
+// swagger:model
+type orgAPITokenResponse struct {
+	OrgID string `json:"org_id"`
+	Token string `json:"token"`
+}
+
+// orgAPITokenHandler issues and revokes organisation-scoped control API
+// tokens. checkIsAPIOwner accepts either the global admin secret or one of
+// these tokens, tagging a request authenticated with a token via
+// ctxSetControlAPIOrgID so downstream handlers (apiHandler, keyHandler) can
+// restrict it to that org's own APIs and keys.
+//
+// Only the global secret may call this endpoint - an organisation token can
+// never mint or revoke another token, including one for its own org, so a
+// tenant gets self-service use of its existing access without ever being
+// trusted to expand it.
+func orgAPITokenHandler(w http.ResponseWriter, r *http.Request) {
+	if ctxGetControlAPIOrgID(r) != "" {
+		doJSONWrite(w, http.StatusForbidden, apiError("Organisation tokens cannot manage other tokens"))
+		return
+	}
+
+	orgID := mux.Vars(r)["orgID"]
+
+	switch r.Method {
+	case http.MethodPost:
+		token := uuid.NewV4().String()
+		if err := OrgTokenStore.SetKey(token, orgID, 0); err != nil {
+			log.Error("Failed to store organisation API token: ", err)
+			doJSONWrite(w, http.StatusInternalServerError, apiError("Failed to store organisation token"))
+			return
+		}
+		doJSONWrite(w, http.StatusOK, orgAPITokenResponse{OrgID: orgID, Token: token})
+
+	case http.MethodDelete:
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			doJSONWrite(w, http.StatusBadRequest, apiError("token query parameter required"))
+			return
+		}
+		OrgTokenStore.DeleteKey(token)
+		doJSONWrite(w, http.StatusOK, apiOk("organisation token revoked"))
+	}
+}
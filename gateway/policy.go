@@ -160,11 +160,17 @@ func parsePoliciesFromRPC(list string) (map[string]user.Policy, error) {
 
 func LoadPoliciesFromRPC(orgId string) (map[string]user.Policy, error) {
 	if rpc.IsEmergencyMode() {
-		return LoadPoliciesFromRPCBackup()
+		if policies, err := LoadPoliciesFromRPCBackup(); err == nil {
+			return policies, nil
+		}
+		return LoadPoliciesFromRPCFileBackup()
 	}
 
 	store := &RPCStorageHandler{}
 	if !store.Connect() {
+		if policies, err := LoadPoliciesFromRPCFileBackup(); err == nil {
+			return policies, nil
+		}
 		return nil, errors.New("Policies backup: Failed connecting to database")
 	}
 
@@ -182,6 +188,9 @@ func LoadPoliciesFromRPC(orgId string) (map[string]user.Policy, error) {
 	if err := saveRPCPoliciesBackup(rpcPolicies); err != nil {
 		return nil, err
 	}
+	if err := saveRPCPoliciesFileBackup(rpcPolicies); err != nil {
+		log.Warning(err)
+	}
 
 	return policies, nil
 }
@@ -50,6 +50,7 @@ type proxy struct {
 	httpServer       *http.Server
 	tcpProxy         *tcp.Proxy
 	started          bool
+	tlsConfig        *tls.Config
 }
 
 func (p proxy) String() string {
@@ -161,15 +162,25 @@ func (m *proxyMux) addTCPService(spec *APISpec, modifier *tcp.Modifier) {
 	} else {
 		tlsConfig := tlsClientConfig(spec)
 
+		healthCheck := spec.Proxy.TCPHealthCheck
+		var healthCheckInterval, healthCheckTimeout time.Duration
+		if healthCheck.Enabled {
+			healthCheckInterval = time.Duration(healthCheck.IntervalSeconds) * time.Second
+			healthCheckTimeout = time.Duration(healthCheck.TimeoutSeconds) * time.Second
+		}
+
 		p = &proxy{
 			port:             spec.ListenPort,
 			protocol:         spec.Protocol,
 			useProxyProtocol: spec.EnableProxyProtocol,
 			tcpProxy: &tcp.Proxy{
-				DialTLS:         dialWithServiceDiscovery(spec, dialTLSPinnedCheck(spec, tlsConfig)),
-				Dial:            dialWithServiceDiscovery(spec, net.Dial),
-				TLSConfigTarget: tlsConfig,
-				SyncStats:       recordTCPHit(spec.APIID, spec.DoNotTrack),
+				DialTLS:                     dialWithServiceDiscovery(spec, dialTLSPinnedCheck(spec, tlsConfig)),
+				Dial:                        dialWithServiceDiscovery(spec, net.Dial),
+				TLSConfigTarget:             tlsConfig,
+				SyncStats:                   recordTCPHit(spec.APIID, spec.DoNotTrack),
+				UpstreamHealthCheckInterval: healthCheckInterval,
+				UpstreamHealthCheckTimeout:  healthCheckTimeout,
+				CloseClientOnUpstreamDown:   healthCheck.CloseClientOnUpstreamDown,
 			},
 		}
 		p.tcpProxy.AddDomainHandler(hostname, spec.Proxy.TargetURL, modifier)
@@ -273,7 +284,7 @@ func dialWithServiceDiscovery(spec *APISpec, dial dialFn) dialFn {
 			log.Debug("[PROXY] [SERVICE DISCOVERY] received host list ", hostList.All())
 			fallthrough // implies load balancing, with replaced host list
 		case spec.Proxy.EnableLoadBalancing:
-			host, err := nextTarget(hostList, spec)
+			host, err := nextTarget(hostList, spec, nil)
 			if err != nil {
 				log.Error("[PROXY] [LOAD BALANCING] ", err)
 				host = allHostsDownURL
@@ -293,11 +304,18 @@ func dialWithServiceDiscovery(spec *APISpec, dial dialFn) dialFn {
 	}
 }
 
+const defaultHandlerDrainTimeout = 10 * time.Second
+
 func (m *proxyMux) swap(new *proxyMux) {
 	m.Lock()
 	defer m.Unlock()
 	listenAddress := config.Global().ListenAddress
 
+	drainTimeout := time.Duration(config.Global().HandlerDrainTimeout) * time.Second
+	if drainTimeout == 0 {
+		drainTimeout = defaultHandlerDrainTimeout
+	}
+
 	// Shutting down and removing unused listeners/proxies
 	i := 0
 	for _, curP := range m.proxies {
@@ -306,7 +324,12 @@ func (m *proxyMux) swap(new *proxyMux) {
 			mainLog.Infof("Found unused listener at port %d, shutting down", curP.port)
 
 			if curP.httpServer != nil {
-				ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+				// Shutdown waits for in-flight requests on this listener to
+				// finish (rather than cutting them off) for up to
+				// drainTimeout, so a reload that drops or moves an API's
+				// listener doesn't orphan requests that were already being
+				// served on it.
+				ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
 				curP.httpServer.Shutdown(ctx)
 				cancel()
 			} else if curP.listener != nil {
@@ -349,7 +372,7 @@ func (m *proxyMux) swap(new *proxyMux) {
 func (m *proxyMux) serve() {
 	for _, p := range m.proxies {
 		if p.listener == nil {
-			listener, err := m.generateListener(p.port, p.protocol)
+			listener, tlsConfig, err := m.generateListenerWithTLSConfig(p.port, p.protocol)
 			if err != nil {
 				mainLog.WithError(err).Error("Can't start listener")
 				continue
@@ -359,6 +382,7 @@ func (m *proxyMux) serve() {
 			port, _ := strconv.Atoi(portS)
 			p.port = port
 			p.listener = listener
+			p.tlsConfig = tlsConfig
 		}
 		if p.started {
 			continue
@@ -368,7 +392,7 @@ func (m *proxyMux) serve() {
 		case "tcp", "tls":
 			mainLog.Warning("Starting TCP server on:", p.listener.Addr().String())
 			go p.tcpProxy.Serve(p.getListener())
-		case "http", "https":
+		case "http", "https", "grpc":
 			mainLog.Warning("Starting HTTP server on:", p.listener.Addr().String())
 			readTimeout := 120 * time.Second
 			writeTimeout := 120 * time.Second
@@ -382,11 +406,16 @@ func (m *proxyMux) serve() {
 			}
 
 			addr := config.Global().ListenAddress + ":" + strconv.Itoa(p.port)
+			var handler http.Handler = &handleWrapper{p.router}
+			if p.protocol == "https" && p.tlsConfig != nil && config.Global().HttpServerOptions.EnableHttp3 {
+				mainLog.Warning("http_server_options.enable_http3 is set, but no HTTP/3 (QUIC) client is vendored in this build; serving HTTP/1.1 and HTTP/2 only")
+			}
+
 			p.httpServer = &http.Server{
 				Addr:         addr,
 				ReadTimeout:  readTimeout,
 				WriteTimeout: writeTimeout,
-				Handler:      &handleWrapper{p.router},
+				Handler:      handler,
 			}
 
 			if config.Global().CloseConnections {
@@ -416,23 +445,28 @@ func CheckPortWhiteList(w map[string]config.PortWhiteList, listenPort int, proto
 }
 
 func (m *proxyMux) generateListener(listenPort int, protocol string) (l net.Listener, err error) {
+	l, _, err = m.generateListenerWithTLSConfig(listenPort, protocol)
+	return l, err
+}
+
+func (m *proxyMux) generateListenerWithTLSConfig(listenPort int, protocol string) (l net.Listener, tlsConfig *tls.Config, err error) {
 	listenAddress := config.Global().ListenAddress
 	if !config.Global().DisablePortWhiteList {
 		if err := CheckPortWhiteList(config.Global().PortWhiteList, listenPort, protocol); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	targetPort := listenAddress + ":" + strconv.Itoa(listenPort)
 	if ls := m.again.GetListener(targetPort); ls != nil {
-		return ls, nil
+		return ls, nil, nil
 	}
 	switch protocol {
-	case "https", "tls":
+	case "https", "tls", "grpc":
 		mainLog.Infof("--> Using TLS (%s)", protocol)
 		httpServerOptions := config.Global().HttpServerOptions
 
-		tlsConfig := tls.Config{
+		tc := tls.Config{
 			GetCertificate:     dummyGetCertificate,
 			ServerName:         httpServerOptions.ServerName,
 			MinVersion:         httpServerOptions.MinVersion,
@@ -441,21 +475,26 @@ func (m *proxyMux) generateListener(listenPort int, protocol string) (l net.List
 			CipherSuites:       getCipherAliases(httpServerOptions.Ciphers),
 		}
 
-		if httpServerOptions.EnableHttp2 {
-			tlsConfig.NextProtos = append(tlsConfig.NextProtos, http2.NextProtoTLS)
+		// gRPC is always HTTP/2 - unlike a regular HTTPS listener, ALPN h2
+		// isn't optional here, since gRPC can't negotiate down to HTTP/1.1.
+		// There's no cleartext (h2c) support vendored in this tree, so grpc
+		// listeners are TLS-only.
+		if httpServerOptions.EnableHttp2 || protocol == "grpc" {
+			tc.NextProtos = append(tc.NextProtos, http2.NextProtoTLS)
 		}
 
-		tlsConfig.GetConfigForClient = getTLSConfigForClient(&tlsConfig, listenPort)
-		l, err = tls.Listen("tcp", targetPort, &tlsConfig)
+		tc.GetConfigForClient = getTLSConfigForClient(&tc, listenPort)
+		l, err = tls.Listen("tcp", targetPort, &tc)
+		tlsConfig = &tc
 	default:
 		mainLog.WithField("port", targetPort).Infof("--> Standard listener (%s)", protocol)
 		l, err = net.Listen("tcp", targetPort)
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := (&m.again).Listen(targetPort, l); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return l, nil
+	return l, tlsConfig, nil
 }
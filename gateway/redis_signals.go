@@ -32,6 +32,7 @@ const (
 	NoticeGatewayDRLNotification NotificationCommand = "NoticeGatewayDRLNotification"
 	NoticeGatewayLENotification  NotificationCommand = "NoticeGatewayLENotification"
 	KeySpaceUpdateNotification   NotificationCommand = "KeySpaceUpdateNotification"
+	NoticeOrgSessionUpdate       NotificationCommand = "OrgSessionUpdate"
 )
 
 // Notification is a type that encodes a message published to a pub sub channel (shared between implementations)
@@ -103,6 +104,8 @@ func handleRedisEvent(v interface{}, handled func(NotificationCommand), reloaded
 		reloadURLStructure(reloaded)
 	case KeySpaceUpdateNotification:
 		handleKeySpaceEventCacheFlush(notif.Payload)
+	case NoticeOrgSessionUpdate:
+		handleOrgSessionCacheFlush(notif.Payload)
 	default:
 		pubSubLog.Warnf("Unknown notification command: %q", notif.Command)
 		return
@@ -128,6 +131,15 @@ func handleKeySpaceEventCacheFlush(payload string) {
 	}
 }
 
+// handleOrgSessionCacheFlush drops this node's cached copy of an org's
+// session (rate/quota settings) and its data-expiry entry, so a change made
+// via the control API takes effect immediately across the cluster instead
+// of waiting out SessionCache/ExpiryCache staleness.
+func handleOrgSessionCacheFlush(orgID string) {
+	SessionCache.Delete(orgID)
+	ExpiryCache.Delete(orgID)
+}
+
 var redisInsecureWarn sync.Once
 var notificationVerifier goverify.Verifier
 
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strconv"
+
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// ResponseTransformContentTypeMiddleware converts an upstream response body
+// between XML and JSON on configured paths, the response-side counterpart
+// to ContentTypeConversionMiddleware.
+type ResponseTransformContentTypeMiddleware struct {
+	Spec *APISpec
+}
+
+func (ResponseTransformContentTypeMiddleware) Name() string {
+	return "ResponseTransformContentTypeMiddleware"
+}
+
+func (h *ResponseTransformContentTypeMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *ResponseTransformContentTypeMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *ResponseTransformContentTypeMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	_, versionPaths, _, _ := h.Spec.Version(req)
+	found, meta := h.Spec.CheckSpecMatchesStatus(req, versionPaths, ContentTypeConversionResponse)
+	if !found {
+		return nil
+	}
+	ctMeta := meta.(*apidef.ContentTypeConversionMeta)
+
+	respBody := respBodyReader(req, res)
+	body, err := ioutil.ReadAll(respBody)
+	respBody.Close()
+	if err != nil {
+		return err
+	}
+
+	converted, err := convertContentType(body, ctMeta.From, ctMeta.To, ctMeta.XMLRootTag)
+	if err != nil {
+		return err
+	}
+
+	var bodyBuffer bytes.Buffer
+	bodyBuffer.Write(converted)
+
+	// Re-compress if original upstream response was compressed
+	encoding := res.Header.Get("Content-Encoding")
+	bodyBuffer = compressBuffer(bodyBuffer, encoding)
+
+	res.ContentLength = int64(bodyBuffer.Len())
+	res.Header.Set("Content-Length", strconv.Itoa(bodyBuffer.Len()))
+	res.Body = ioutil.NopCloser(&bodyBuffer)
+
+	return nil
+}
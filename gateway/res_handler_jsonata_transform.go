@@ -0,0 +1,65 @@
+// +build jsonata
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/TykTechnologies/tyk/user"
+)
+
+type ResponseTransformJSONataMiddleware struct {
+	Spec *APISpec
+}
+
+func (ResponseTransformJSONataMiddleware) Name() string {
+	return "ResponseTransformJSONataMiddleware"
+}
+
+func (h *ResponseTransformJSONataMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+
+	return nil
+}
+
+func (h *ResponseTransformJSONataMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *ResponseTransformJSONataMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	_, versionPaths, _, _ := h.Spec.Version(req)
+	found, meta := h.Spec.CheckSpecMatchesStatus(req, versionPaths, TransformedJSONataResponse)
+	if !found {
+		return nil
+	}
+
+	defer res.Body.Close()
+
+	ts := meta.(*TransformJSONataSpec)
+
+	var bodyObj interface{}
+	dec := json.NewDecoder(res.Body)
+	if err := dec.Decode(&bodyObj); err != nil {
+		return err
+	}
+
+	result, err := ts.Expr.Eval(bodyObj)
+	if err != nil {
+		return err
+	}
+
+	transformed, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	bodyBuffer := bytes.NewBuffer(transformed)
+	res.Header.Set("Content-Length", strconv.Itoa(bodyBuffer.Len()))
+	res.ContentLength = int64(bodyBuffer.Len())
+	res.Body = ioutil.NopCloser(bodyBuffer)
+
+	return nil
+}
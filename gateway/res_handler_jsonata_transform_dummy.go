@@ -0,0 +1,31 @@
+// +build !jsonata
+
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/user"
+)
+
+type ResponseTransformJSONataMiddleware struct {
+	Spec *APISpec
+}
+
+func (ResponseTransformJSONataMiddleware) Name() string {
+	return "ResponseTransformJSONataMiddleware"
+}
+
+func (h *ResponseTransformJSONataMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+
+	return nil
+}
+
+func (h *ResponseTransformJSONataMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *ResponseTransformJSONataMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	log.Warning("JSONata transforms not supported")
+	return nil
+}
@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"text/template"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// compiledResponseTransformStep is a ResponseTransformStep with its
+// expensive-to-build pieces (a parsed template, a compiled JQ filter or
+// JSONata expression) already prepared at load time.
+type compiledResponseTransformStep struct {
+	apidef.ResponseTransformStep
+	Template   *template.Template
+	JQFilter   *JQ
+	JSONataExp *chainJSONataExpr
+}
+
+// ResponseTransformChainSpec is the compiled form of a
+// ResponseTransformChainMeta, matched against a request the same way the
+// other per-path response actions are.
+type ResponseTransformChainSpec struct {
+	apidef.ResponseTransformChainMeta
+	Steps []compiledResponseTransformStep
+}
+
+// compileResponseTransformStep prepares the parts of step that are expensive
+// (or, for jq/jsonata, unsafe) to redo on every request.
+func (a APIDefinitionLoader) compileResponseTransformStep(step apidef.ResponseTransformStep) (compiledResponseTransformStep, error) {
+	compiled := compiledResponseTransformStep{ResponseTransformStep: step}
+
+	var err error
+	switch step.Type {
+	case apidef.ResponseTransformStepTemplate:
+		switch step.TemplateData.Mode {
+		case apidef.UseFile:
+			compiled.Template, err = a.loadFileTemplate(step.TemplateData.TemplateSource)
+		case apidef.UseBlob:
+			compiled.Template, err = a.loadBlobTemplate(step.TemplateData.TemplateSource)
+		default:
+			err = errors.New("no valid template mode defined, must be either 'file' or 'blob'")
+		}
+	case apidef.ResponseTransformStepJQ:
+		compiled.JQFilter, err = compileChainJQStep(step.JQFilter)
+	case apidef.ResponseTransformStepJSONata:
+		compiled.JSONataExp, err = compileChainJSONataStep(step.JSONataExpression)
+	case apidef.ResponseTransformStepHeader:
+		// Nothing to compile, AddHeaders/DeleteHeaders are used as-is.
+	default:
+		err = errors.New("unknown response transform chain step type: " + string(step.Type))
+	}
+
+	return compiled, err
+}
+
+// ResponseTransformChainMiddleware runs a ResponseTransformChainSpec's steps
+// in order for a matched path, so a JQ filter, a template and a header
+// rewrite (say) can be composed for the same path instead of only being able
+// to declare one entry per transform type.
+type ResponseTransformChainMiddleware struct {
+	Spec *APISpec
+}
+
+func (ResponseTransformChainMiddleware) Name() string {
+	return "ResponseTransformChainMiddleware"
+}
+
+func (h *ResponseTransformChainMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *ResponseTransformChainMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *ResponseTransformChainMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	_, versionPaths, _, _ := h.Spec.Version(req)
+	found, meta := h.Spec.CheckSpecMatchesStatus(req, versionPaths, TransformedResponseChain)
+	if !found {
+		return nil
+	}
+	chain := meta.(*ResponseTransformChainSpec)
+
+	respBody := respBodyReader(req, res)
+	body, err := ioutil.ReadAll(respBody)
+	respBody.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, step := range chain.Steps {
+		body, err = h.runStep(step, req, res, body)
+		if err != nil {
+			log.Error("Response transform chain step failed: ", err)
+			return err
+		}
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	res.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	return nil
+}
+
+func (h *ResponseTransformChainMiddleware) runStep(step compiledResponseTransformStep, req *http.Request, res *http.Response, body []byte) ([]byte, error) {
+	switch step.Type {
+	case apidef.ResponseTransformStepTemplate:
+		return h.runTemplateStep(step, req, body)
+	case apidef.ResponseTransformStepJQ:
+		transformed, rewriteHeaders, err := runChainJQStep(h.Spec, step.JQFilter, req, body)
+		if err != nil {
+			return nil, err
+		}
+		for hName, hValue := range rewriteHeaders {
+			res.Header.Set(hName, hValue)
+		}
+		return transformed, nil
+	case apidef.ResponseTransformStepJSONata:
+		return runChainJSONataStep(step.JSONataExp, body)
+	case apidef.ResponseTransformStepHeader:
+		for _, dKey := range step.DeleteHeaders {
+			res.Header.Del(dKey)
+		}
+		for nKey, nVal := range step.AddHeaders {
+			res.Header.Set(nKey, replaceTykVariables(req, nVal, false))
+		}
+		return body, nil
+	}
+
+	return body, nil
+}
+
+func (h *ResponseTransformChainMiddleware) runTemplateStep(step compiledResponseTransformStep, req *http.Request, body []byte) ([]byte, error) {
+	if len(body) == 0 {
+		body = []byte("{}")
+	}
+
+	var bodyData interface{}
+	if err := json.Unmarshal(body, &bodyData); err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{"body": bodyData}
+	if h.Spec.EnableContextVars {
+		data["_tyk_context"] = ctxGetData(req)
+	}
+	if step.TemplateData.EnableSession {
+		if session := ctxGetSession(req); session != nil {
+			data["_tyk_meta"] = session.MetaData
+		}
+	}
+
+	var out bytes.Buffer
+	if err := step.Template.Execute(&out, data); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
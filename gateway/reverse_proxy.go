@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"strconv"
 	"strings"
@@ -122,9 +123,42 @@ func EnsureTransport(host, protocol string) string {
 	return prefix + host
 }
 
-func nextTarget(targetData *apidef.HostList, spec *APISpec) (string, error) {
+// consistentHashKey returns the value nextTarget should hash on for
+// "consistent-hash" load balancing: spec.Proxy.ConsistentHashHeader if
+// configured and present, otherwise the request's authenticated key.
+func consistentHashKey(spec *APISpec, r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	if header := spec.Proxy.ConsistentHashHeader; header != "" {
+		if v := r.Header.Get(header); v != "" {
+			return v
+		}
+	}
+	return ctxGetAuthToken(r)
+}
+
+func nextTarget(targetData *apidef.HostList, spec *APISpec, r *http.Request) (string, error) {
 	if spec.Proxy.EnableLoadBalancing {
 		log.Debug("[PROXY] [LOAD BALANCING] Load balancer enabled, getting upstream target")
+
+		if spec.Proxy.LoadBalancerStrategy == "consistent-hash" {
+			if key := consistentHashKey(spec, r); key != "" {
+				gotHost, err := consistentHashTarget(targetData.All(), key)
+				if err != nil {
+					return "", err
+				}
+				host := EnsureTransport(gotHost, spec.Protocol)
+				if !spec.Proxy.CheckHostAgainstUptimeTests || !GlobalHostChecker.HostDown(host) {
+					return host, nil
+				}
+				// Hashed host is down and uptime checks matter here - fall
+				// through to the round-robin scan below, which tries every
+				// other host in turn, rather than failing the request
+				// outright for the sake of affinity.
+			}
+		}
+
 		// Use a HostList
 		startPos := spec.RoundRobin.WithLen(targetData.Len())
 		pos := startPos
@@ -219,7 +253,7 @@ func TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec) *ReverseProxy
 			}
 			fallthrough // implies load balancing, with replaced host list
 		case spec.Proxy.EnableLoadBalancing:
-			host, err := nextTarget(hostList, spec)
+			host, err := nextTarget(hostList, spec, req)
 			if err != nil {
 				log.Error("[PROXY] [LOAD BALANCING] ", err)
 				host = allHostsDownURL
@@ -284,6 +318,8 @@ func TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec) *ReverseProxy
 			// this should force URL to do escaping
 			req.URL.RawPath = ""
 		}
+
+		preserveHeaderCasing(req.Header, spec.Proxy.PreserveHeaderCasing)
 	}
 
 	proxy := &ReverseProxy{
@@ -349,6 +385,24 @@ func defaultTransport(dialerTimeout float64) *http.Transport {
 	}
 }
 
+// preserveHeaderCasing re-keys any header in names from its canonicalised
+// form back to the exact casing requested, directly on the map rather
+// than through Header.Set - Set would just canonicalise it right back.
+// net/http's request writer sends map keys as stored, so this is enough
+// to get the exact casing onto the wire for a case-sensitive upstream.
+func preserveHeaderCasing(header http.Header, names []string) {
+	for _, exact := range names {
+		canonical := textproto.CanonicalMIMEHeaderKey(exact)
+		if canonical == exact {
+			continue
+		}
+		if vals, ok := header[canonical]; ok {
+			delete(header, canonical)
+			header[exact] = vals
+		}
+	}
+}
+
 func singleJoiningSlash(a, b string, disableStripSlash bool) string {
 	if disableStripSlash && len(b) == 0 {
 		return a
@@ -554,11 +608,14 @@ func httpTransport(timeOut float64, rw http.ResponseWriter, req *http.Request, p
 	transport.DisableKeepAlives = p.TykAPISpec.GlobalConfig.ProxyCloseConnections
 
 	if IsWebsocket(req) {
-		wsTransport := &WSDialer{transport, rw, p.TLSClientConfig}
+		wsTransport := &WSDialer{transport, rw, p.TLSClientConfig, p.TykAPISpec}
 		return wsTransport
 	}
 
-	if config.Global().ProxyEnableHttp2 {
+	// gRPC is always HTTP/2, regardless of the global http2 toggle - without
+	// this the transport would silently negotiate HTTP/1.1 and break gRPC's
+	// framing.
+	if config.Global().ProxyEnableHttp2 || p.TykAPISpec.Protocol == "grpc" {
 		http2.ConfigureTransport(transport)
 	}
 
@@ -717,22 +774,31 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	// do request round trip
 	var res *http.Response
 	var err error
+	upstreamStart := time.Now()
 	if breakerEnforced {
-		if !breakerConf.CB.Ready() {
+		targetBreaker := breakerConf.BreakerFor(outreq.URL.Host)
+		if !targetBreaker.Ready() {
 			log.Debug("ON REQUEST: Circuit Breaker is in OPEN state")
+			if writeCircuitBreakerFallback(rw, req, p.TykAPISpec.APIID, outreq.URL.Host, breakerConf.FallbackResponse) {
+				return nil
+			}
 			p.ErrorHandler.HandleError(rw, logreq, "Service temporarily unavailable.", 503, true)
 			return nil
 		}
 		log.Debug("ON REQUEST: Circuit Breaker is in CLOSED or HALF-OPEN state")
+		reqStart := time.Now()
 		res, err = roundTripper.RoundTrip(outreq)
-		if err != nil || res.StatusCode == http.StatusInternalServerError {
-			breakerConf.CB.Fail()
+		tooSlow := breakerConf.LatencyThresholdMs > 0 && time.Since(reqStart) > time.Duration(breakerConf.LatencyThresholdMs)*time.Millisecond
+		if err != nil || res.StatusCode == http.StatusInternalServerError || tooSlow {
+			targetBreaker.Fail()
 		} else {
-			breakerConf.CB.Success()
+			targetBreaker.Success()
+			recordLastGoodResponse(p.TykAPISpec.APIID, outreq.URL.Host, res)
 		}
 	} else {
 		res, err = roundTripper.RoundTrip(outreq)
 	}
+	upstreamMs := float64(time.Since(upstreamStart).Nanoseconds()) / 1e6
 
 	if err != nil {
 
@@ -775,6 +841,12 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 			return nil
 		}
 
+		if strings.Contains(err.Error(), "certificate public key pinning error") {
+			ctxSetCertPinningMismatch(req)
+			p.ErrorHandler.HandleError(rw, logreq, "Upstream certificate rejected by pinning policy", http.StatusBadGateway, true)
+			return nil
+		}
+
 		p.ErrorHandler.HandleError(rw, logreq, "There was a problem proxying the request", http.StatusInternalServerError, true)
 		return nil
 
@@ -802,6 +874,11 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		log.Error("Response chain failed! ", err)
 	}
 
+	// A response that wasn't already going to be captured can still earn
+	// detailed recording here based on its own status code or a header the
+	// upstream set, which isn't known until now.
+	withCache = withCache || selectiveDetailTrigger(p.TykAPISpec, res.StatusCode, res.Header)
+
 	inres := new(http.Response)
 	if withCache {
 		*inres = *res // includes shallow copies of maps, but okay
@@ -823,11 +900,11 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	// We should at least copy the status code in
 	inres.StatusCode = res.StatusCode
 	inres.ContentLength = res.ContentLength
-	p.HandleResponse(rw, res, ses)
+	p.HandleResponse(rw, res, ses, req, upstreamMs)
 	return inres
 }
 
-func (p *ReverseProxy) HandleResponse(rw http.ResponseWriter, res *http.Response, ses *user.SessionState) error {
+func (p *ReverseProxy) HandleResponse(rw http.ResponseWriter, res *http.Response, ses *user.SessionState, req *http.Request, upstreamMs float64) error {
 
 	// Remove hop-by-hop headers listed in the
 	// "Connection" header of the response.
@@ -858,6 +935,25 @@ func (p *ReverseProxy) HandleResponse(rw http.ResponseWriter, res *http.Response
 		res.Header.Set(headers.XRateLimitReset, strconv.Itoa(int(quotaRenews)))
 	}
 
+	if p.TykAPISpec.EnableRequestBudgetHeaders {
+		gatewayMs := 0.0
+		for _, t := range ctxGetMWTimings(req) {
+			gatewayMs += t.Ms
+		}
+		res.Header.Set(headers.XTykGatewayTimeMs, strconv.FormatFloat(gatewayMs, 'f', 2, 64))
+		res.Header.Set(headers.XTykUpstreamTimeMs, strconv.FormatFloat(upstreamMs, 'f', 2, 64))
+		if ses != nil {
+			res.Header.Set(headers.XTykAppliedPolicies, strings.Join(ses.PolicyIDs(), ","))
+		}
+		if status := ctxGetCacheStatus(req); status != "" {
+			res.Header.Set(headers.XTykCacheStatus, status)
+		}
+	}
+
+	if p.TykAPISpec.EnableServerTimingHeader {
+		res.Header.Set(headers.ServerTiming, serverTimingHeaderValue(ctxGetMWTimings(req), upstreamMs))
+	}
+
 	copyHeader(rw.Header(), res.Header)
 
 	announcedTrailers := len(res.Trailer)
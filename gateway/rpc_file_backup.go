@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const (
+	rpcDefinitionsFileBackupName = "tyk-rpc-definitions-backup.json"
+	rpcPoliciesFileBackupName    = "tyk-rpc-policies-backup.json"
+)
+
+// rpcFileBackupPath returns where a given RPC backup snapshot is kept on
+// disk, nested under AppPath so it survives a restart even when Redis
+// itself is part of the outage that took MDCB offline.
+func rpcFileBackupPath(name string) string {
+	return filepath.Join(config.Global().AppPath, name)
+}
+
+func saveRPCDefinitionsFileBackup(list string) error {
+	secret := rightPad2Len(config.Global().Secret, "=", 32)
+	cryptoText := encrypt([]byte(secret), list)
+
+	if err := ioutil.WriteFile(rpcFileBackupPath(rpcDefinitionsFileBackupName), []byte(cryptoText), 0600); err != nil {
+		return errors.New("Failed to store local RPC definitions backup: " + err.Error())
+	}
+
+	return nil
+}
+
+// LoadDefinitionsFromRPCFileBackup loads the last known-good API definition
+// set from disk, for use when both MDCB and the Redis-backed RPC backup are
+// unreachable, e.g. a fresh restart of an isolated edge node.
+func LoadDefinitionsFromRPCFileBackup() ([]*APISpec, error) {
+	log.Info("[RPC] --> Loading API definitions from local file backup")
+
+	cryptoText, err := ioutil.ReadFile(rpcFileBackupPath(rpcDefinitionsFileBackupName))
+	if err != nil {
+		return nil, errors.New("[RPC] --> Failed to read local definitions backup: " + err.Error())
+	}
+
+	secret := rightPad2Len(config.Global().Secret, "=", 32)
+	apiListAsString := decrypt([]byte(secret), string(cryptoText))
+
+	a := APIDefinitionLoader{}
+	specs, err := a.processRPCDefinitions(apiListAsString)
+	if err != nil {
+		return nil, err
+	}
+
+	FireSystemEvent(EventRPCSourceDegraded, EventRPCSourceDegradedMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Loaded API definitions from local file backup, RPC source unavailable"},
+		Source:           "file",
+	})
+
+	return specs, nil
+}
+
+func saveRPCPoliciesFileBackup(list string) error {
+	secret := rightPad2Len(config.Global().Secret, "=", 32)
+	cryptoText := encrypt([]byte(secret), list)
+
+	if err := ioutil.WriteFile(rpcFileBackupPath(rpcPoliciesFileBackupName), []byte(cryptoText), 0600); err != nil {
+		return errors.New("Failed to store local RPC policies backup: " + err.Error())
+	}
+
+	return nil
+}
+
+// LoadPoliciesFromRPCFileBackup is the policy equivalent of
+// LoadDefinitionsFromRPCFileBackup.
+func LoadPoliciesFromRPCFileBackup() (map[string]user.Policy, error) {
+	log.Info("[RPC] --> Loading policies from local file backup")
+
+	cryptoText, err := ioutil.ReadFile(rpcFileBackupPath(rpcPoliciesFileBackupName))
+	if err != nil {
+		return nil, errors.New("[RPC] --> Failed to read local policies backup: " + err.Error())
+	}
+
+	secret := rightPad2Len(config.Global().Secret, "=", 32)
+	listAsString := decrypt([]byte(secret), string(cryptoText))
+
+	return parsePoliciesFromRPC(listAsString)
+}
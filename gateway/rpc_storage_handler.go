@@ -864,3 +864,8 @@ func (r *RPCStorageHandler) RemoveSortedSetRange(keyName, scoreFrom, scoreTo str
 	log.Error("RPCStorageHandler.RemoveSortedSetRange - Not implemented")
 	return nil
 }
+
+func (r *RPCStorageHandler) RemoveFromSortedSet(keyName, value string) error {
+	log.Error("RPCStorageHandler.RemoveFromSortedSet - Not implemented")
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -44,6 +45,7 @@ import (
 	"github.com/TykTechnologies/tyk/headers"
 	logger "github.com/TykTechnologies/tyk/log"
 	"github.com/TykTechnologies/tyk/regexp"
+	"github.com/TykTechnologies/tyk/request"
 	"github.com/TykTechnologies/tyk/rpc"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/trace"
@@ -63,6 +65,7 @@ var (
 	DefaultOrgStore          DefaultSessionManager
 	DefaultQuotaStore        DefaultSessionManager
 	FallbackKeySesionManager = SessionHandler(&DefaultSessionManager{})
+	OrgTokenStore            storage.RedisCluster
 	MonitoringHandler        config.TykEventHandler
 	RPCListener              RPCStorageHandler
 	DashService              DashboardServiceSender
@@ -158,6 +161,8 @@ func setupGlobals(ctx context.Context) {
 	reloadMu.Lock()
 	defer reloadMu.Unlock()
 
+	request.TrustedProxyDepth = config.Global().TrustedProxyDepth
+
 	dnsCacheManager = dnscache.NewDnsCacheManager(config.Global().DnsCache.MultipleIPsHandleStrategy)
 	if config.Global().DnsCache.Enabled {
 		dnsCacheManager.InitDNSCaching(
@@ -244,6 +249,7 @@ func setupGlobals(ctx context.Context) {
 	}
 
 	CertificateManager = certs.NewCertificateManager(getGlobalStorageHandler("cert-", false), certificateSecret, log)
+	CertificateManager.SetVaultConfig(resolveVaultConfig(config.Global().Vault))
 
 	if config.Global().NewRelic.AppName != "" {
 		NewRelicApplication = SetupNewRelic()
@@ -266,7 +272,35 @@ func buildConnStr(resource string) string {
 	return config.Global().DBAppConfOptions.ConnectionString + resource
 }
 
-func syncAPISpecs() (int, error) {
+// reloadScope optionally limits a reload to APIs with specific tags or
+// belonging to a specific org. APIs outside the scope keep the *APISpec
+// they already had rather than being replaced by a freshly re-parsed one,
+// so a control-plane change to one segment of a large installation can't
+// also redefine (or momentarily mis-define, on a bad fetch) every other
+// segment's APIs on the same reload cycle.
+type reloadScope struct {
+	tags  []string
+	orgID string
+}
+
+func (s *reloadScope) matches(spec *APISpec) bool {
+	if s.orgID != "" {
+		return spec.OrgID == s.orgID
+	}
+	for _, want := range s.tags {
+		for _, have := range spec.Tags {
+			if want == have {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var reloadScopeMu sync.Mutex
+var pendingReloadScope *reloadScope
+
+func syncAPISpecs(scope *reloadScope) (int, error) {
 	loader := APIDefinitionLoader{}
 	apisMu.Lock()
 	defer apisMu.Unlock()
@@ -282,6 +316,15 @@ func syncAPISpecs() (int, error) {
 		s = tmpSpecs
 
 		mainLog.Debug("Downloading API Configurations from Dashboard Service")
+	} else if config.Global().GitAppConfig.Enabled {
+		mainLog.Debug("Using git-backed API Configuration")
+
+		var err error
+		s, err = loader.FromGit(config.Global().GitAppConfig)
+		if err != nil {
+			log.Error("failed to load API specs from git: ", err)
+			return 0, err
+		}
 	} else if config.Global().SlaveOptions.UseRPC {
 		mainLog.Debug("Using RPC Configuration")
 
@@ -290,6 +333,15 @@ func syncAPISpecs() (int, error) {
 		if err != nil {
 			return 0, err
 		}
+	} else if config.Global().KubernetesAppConfig.Enabled {
+		mainLog.Debug("Using Kubernetes-backed API Configuration")
+
+		var err error
+		s, err = loader.FromKubernetes(config.Global().KubernetesAppConfig)
+		if err != nil {
+			log.Error("failed to load API specs from Kubernetes: ", err)
+			return 0, err
+		}
 	} else {
 		s = loader.FromDir(config.Global().AppPath)
 	}
@@ -315,10 +367,40 @@ func syncAPISpecs() (int, error) {
 		}
 		filter = append(filter, v)
 	}
+
+	if scope != nil {
+		filter = applyReloadScope(apiSpecs, filter, scope)
+	}
+
 	apiSpecs = filter
 	return len(apiSpecs), nil
 }
 
+// applyReloadScope walks freshly synced specs and, for any that fall
+// outside scope, substitutes the *APISpec the gateway already had loaded
+// for that API ID (if any), leaving it untouched by this reload. Specs
+// that are new, or that match scope, are taken from fresh as normal.
+func applyReloadScope(previous, fresh []*APISpec, scope *reloadScope) []*APISpec {
+	old := make(map[string]*APISpec, len(previous))
+	for _, spec := range previous {
+		old[spec.APIID] = spec
+	}
+
+	out := make([]*APISpec, len(fresh))
+	for i, spec := range fresh {
+		if scope.matches(spec) {
+			out[i] = spec
+			continue
+		}
+		if existing, ok := old[spec.APIID]; ok {
+			out[i] = existing
+			continue
+		}
+		out[i] = spec
+	}
+	return out
+}
+
 func syncPolicies() (count int, err error) {
 	var pols map[string]user.Policy
 
@@ -338,6 +420,9 @@ func syncPolicies() (count int, err error) {
 	case "rpc":
 		mainLog.Debug("Using Policies from RPC")
 		pols, err = LoadPoliciesFromRPC(config.Global().SlaveOptions.RPCKey)
+	case "git":
+		mainLog.Debug("Using Policies from git")
+		pols, err = LoadPoliciesFromGit(config.Global().GitAppConfig)
 	default:
 		// this is the only case now where we need a policy record name
 		if config.Global().Policies.PolicyRecordName == "" {
@@ -420,59 +505,122 @@ func loadAPIEndpoints(muxer *mux.Router) {
 		muxer.HandleFunc("/debug/pprof/{_:.*}", pprof_http.Index)
 	}
 
+	// Serve Prometheus metrics here too, unless MetricsPort asks for a
+	// dedicated listener - see startServer.
+	if mp := config.Global().MetricsPort; mp == 0 || mp == config.Global().ControlAPIPort {
+		muxer.HandleFunc("/metrics", metricsHandler).Methods("GET")
+	}
+
 	r.MethodNotAllowedHandler = MethodNotAllowedHandler{}
 
 	mainLog.Info("Initialising Tyk REST API Endpoints")
 
 	// set up main API handlers
-	r.HandleFunc("/reload/group", groupResetHandler).Methods("GET")
-	r.HandleFunc("/reload", resetHandler(nil)).Methods("GET")
+	//
+	// apiHandler and keyHandler are the only handlers below that restrict an
+	// organisation-scoped token to that org's own data themselves; every
+	// other route is wrapped in restrictToGlobalSecret so it's denied to an
+	// org token by default instead of running with no org restriction at all.
+	r.HandleFunc("/reload/group", restrictToGlobalSecret(groupResetHandler)).Methods("GET")
+	r.HandleFunc("/reload", restrictToGlobalSecret(resetHandler(nil))).Methods("GET")
+	r.HandleFunc("/reload/git", restrictToGlobalSecret(gitWebhookHandler)).Methods("POST")
+	r.HandleFunc("/reload/kubernetes", restrictToGlobalSecret(kubernetesWebhookHandler)).Methods("POST")
 
 	if !isRPCMode() {
-		r.HandleFunc("/org/keys", orgHandler).Methods("GET")
-		r.HandleFunc("/org/keys/{keyName:[^/]*}", orgHandler).Methods("POST", "PUT", "GET", "DELETE")
-		r.HandleFunc("/keys/policy/{keyName}", policyUpdateHandler).Methods("POST")
-		r.HandleFunc("/keys/create", createKeyHandler).Methods("POST")
+		r.HandleFunc("/org/keys", restrictToGlobalSecret(orgHandler)).Methods("GET")
+		r.HandleFunc("/org/keys/{keyName:[^/]*}", restrictToGlobalSecret(orgHandler)).Methods("POST", "PUT", "GET", "DELETE")
+		r.HandleFunc("/keys/policy/{keyName}", restrictToGlobalSecret(policyUpdateHandler)).Methods("POST")
+		r.HandleFunc("/keys/create", restrictToGlobalSecret(createKeyHandler)).Methods("POST")
+		r.HandleFunc("/keys/hash-migration", restrictToGlobalSecret(keyHashMigrationHandler)).Methods("GET")
+		r.HandleFunc("/keys/schema-migration", restrictToGlobalSecret(sessionMigrationHandler)).Methods("POST")
+		r.HandleFunc("/org/sessions/export", restrictToGlobalSecret(orgSessionExportHandler)).Methods("GET")
+		r.HandleFunc("/org/sessions/import", restrictToGlobalSecret(orgSessionImportHandler)).Methods("POST")
+		r.HandleFunc("/org-tokens/{orgID}", restrictToGlobalSecret(orgAPITokenHandler)).Methods("POST", "DELETE")
+		r.HandleFunc("/slow-log", restrictToGlobalSecret(slowLogHandler)).Methods("GET")
+		r.HandleFunc("/customdomains/{domain}", restrictToGlobalSecret(customDomainAuthHandler)).Methods("GET", "POST", "PUT", "DELETE")
 		r.HandleFunc("/apis", apiHandler).Methods("GET", "POST", "PUT", "DELETE")
+		r.HandleFunc("/apis/dependency-graph", restrictToGlobalSecret(apiDependencyGraphHandler)).Methods("GET")
 		r.HandleFunc("/apis/{apiID}", apiHandler).Methods("GET", "POST", "PUT", "DELETE")
-		r.HandleFunc("/health", healthCheckhandler).Methods("GET")
-		r.HandleFunc("/oauth/clients/create", createOauthClient).Methods("POST")
-		r.HandleFunc("/oauth/clients/{apiID}/{keyName:[^/]*}", oAuthClientHandler).Methods("PUT")
-		r.HandleFunc("/oauth/refresh/{keyName}", invalidateOauthRefresh).Methods("DELETE")
-		r.HandleFunc("/cache/{apiID}", invalidateCacheHandler).Methods("DELETE")
+		r.HandleFunc("/apis/{apiID}/load-health", restrictToGlobalSecret(apiLoadHealthHandler)).Methods("GET")
+		r.HandleFunc("/apis/{apiID}/overrides", restrictToGlobalSecret(apiOverridesHandler)).Methods("GET", "POST", "PUT", "DELETE")
+		r.HandleFunc("/apis/{apiID}/transform-test", restrictToGlobalSecret(apiTransformPreviewHandler)).Methods("POST")
+		r.HandleFunc("/health", restrictToGlobalSecret(healthCheckhandler)).Methods("GET")
+		r.HandleFunc("/cluster/nodes", restrictToGlobalSecret(clusterNodesHandler)).Methods("GET")
+		r.HandleFunc("/swagger.yml", restrictToGlobalSecret(swaggerHandler)).Methods("GET")
+		r.HandleFunc("/oauth/clients/create", restrictToGlobalSecret(createOauthClient)).Methods("POST")
+		r.HandleFunc("/oauth/clients/{apiID}/{keyName:[^/]*}", restrictToGlobalSecret(oAuthClientHandler)).Methods("PUT")
+		r.HandleFunc("/oauth/refresh/{keyName}", restrictToGlobalSecret(invalidateOauthRefresh)).Methods("DELETE")
+		r.HandleFunc("/cache/{apiID}", restrictToGlobalSecret(invalidateCacheHandler)).Methods("DELETE")
 	} else {
 		mainLog.Info("Node is slaved, REST API minimised")
 	}
 
-	r.HandleFunc("/debug", traceHandler).Methods("POST")
+	r.HandleFunc("/debug", restrictToGlobalSecret(traceHandler)).Methods("POST")
 
 	r.HandleFunc("/keys", keyHandler).Methods("POST", "PUT", "GET", "DELETE")
+	r.HandleFunc("/keys/batch", restrictToGlobalSecret(keyBatchHandler)).Methods("POST")
+	r.HandleFunc("/keys/alias/{alias}", restrictToGlobalSecret(aliasLookupHandler)).Methods("GET")
 	r.HandleFunc("/keys/{keyName:[^/]*}", keyHandler).Methods("POST", "PUT", "GET", "DELETE")
-	r.HandleFunc("/certs", certHandler).Methods("POST", "GET")
-	r.HandleFunc("/certs/{certID:[^/]*}", certHandler).Methods("POST", "GET", "DELETE")
-	r.HandleFunc("/oauth/clients/{apiID}", oAuthClientHandler).Methods("GET", "DELETE")
-	r.HandleFunc("/oauth/clients/{apiID}/{keyName:[^/]*}", oAuthClientHandler).Methods("GET", "DELETE")
-	r.HandleFunc("/oauth/clients/{apiID}/{keyName}/tokens", oAuthClientTokensHandler).Methods("GET")
+	r.HandleFunc("/keys/{keyName:[^/]*}/usage/stream", restrictToGlobalSecret(keyUsageStreamHandler)).Methods("GET")
+	r.HandleFunc("/certs", restrictToGlobalSecret(certHandler)).Methods("POST", "GET")
+	r.HandleFunc("/certs/{certID:[^/]*}", restrictToGlobalSecret(certHandler)).Methods("POST", "GET", "DELETE")
+	r.HandleFunc("/oauth/clients/{apiID}", restrictToGlobalSecret(oAuthClientHandler)).Methods("GET", "DELETE")
+	r.HandleFunc("/oauth/clients/{apiID}/{keyName:[^/]*}", restrictToGlobalSecret(oAuthClientHandler)).Methods("GET", "DELETE")
+	r.HandleFunc("/oauth/clients/{apiID}/{keyName}/tokens", restrictToGlobalSecret(oAuthClientTokensHandler)).Methods("GET", "DELETE")
+	r.HandleFunc("/oauth/clients/{apiID}/{keyName}/rotate-secret", restrictToGlobalSecret(oAuthClientRotateSecretHandler)).Methods("POST")
 
 	mainLog.Debug("Loaded API Endpoints")
 }
 
+// restrictToGlobalSecret wraps a control API handler that has no org-scoping
+// logic of its own, rejecting any request authenticated with an
+// organisation token (ctxGetControlAPIOrgID set) rather than the global
+// secret. Every route registered in loadAPIEndpoints must go through this
+// unless the handler itself restricts an org token to that org's own data -
+// currently only apiHandler and keyHandler do, so every other route is
+// wrapped with this by default.
+func restrictToGlobalSecret(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ctxGetControlAPIOrgID(r) != "" {
+			doJSONWrite(w, http.StatusForbidden, apiError("This endpoint is not available to organisation-scoped tokens"))
+			return
+		}
+		h(w, r)
+	}
+}
+
 // checkIsAPIOwner will ensure that the accessor of the tyk API has the
 // correct security credentials - this is a shared secret between the
 // client and the owner and is set in the tyk.conf file. This should
 // never be made public!
+//
+// It also accepts an organisation-scoped token minted by orgAPITokenHandler,
+// so a tenant can call the control API for self-service without ever
+// being handed the global secret. A request authenticated this way is
+// tagged with its org via ctxSetControlAPIOrgID; individual handlers that
+// know how to restrict themselves to that org's own APIs/keys/policies can
+// check it directly, and every other route is wrapped with
+// restrictToGlobalSecret so an org token is denied by default rather than
+// falling through to unrestricted access.
 func checkIsAPIOwner(next http.Handler) http.Handler {
 	secret := config.Global().Secret
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tykAuthKey := r.Header.Get(headers.XTykAuthorization)
-		if tykAuthKey != secret {
-			// Error
-			mainLog.Warning("Attempted administrative access with invalid or missing key!")
+		if tykAuthKey == secret {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-			doJSONWrite(w, http.StatusForbidden, apiError("Attempted administrative access with invalid or missing key!"))
+		if orgID, err := OrgTokenStore.GetKey(tykAuthKey); tykAuthKey != "" && err == nil {
+			ctxSetControlAPIOrgID(r, orgID)
+			next.ServeHTTP(w, r)
 			return
 		}
-		next.ServeHTTP(w, r)
+
+		// Error
+		mainLog.Warning("Attempted administrative access with invalid or missing key!")
+
+		doJSONWrite(w, http.StatusForbidden, apiError("Attempted administrative access with invalid or missing key!"))
 	})
 }
 
@@ -506,6 +654,18 @@ func addOAuthHandlers(spec *APISpec, muxer *mux.Router) *OAuthManager {
 	muxer.HandleFunc(clientAuthPath, allowMethods(oauthHandlers.HandleAuthorizePassthrough, "GET", "POST"))
 	muxer.HandleFunc(clientAccessPath, addSecureAndCacheHeaders(allowMethods(oauthHandlers.HandleAccessRequest, "GET", "POST")))
 
+	if spec.Oauth2Meta.DeviceFlow.Enabled {
+		deviceCodePath := spec.Proxy.ListenPath + "oauth/device/code{_:/?}"
+		deviceVerifyPath := spec.Proxy.ListenPath + "tyk/oauth/device/verify{_:/?}"
+		deviceTokenPath := spec.Proxy.ListenPath + "oauth/device/token{_:/?}"
+
+		deviceFlowHandlers := DeviceFlowHandlers{oauthManager, storageManager}
+
+		muxer.HandleFunc(deviceCodePath, addSecureAndCacheHeaders(allowMethods(deviceFlowHandlers.HandleDeviceCodeRequest, "POST")))
+		muxer.Handle(deviceVerifyPath, checkIsAPIOwner(allowMethods(deviceFlowHandlers.HandleVerification, "POST")))
+		muxer.HandleFunc(deviceTokenPath, addSecureAndCacheHeaders(allowMethods(deviceFlowHandlers.HandlePollingTokenRequest, "POST")))
+	}
+
 	return &oauthManager
 }
 
@@ -516,6 +676,17 @@ func addBatchEndpoint(spec *APISpec, muxer *mux.Router) {
 	muxer.HandleFunc(apiBatchPath, batchHandler.HandleBatchRequest)
 }
 
+// addTokenExchangeEndpoint mounts an RFC 8693 token exchange endpoint for
+// APIs that authenticate with a centralised JWT, letting a partner swap a
+// validated external JWT for a regular Tyk key without going through the
+// dashboard.
+func addTokenExchangeEndpoint(spec *APISpec, muxer *mux.Router) {
+	mainLog.Debug("Token exchange endpoint enabled for API")
+	tokenExchangePath := spec.Proxy.ListenPath + "tyk/token_exchange{_:/?}"
+	handler := TokenExchangeHandler{Spec: spec}
+	muxer.HandleFunc(tokenExchangePath, addSecureAndCacheHeaders(allowMethods(handler.HandleTokenExchange, "POST")))
+}
+
 func loadCustomMiddleware(spec *APISpec) ([]string, apidef.MiddlewareDefinition, []apidef.MiddlewareDefinition, []apidef.MiddlewareDefinition, []apidef.MiddlewareDefinition, []apidef.MiddlewareDefinition, apidef.MiddlewareDriver) {
 	mwPaths := []string{}
 	var mwAuthCheckFunc apidef.MiddlewareDefinition
@@ -608,11 +779,32 @@ func loadCustomMiddleware(spec *APISpec) ([]string, apidef.MiddlewareDefinition,
 
 }
 
-func createResponseMiddlewareChain(spec *APISpec, responseFuncs []apidef.MiddlewareDefinition) {
-	// Create the response processors
+// customMiddlewareFromSection extracts the coprocess hook lists straight out
+// of a MiddlewareSection, without touching the filesystem-glob or Otto-path
+// handling that loadCustomMiddleware does for the API-level bundle. It backs
+// version-scoped bundles (apidef.VersionInfo.CustomMiddlewareBundle), which
+// only ever come from a downloaded manifest, never from the JS middleware
+// folders on disk.
+func customMiddlewareFromSection(section apidef.MiddlewareSection) (mwPreFuncs, mwPostFuncs, mwPostKeyAuthFuncs, mwResponseFuncs []apidef.MiddlewareDefinition, mwDriver apidef.MiddlewareDriver) {
+	mwDriver = section.Driver
+	mwPreFuncs = append(mwPreFuncs, section.Pre...)
+	mwPostFuncs = append(mwPostFuncs, section.Post...)
+	mwPostKeyAuthFuncs = append(mwPostKeyAuthFuncs, section.PostKeyAuth...)
+	mwResponseFuncs = append(mwResponseFuncs, section.Response...)
+	return
+}
 
-	responseChain := make([]TykResponseHandler, len(spec.ResponseProcessors))
-	for i, processorDetail := range spec.ResponseProcessors {
+func createResponseMiddlewareChain(spec *APISpec, responseFuncs []apidef.MiddlewareDefinition, driver apidef.MiddlewareDriver) {
+	// Create the response processors, ordered by Priority (lowest first),
+	// falling back to declaration order for processors sharing a priority.
+	processorDetails := make([]apidef.ResponseProcessor, len(spec.ResponseProcessors))
+	copy(processorDetails, spec.ResponseProcessors)
+	sort.SliceStable(processorDetails, func(i, j int) bool {
+		return processorDetails[i].Priority < processorDetails[j].Priority
+	})
+
+	responseChain := make([]TykResponseHandler, len(processorDetails))
+	for i, processorDetail := range processorDetails {
 		processor := responseProcessorByName(processorDetail.Name)
 		if processor == nil {
 			mainLog.Error("No such processor: ", processorDetail.Name)
@@ -626,7 +818,11 @@ func createResponseMiddlewareChain(spec *APISpec, responseFuncs []apidef.Middlew
 	}
 
 	for _, mw := range responseFuncs {
-		processor := responseProcessorByName("custom_mw_res_hook")
+		processorName := "custom_mw_res_hook"
+		if driver == apidef.GoPluginDriver {
+			processorName = "goplugin_response_hook"
+		}
+		processor := responseProcessorByName(processorName)
 		// TODO: perhaps error when plugin support is disabled?
 		if processor == nil {
 			mainLog.Error("Couldn't find custom middleware processor")
@@ -677,6 +873,11 @@ func DoReload() {
 	reloadMu.Lock()
 	defer reloadMu.Unlock()
 
+	reloadScopeMu.Lock()
+	scope := pendingReloadScope
+	pendingReloadScope = nil
+	reloadScopeMu.Unlock()
+
 	// Initialize/reset the JSVM
 	if config.Global().EnableJSVM {
 		GlobalEventsJSVM.Init(nil, logrus.NewEntry(log))
@@ -689,7 +890,7 @@ func DoReload() {
 	}
 
 	// load the specs
-	if count, err := syncAPISpecs(); err != nil {
+	if count, err := syncAPISpecs(scope); err != nil {
 		mainLog.Error("Error during syncing apis:", err.Error())
 		return
 	} else {
@@ -772,6 +973,18 @@ func reloadURLStructure(done func()) {
 	reloadQueue <- done
 }
 
+// reloadURLStructureWithScope is reloadURLStructure, but limits the
+// reload to APIs matching scope (see reloadScope). If a reload is already
+// queued when this is called, the two requests are coalesced as usual and
+// this scope is the one that applies to the resulting reload - there's no
+// way to merge two different scopes into one reload.
+func reloadURLStructureWithScope(done func(), scope *reloadScope) {
+	reloadScopeMu.Lock()
+	pendingReloadScope = scope
+	reloadScopeMu.Unlock()
+	reloadURLStructure(done)
+}
+
 func setupLogger() {
 	if config.Global().UseSentry {
 		mainLog.Debug("Enabling Sentry support")
@@ -1160,11 +1373,24 @@ func start() {
 		DefaultQuotaStore.Init(getGlobalStorageHandler("orgkey.", false))
 	}
 
+	OrgTokenStore = storage.RedisCluster{KeyPrefix: "orgtoken."}
+	OrgTokenStore.Connect()
+
 	// Start listening for reload messages
 	if !config.Global().SuppressRedisSignalReload {
 		go startPubSubLoop()
 	}
 
+	if config.Global().GitAppConfig.Enabled {
+		go startGitSyncLoop()
+	}
+
+	if config.Global().KubernetesAppConfig.Enabled {
+		go startKubernetesSyncLoop()
+	}
+
+	go startUpstreamCertWatcher()
+
 	if slaveOptions := config.Global().SlaveOptions; slaveOptions.UseRPC {
 		mainLog.Debug("Starting RPC reload listener")
 		RPCListener = RPCStorageHandler{
@@ -1239,6 +1465,9 @@ func setupPortsWhitelist() {
 	if globalConf.ControlAPIPort != 0 {
 		ls.Ports = append(ls.Ports, globalConf.ControlAPIPort)
 	}
+	if globalConf.MetricsPort != 0 {
+		ls.Ports = append(ls.Ports, globalConf.MetricsPort)
+	}
 	w[protocol] = ls
 	globalConf.PortWhiteList = w
 	config.SetGlobal(globalConf)
@@ -1256,6 +1485,12 @@ func startServer() {
 		muxer.setRouter(config.Global().ListenPort, "", mux.NewRouter())
 	}
 
+	if mp := config.Global().MetricsPort; mp != 0 && mp != config.Global().ControlAPIPort && mp != config.Global().ListenPort {
+		metricsRouter := mux.NewRouter()
+		metricsRouter.HandleFunc("/metrics", metricsHandler).Methods("GET")
+		muxer.setRouter(mp, "", metricsRouter)
+	}
+
 	defaultProxyMux.swap(muxer)
 
 	// handle dashboard registration and nonces if available
@@ -1263,6 +1498,8 @@ func startServer() {
 
 	// at this point NodeID is ready to use by DRL
 	drlOnce.Do(startDRL)
+	clusterHeartbeatOnce.Do(startClusterHeartbeat)
+	analyticsArchiverOnce.Do(startAnalyticsArchiver)
 
 	mainLog.Infof("Tyk Gateway started (%s)", VERSION)
 	address := config.Global().ListenAddress
@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// sessionAliasStore indexes session aliases to their key name so a key can
+// be looked up by its human-friendly alias, and so two keys can't silently
+// claim the same alias.
+var sessionAliasStore = storage.RedisCluster{KeyPrefix: "alias-"}
+
+func aliasKeyName(alias string) string {
+	return "alias-map-" + alias
+}
+
+// checkAliasAvailable returns false if alias is already claimed by a key
+// other than keyName.
+func checkAliasAvailable(alias, keyName string) bool {
+	if alias == "" {
+		return true
+	}
+	owner, err := sessionAliasStore.GetRawKey(aliasKeyName(alias))
+	if err != nil {
+		return true
+	}
+	return owner == keyName
+}
+
+// setAlias records the alias -> key mapping, replacing any previous alias
+// this key held.
+func setAlias(alias, previousAlias, keyName string) {
+	if previousAlias != "" && previousAlias != alias {
+		sessionAliasStore.DeleteRawKey(aliasKeyName(previousAlias))
+	}
+	if alias == "" {
+		return
+	}
+	sessionAliasStore.SetRawKey(aliasKeyName(alias), keyName, 0)
+}
+
+// removeAlias deletes the alias -> key mapping for a removed key.
+func removeAlias(alias string) {
+	if alias == "" {
+		return
+	}
+	sessionAliasStore.DeleteRawKey(aliasKeyName(alias))
+}
+
+// keyNameByAlias looks up the key name that currently owns an alias.
+func keyNameByAlias(alias string) (string, bool) {
+	owner, err := sessionAliasStore.GetRawKey(aliasKeyName(alias))
+	if err != nil {
+		return "", false
+	}
+	return owner, true
+}
+
+// aliasLookupHandler resolves a session alias to its key detail, so callers
+// that only know a key's human-friendly alias don't need to track the
+// underlying token or hash.
+func aliasLookupHandler(w http.ResponseWriter, r *http.Request) {
+	alias := mux.Vars(r)["alias"]
+	apiID := r.URL.Query().Get("api_id")
+
+	keyName, found := keyNameByAlias(alias)
+	if !found {
+		doJSONWrite(w, http.StatusNotFound, apiError("Alias not found"))
+		return
+	}
+
+	obj, code := handleGetDetail(keyName, apiID, false)
+	doJSONWrite(w, code, obj)
+}
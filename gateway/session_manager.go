@@ -2,10 +2,12 @@ package gateway
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/TykTechnologies/leakybucket"
 	"github.com/TykTechnologies/leakybucket/memorycache"
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/user"
@@ -123,78 +125,134 @@ func (l *SessionLimiter) ForwardMessage(r *http.Request, currentSession *user.Se
 		}
 	}
 
+	// Rate limiting and quota tracking hit independent Redis keys, so when
+	// both are enabled and the operator has opted in, run them concurrently
+	// instead of paying their round trips back to back - this is the
+	// combination most requests take, and it's where the added per-request
+	// RTT is most visible at high load.
+	if enableRL && enableQ && globalConf.EnableConcurrentRateLimitAndQuotaChecks {
+		var rlBlocked, quotaBlocked bool
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			rlBlocked = l.checkRateLimit(key, currentSession, store, globalConf, apiLimit, allowanceScope, apiID, dryRun)
+		}()
+		go func() {
+			defer wg.Done()
+			quotaBlocked = l.checkQuota(r, currentSession, allowanceScope, apiLimit, store, globalConf)
+		}()
+		wg.Wait()
+
+		if rlBlocked {
+			return sessionFailRateLimit
+		}
+		if quotaBlocked {
+			return sessionFailQuota
+		}
+		return sessionFailNone
+	}
+
 	if enableRL {
-		rateScope := ""
-		if allowanceScope != "" {
-			rateScope = allowanceScope + "-"
+		if l.checkRateLimit(key, currentSession, store, globalConf, apiLimit, allowanceScope, apiID, dryRun) {
+			return sessionFailRateLimit
 		}
-		if globalConf.EnableSentinelRateLimiter {
-			rateLimiterKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash()
-			rateLimiterSentinelKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash() + ".BLOCKED"
+	}
 
+	if enableQ {
+		if l.checkQuota(r, currentSession, allowanceScope, apiLimit, store, globalConf) {
+			return sessionFailQuota
+		}
+	}
+
+	return sessionFailNone
+}
+
+// checkRateLimit runs the configured rate limiting strategy (sentinel,
+// Redis rolling window, or in-memory leaky bucket) and reports whether the
+// request should be blocked.
+func (l *SessionLimiter) checkRateLimit(key string, currentSession *user.SessionState, store storage.Handler, globalConf *config.Config, apiLimit *user.APILimit, allowanceScope string, apiID string, dryRun bool) bool {
+	rateScope := ""
+	if allowanceScope != "" {
+		rateScope = allowanceScope + "-"
+	}
+
+	strategy := apidef.RateLimitStrategyDefault
+	if spec := getApiSpec(apiID); spec != nil {
+		strategy = spec.RateLimit.Strategy
+	}
+	exact := strategy == apidef.RateLimitStrategyExact || (strategy != apidef.RateLimitStrategyFast && (globalConf.EnableSentinelRateLimiter || globalConf.EnableRedisRollingLimiter))
+
+	if exact {
+		rateLimiterKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash()
+		rateLimiterSentinelKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash() + ".BLOCKED"
+
+		// The sentinel variant is only meaningful when the node's global
+		// config asked for it - it trades a little more accuracy for one
+		// fewer blocking Redis round trip per request. A per-API "exact"
+		// override on a node that isn't running the sentinel limiter still
+		// gets the plain rolling window counter, which is exact either way.
+		if strategy != apidef.RateLimitStrategyExact && globalConf.EnableSentinelRateLimiter {
 			go l.doRollingWindowWrite(key, rateLimiterKey, rateLimiterSentinelKey, currentSession, store, globalConf, apiLimit, dryRun)
 
 			// Check sentinel
 			_, sentinelActive := store.GetRawKey(rateLimiterSentinelKey)
-			if sentinelActive == nil {
-				// Sentinel is set, fail
-				return sessionFailRateLimit
-			}
-		} else if globalConf.EnableRedisRollingLimiter {
-			rateLimiterKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash()
-			rateLimiterSentinelKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash() + ".BLOCKED"
+			// Sentinel is set, fail
+			return sentinelActive == nil
+		}
 
-			if l.doRollingWindowWrite(key, rateLimiterKey, rateLimiterSentinelKey, currentSession, store, globalConf, apiLimit, dryRun) {
-				return sessionFailRateLimit
-			}
-		} else {
-			// In-memory limiter
-			if l.bucketStore == nil {
-				l.bucketStore = memorycache.New()
-			}
+		return l.doRollingWindowWrite(key, rateLimiterKey, rateLimiterSentinelKey, currentSession, store, globalConf, apiLimit, dryRun)
+	}
 
-			bucketKey := key + ":" + rateScope + currentSession.LastUpdated
-			currRate := apiLimit.Rate
-			per := apiLimit.Per
+	// In-memory limiter
+	if l.bucketStore == nil {
+		l.bucketStore = memorycache.New()
+	}
 
-			// DRL will always overflow with more servers on low rates
-			rate := uint(currRate * float64(DRLManager.RequestTokenValue))
-			if rate < uint(DRLManager.CurrentTokenValue()) {
-				rate = uint(DRLManager.CurrentTokenValue())
-			}
+	bucketKey := key + ":" + rateScope + currentSession.LastUpdated
+	currRate := apiLimit.Rate
+	per := apiLimit.Per
 
-			userBucket, err := l.bucketStore.Create(bucketKey, rate, time.Duration(per)*time.Second)
-			if err != nil {
-				log.Error("Failed to create bucket!")
-				return sessionFailRateLimit
-			}
+	// DRL will always overflow with more servers on low rates
+	rate := uint(currRate * float64(DRLManager.RequestTokenValue))
+	if rate < uint(DRLManager.CurrentTokenValue()) {
+		rate = uint(DRLManager.CurrentTokenValue())
+	}
 
-			if dryRun {
-				// if userBucket is empty and not expired.
-				if userBucket.Remaining() == 0 && time.Now().Before(userBucket.Reset()) {
-					return sessionFailRateLimit
-				}
-			} else {
-				_, errF := userBucket.Add(uint(DRLManager.CurrentTokenValue()))
-				if errF != nil {
-					return sessionFailRateLimit
-				}
-			}
-		}
+	userBucket, err := l.bucketStore.Create(bucketKey, rate, time.Duration(per)*time.Second)
+	if err != nil {
+		log.Error("Failed to create bucket!")
+		return true
 	}
 
-	if enableQ {
-		if globalConf.LegacyEnableAllowanceCountdown {
-			currentSession.Allowance--
-		}
+	if dryRun {
+		// if userBucket is empty and not expired.
+		return userBucket.Remaining() == 0 && time.Now().Before(userBucket.Reset())
+	}
 
-		if l.RedisQuotaExceeded(r, currentSession, allowanceScope, apiLimit, store) {
-			return sessionFailQuota
-		}
+	_, errF := userBucket.Add(uint(DRLManager.CurrentTokenValue()))
+	return errF != nil
+}
+
+// checkQuota applies the legacy allowance countdown (if enabled) and
+// reports whether the request has exceeded its quota.
+func (l *SessionLimiter) checkQuota(r *http.Request, currentSession *user.SessionState, allowanceScope string, apiLimit *user.APILimit, store storage.Handler, globalConf *config.Config) bool {
+	if globalConf.LegacyEnableAllowanceCountdown {
+		currentSession.Allowance--
 	}
 
-	return sessionFailNone
+	return l.RedisQuotaExceeded(r, currentSession, allowanceScope, apiLimit, store)
+}
 
+// secondsToNextCalendarBoundary returns the number of seconds from now
+// until the next fixed clock boundary that is a multiple of period since
+// the Unix epoch (UTC), so quota periods reset on the wall clock (e.g.
+// every midnight for an 86400s period) rather than sliding forward from
+// whenever a key was first used.
+func secondsToNextCalendarBoundary(now time.Time, period int64) int64 {
+	nowUnix := now.Unix()
+	next := ((nowUnix / period) + 1) * period
+	return next - nowUnix
 }
 
 func (l *SessionLimiter) RedisQuotaExceeded(r *http.Request, currentSession *user.SessionState, scope string, limit *user.APILimit, store storage.Handler) bool {
@@ -214,10 +272,15 @@ func (l *SessionLimiter) RedisQuotaExceeded(r *http.Request, currentSession *use
 	quotaRenews := limit.QuotaRenews
 	quotaMax := limit.QuotaMax
 
+	ttl := quotaRenewalRate
+	if currentSession.QuotaCalendarAligned && quotaRenewalRate > 0 {
+		ttl = secondsToNextCalendarBoundary(time.Now(), quotaRenewalRate)
+	}
+
 	log.Debug("[QUOTA] Quota limiter key is: ", rawKey)
-	log.Debug("Renewing with TTL: ", quotaRenewalRate)
+	log.Debug("Renewing with TTL: ", ttl)
 	// INCR the key (If it equals 1 - set EXPIRE)
-	qInt := store.IncrememntWithExpire(rawKey, quotaRenewalRate)
+	qInt := store.IncrememntWithExpire(rawKey, ttl)
 
 	// if the returned val is >= quota: block
 	if qInt-1 >= quotaMax {
@@ -233,15 +296,27 @@ func (l *SessionLimiter) RedisQuotaExceeded(r *http.Request, currentSession *use
 			go store.DeleteRawKey(rawKey)
 			qInt = 1
 		} else {
-			// Renewal date is in the future and the quota is exceeded
-			return true
+			// Renewal date is in the future and the quota is exceeded.
+			// If the limit grants an overage allowance, let the request
+			// through and flag how far into the overage it is, instead of
+			// hard-cutting the key off.
+			overage := qInt - 1 - quotaMax
+			if limit.QuotaOverageAllowance > 0 && overage < limit.QuotaOverageAllowance {
+				ctxSetQuotaOverage(r, overage+1)
+			} else {
+				return true
+			}
 		}
 
 	}
 
 	// If this is a new Quota period, ensure we let the end user know
 	if qInt == 1 {
-		quotaRenews = time.Now().Unix() + quotaRenewalRate
+		if currentSession.QuotaCalendarAligned && quotaRenewalRate > 0 {
+			quotaRenews = time.Now().Unix() + secondsToNextCalendarBoundary(time.Now(), quotaRenewalRate)
+		} else {
+			quotaRenews = time.Now().Unix() + quotaRenewalRate
+		}
 		ctxScheduleSessionUpdate(r)
 	}
 
@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// TestSessionLimiter_ConcurrentQuotaCheck guards against a regression where
+// running the rate limit and quota checks concurrently
+// (EnableConcurrentRateLimitAndQuotaChecks) could lose the quota-exceeded
+// result if it raced with an unrelated rate limit pass.
+func TestSessionLimiter_ConcurrentQuotaCheck(t *testing.T) {
+	globalCfg := config.Global()
+	globalCfg.EnableConcurrentRateLimitAndQuotaChecks = true
+	config.SetGlobal(globalCfg)
+	defer ResetTestConfig()
+
+	ts := StartTest()
+	defer ts.Close()
+
+	keyID := CreateSession(func(s *user.SessionState) {
+		s.QuotaMax = 2
+		s.Rate = 1000
+		s.Per = 1
+	})
+
+	authHeaders := map[string]string{"authorization": keyID}
+
+	ts.Run(t, []test.TestCase{
+		{Path: "/", Headers: authHeaders, Code: 200},
+		{Path: "/", Headers: authHeaders, Code: 200},
+		{Path: "/", Headers: authHeaders, Code: 403, BodyMatch: `"error": "Quota exceeded"`},
+	}...)
+}
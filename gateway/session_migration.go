@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// sessionMigrationStatus reports how many stored sessions were rewritten to
+// user.CurrentSessionSchemaVersion by sessionMigrationHandler.
+//
+// swagger:model
+type sessionMigrationStatus struct {
+	SchemaVersion int `json:"schema_version"`
+	TotalKeys     int `json:"total_keys"`
+	Migrated      int `json:"migrated"`
+	Errors        int `json:"errors"`
+}
+
+// sessionMigrationHandler bulk-migrates every stored session to
+// user.CurrentSessionSchemaVersion. SessionState.Migrate already runs
+// transparently whenever a session is read, but that only updates the
+// in-memory copy for that one request - this walks every key in the store
+// and writes back any that changed, so an operator can force old sessions
+// onto the latest schema right after a gateway upgrade instead of waiting
+// for each one to happen to be read and re-saved on its own.
+func sessionMigrationHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := r.URL.Query().Get("api_id")
+	sessionManager := FallbackKeySesionManager
+	if spec := getApiSpec(apiID); spec != nil {
+		sessionManager = spec.SessionManager
+	}
+
+	hashed := config.Global().HashKeys
+	status := sessionMigrationStatus{SchemaVersion: user.CurrentSessionSchemaVersion}
+
+	for _, keyName := range sessionManager.Sessions("") {
+		if strings.HasPrefix(keyName, QuotaKeyPrefix) || strings.HasPrefix(keyName, RateLimitKeyPrefix) {
+			continue
+		}
+		status.TotalKeys++
+
+		session, found := sessionManager.SessionDetail(keyName, hashed)
+		if !found {
+			status.Errors++
+			continue
+		}
+
+		if !session.Migrate() {
+			continue
+		}
+
+		if err := sessionManager.UpdateSession(keyName, &session, 0, hashed); err != nil {
+			log.WithFields(logrus.Fields{
+				"prefix":      "session-migration",
+				"inbound-key": obfuscateKey(keyName),
+				"err":         err,
+			}).Error("Failed to write back migrated session")
+			status.Errors++
+			continue
+		}
+
+		status.Migrated++
+	}
+
+	doJSONWrite(w, http.StatusOK, status)
+}
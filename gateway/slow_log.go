@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/ctx"
+)
+
+// MWTiming captures how long a single middleware took to process a request,
+// as part of a slow request's latency breakdown.
+type MWTiming struct {
+	Name string  `json:"name"`
+	Ms   float64 `json:"ms"`
+}
+
+// ctxAppendMWTiming records how long a middleware took to run against the
+// request, so a slow request can later be broken down phase by phase.
+func ctxAppendMWTiming(r *http.Request, name string, d time.Duration) {
+	timings, _ := r.Context().Value(ctx.MWTimings).([]MWTiming)
+	timings = append(timings, MWTiming{Name: name, Ms: float64(d.Nanoseconds()) / 1e6})
+	setCtxValue(r, ctx.MWTimings, timings)
+}
+
+func ctxGetMWTimings(r *http.Request) []MWTiming {
+	timings, _ := r.Context().Value(ctx.MWTimings).([]MWTiming)
+	return timings
+}
+
+// mwTimingsTotalMs sums the middleware timings recorded so far against the
+// request, giving a latency figure for requests that are rejected by a
+// middleware before ever reaching the upstream (so RecordHit never runs).
+func mwTimingsTotalMs(r *http.Request) float64 {
+	var total float64
+	for _, t := range ctxGetMWTimings(r) {
+		total += t.Ms
+	}
+	return total
+}
+
+// serverTimingTokenReplacer sanitises a middleware name into a valid
+// Server-Timing metric name (an HTTP token: RFC 7230 section 3.2.6), since
+// middleware names such as a Go-plugin's "GoPluginMiddleware: path:symbol"
+// contain characters (spaces, colons) a token can't.
+var serverTimingTokenReplacer = strings.NewReplacer(" ", "_", ":", "_", ",", "_", ";", "_", "\"", "_")
+
+// serverTimingHeaderValue formats the recorded middleware timings plus the
+// upstream round trip as a standard Server-Timing header value, so a
+// request's gateway-side latency can be broken down phase by phase in
+// browser devtools or an APM agent without custom instrumentation.
+func serverTimingHeaderValue(mwTimings []MWTiming, upstreamMs float64) string {
+	entries := make([]string, 0, len(mwTimings)+1)
+	for _, t := range mwTimings {
+		name := serverTimingTokenReplacer.Replace(t.Name)
+		entries = append(entries, name+";dur="+strconv.FormatFloat(t.Ms, 'f', 2, 64))
+	}
+	entries = append(entries, "proxy;dur="+strconv.FormatFloat(upstreamMs, 'f', 2, 64))
+	return strings.Join(entries, ", ")
+}
+
+// SlowLogEntry records the latency breakdown for a single request that
+// exceeded its API's slow-log threshold.
+type SlowLogEntry struct {
+	Timestamp  time.Time  `json:"timestamp"`
+	APIID      string     `json:"api_id"`
+	Method     string     `json:"method"`
+	Path       string     `json:"path"`
+	TotalMs    float64    `json:"total_ms"`
+	UpstreamMs float64    `json:"upstream_ms"`
+	Middleware []MWTiming `json:"middleware"`
+}
+
+const slowLogRingSize = 200
+
+// slowLogRing is a small fixed-size ring buffer of recent slow requests,
+// retrievable via the control API for troubleshooting latency spikes without
+// having to stand up full analytics processing.
+var slowLogRing = struct {
+	sync.Mutex
+	entries []SlowLogEntry
+	next    int
+	full    bool
+}{entries: make([]SlowLogEntry, slowLogRingSize)}
+
+func recordSlowLog(entry SlowLogEntry) {
+	slowLogRing.Lock()
+	defer slowLogRing.Unlock()
+
+	slowLogRing.entries[slowLogRing.next] = entry
+	slowLogRing.next = (slowLogRing.next + 1) % slowLogRingSize
+	if slowLogRing.next == 0 {
+		slowLogRing.full = true
+	}
+}
+
+// slowLogEntries returns the ring buffer contents, most recent first.
+func slowLogEntries() []SlowLogEntry {
+	slowLogRing.Lock()
+	defer slowLogRing.Unlock()
+
+	n := slowLogRing.next
+	if slowLogRing.full {
+		n = slowLogRingSize
+	}
+
+	out := make([]SlowLogEntry, 0, n)
+	for i := 0; i < n; i++ {
+		idx := (slowLogRing.next - 1 - i + slowLogRingSize) % slowLogRingSize
+		out = append(out, slowLogRing.entries[idx])
+	}
+	return out
+}
+
+// checkSlowLog compares total request latency (middleware chain + upstream)
+// against the API's configured slow-log threshold and, if exceeded, records
+// a per-phase breakdown into the slow-log ring buffer.
+func checkSlowLog(r *http.Request, spec *APISpec, upstreamMs float64) {
+	if spec.SlowLogThresholdMs <= 0 {
+		return
+	}
+
+	mwTimings := ctxGetMWTimings(r)
+
+	total := upstreamMs
+	for _, t := range mwTimings {
+		total += t.Ms
+	}
+
+	if total < float64(spec.SlowLogThresholdMs) {
+		return
+	}
+
+	recordSlowLog(SlowLogEntry{
+		Timestamp:  time.Now(),
+		APIID:      spec.APIID,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		TotalMs:    total,
+		UpstreamMs: upstreamMs,
+		Middleware: mwTimings,
+	})
+}
+
+// slowLogHandler serves the recent slow-request ring buffer via the control API.
+func slowLogHandler(w http.ResponseWriter, r *http.Request) {
+	doJSONWrite(w, http.StatusOK, slowLogEntries())
+}
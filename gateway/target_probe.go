@@ -0,0 +1,122 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+const defaultProbeTargetsTimeout = 5 * time.Second
+
+var (
+	deadTargetWarningsMu sync.RWMutex
+	deadTargetWarnings   []DeadTargetWarning
+)
+
+// setLastDeadTargetWarnings records the outcome of the most recent
+// probeTargets run, so the reload endpoints can report it back to whoever
+// triggered the reload.
+func setLastDeadTargetWarnings(warnings []DeadTargetWarning) {
+	deadTargetWarningsMu.Lock()
+	deadTargetWarnings = warnings
+	deadTargetWarningsMu.Unlock()
+}
+
+// lastDeadTargetWarnings returns the outcome of the most recent
+// probeTargets run.
+func lastDeadTargetWarnings() []DeadTargetWarning {
+	deadTargetWarningsMu.RLock()
+	defer deadTargetWarningsMu.RUnlock()
+	return deadTargetWarnings
+}
+
+// DeadTargetWarning reports one API whose upstream target couldn't be
+// reached by probeTargets.
+type DeadTargetWarning struct {
+	APIID     string `json:"api_id"`
+	APIName   string `json:"api_name"`
+	TargetURL string `json:"target_url"`
+	Error     string `json:"error"`
+}
+
+// probeTargets HEAD-checks (or, for non-HTTP targets, TCP-connects to) each
+// spec's upstream target, so a typo'd or unreachable target is caught at
+// load time rather than only showing up once traffic starts failing against
+// it. It's opt-in via config.ProbeTargetsOnReload since it adds latency to
+// every reload proportional to the number of APIs and how reachable their
+// upstreams are.
+func probeTargets(specs []*APISpec) []DeadTargetWarning {
+	if !config.Global().ProbeTargetsOnReload {
+		return nil
+	}
+
+	timeout := time.Duration(config.Global().ProbeTargetsTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultProbeTargetsTimeout
+	}
+
+	var warnings []DeadTargetWarning
+	for _, spec := range specs {
+		target := spec.Proxy.TargetURL
+		if target == "" || spec.Proxy.ServiceDiscovery.UseDiscoveryService {
+			continue
+		}
+
+		if err := probeTarget(target, timeout); err != nil {
+			warnings = append(warnings, DeadTargetWarning{
+				APIID:     spec.APIID,
+				APIName:   spec.Name,
+				TargetURL: target,
+				Error:     err.Error(),
+			})
+
+			spec.FireEvent(EventTargetUnreachable, EventTargetUnreachableMeta{
+				EventMetaDefault: EventMetaDefault{Message: "Upstream target unreachable at load time"},
+				APIID:            spec.APIID,
+				TargetURL:        target,
+				Error:            err.Error(),
+			})
+
+			log.Warning("Upstream target unreachable for API ", spec.APIID, ": ", err)
+		}
+	}
+
+	return warnings
+}
+
+func probeTarget(target string, timeout time.Duration) error {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return probeTCP(target, timeout)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		client := &http.Client{Timeout: timeout}
+		req, err := http.NewRequest(http.MethodHead, target, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	default:
+		return probeTCP(u.Host, timeout)
+	}
+}
+
+func probeTCP(hostport string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", hostport, timeout)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
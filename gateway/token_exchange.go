@@ -0,0 +1,128 @@
+package gateway
+
+import (
+	"crypto/md5"
+	"fmt"
+	"net/http"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+const (
+	tokenExchangeGrantType        = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenExchangeSubjectTokenType = "urn:ietf:params:oauth:token-type:jwt"
+	tokenExchangeIssuedTokenType  = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// tokenExchangeResponse mirrors the RFC 8693 token exchange response body.
+type tokenExchangeResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in,omitempty"`
+}
+
+// TokenExchangeHandler implements a minimal RFC 8693 (OAuth 2.0 Token
+// Exchange) endpoint: it verifies a subject JWT against the API's existing
+// JWT configuration and mints a regular Tyk session/key from the policy the
+// token maps to, the same way JWTMiddleware would for an inbound request.
+// Only subject_token_type "urn:ietf:params:oauth:token-type:jwt" is
+// supported.
+type TokenExchangeHandler struct {
+	Spec *APISpec
+}
+
+// HandleTokenExchange implements the token endpoint described in RFC 8693
+// section 2.1.
+func (h *TokenExchangeHandler) HandleTokenExchange(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("could not parse request body"))
+		return
+	}
+
+	if r.Form.Get("grant_type") != tokenExchangeGrantType {
+		doJSONWrite(w, http.StatusBadRequest, apiError("unsupported grant_type"))
+		return
+	}
+
+	if st := r.Form.Get("subject_token_type"); st != "" && st != tokenExchangeSubjectTokenType {
+		doJSONWrite(w, http.StatusBadRequest, apiError("unsupported subject_token_type"))
+		return
+	}
+
+	rawJWT := r.Form.Get("subject_token")
+	if rawJWT == "" {
+		doJSONWrite(w, http.StatusBadRequest, apiError("subject_token is required"))
+		return
+	}
+
+	jwtMW := &JWTMiddleware{BaseMiddleware: BaseMiddleware{Spec: h.Spec}}
+
+	token, err := jwtMW.parseJWT(stripBearer(rawJWT), r)
+	if err != nil || !token.Valid {
+		doJSONWrite(w, http.StatusUnauthorized, apiError("subject_token is not a valid token for this API"))
+		return
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	if jwtErr := jwtMW.timeValidateJWTClaims(claims); jwtErr != nil {
+		doJSONWrite(w, http.StatusUnauthorized, apiError("subject_token has expired or is not yet valid"))
+		return
+	}
+
+	subject, err := jwtMW.getUserIdFromClaim(claims)
+	if err != nil {
+		doJSONWrite(w, http.StatusUnauthorized, apiError("subject_token has no identifiable subject"))
+		return
+	}
+
+	basePolicyID, foundPolicy := jwtMW.getBasePolicyID(r, claims)
+	defaultPolicies := jwtMW.defaultJWTPolicies(claims)
+	if !foundPolicy {
+		if len(defaultPolicies) == 0 {
+			doJSONWrite(w, http.StatusForbidden, apiError("no policy mapped for this token"))
+			return
+		}
+		basePolicyID = defaultPolicies[0]
+	}
+
+	session, err := generateSessionFromPolicy(basePolicyID, h.Spec.OrgID, true)
+	if err != nil {
+		doJSONWrite(w, http.StatusForbidden, apiError("no matching policy for this token"))
+		return
+	}
+
+	for _, pol := range defaultPolicies {
+		if !contains(session.ApplyPolicies, pol) {
+			session.ApplyPolicies = append(session.ApplyPolicies, pol)
+		}
+	}
+
+	if f, ok := claims["exp"].(float64); ok && int64(f) > session.Expires {
+		session.Expires = int64(f)
+	}
+
+	session.Alias = subject
+
+	keyID := fmt.Sprintf("%x", md5.Sum([]byte(subject)))
+	newKey := generateToken(h.Spec.OrgID, keyID)
+
+	if err := applyPoliciesAndSave(newKey, &session, h.Spec, false); err != nil {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("failed to issue token - "+err.Error()))
+		return
+	}
+
+	resp := tokenExchangeResponse{
+		AccessToken:     newKey,
+		IssuedTokenType: tokenExchangeIssuedTokenType,
+		// RFC 8693 section 2.2.1: "N_A" when the token isn't for use with
+		// the OAuth 2.0 Authorization framework's Bearer scheme.
+		TokenType: "N_A",
+	}
+	if session.Expires > 0 {
+		resp.ExpiresIn = session.Expires - time.Now().Unix()
+	}
+
+	doJSONWrite(w, http.StatusOK, resp)
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"math/rand"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/trace"
+)
+
+// statusCapturingResponseWriter records the status code written by the
+// wrapped handler, so tracingHandler can decide whether to force-sample
+// after the handler has run.
+type statusCapturingResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusCapturingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// shouldForceSample reports whether r carries spec.TracingOptions'
+// configured force-sample header.
+func shouldForceSample(spec *APISpec, r *http.Request) bool {
+	header := spec.TracingOptions.ForceSampleHeader
+	return header != "" && r.Header.Get(header) != ""
+}
+
+// tracingHandler wraps h with a root trace span the same way trace.Handle
+// does, but applies spec.TracingOptions on top of it: requests are skipped
+// before a span is even created according to SampleRate, unless they carry
+// ForceSampleHeader or the API wants error responses force-sampled - in
+// which case a span is always created, and retroactively force-sampled on
+// error via trace.ForceSample. This lets a high-volume API sample lightly
+// while a low-volume, critical one still gets every request traced, all
+// without touching the gateway's global tracer configuration.
+func tracingHandler(spec *APISpec, h http.Handler) http.Handler {
+	opts := spec.TracingOptions
+	if !opts.Enabled {
+		return trace.Handle(spec.Name, h)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forced := shouldForceSample(spec, r)
+
+		if !forced && !opts.ForceSampleOnError && rand.Float64() >= opts.SampleRate {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		span, req := trace.Root(spec.Name, r)
+		defer span.Finish()
+
+		if forced {
+			trace.ForceSample(span)
+		}
+
+		sw := &statusCapturingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, req)
+
+		if opts.ForceSampleOnError && sw.status >= http.StatusBadRequest {
+			trace.ForceSample(span)
+		}
+	})
+}
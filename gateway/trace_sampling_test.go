@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func tracingSpec(opts apidef.TracingOptions) *APISpec {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+	spec.Name = "trace-sampling-test"
+	spec.TracingOptions = opts
+	return spec
+}
+
+func TestShouldForceSample(t *testing.T) {
+	spec := tracingSpec(apidef.TracingOptions{ForceSampleHeader: "X-Force-Trace"})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if shouldForceSample(spec, req) {
+		t.Error("expected no force-sample header to not force sampling")
+	}
+
+	req.Header.Set("X-Force-Trace", "1")
+	if !shouldForceSample(spec, req) {
+		t.Error("expected the configured header to force sampling")
+	}
+}
+
+func TestTracingHandler_ForceSampleHeaderAlwaysRunsHandler(t *testing.T) {
+	spec := tracingSpec(apidef.TracingOptions{Enabled: true, SampleRate: 0, ForceSampleHeader: "X-Force-Trace"})
+
+	called := false
+	h := tracingHandler(spec, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Force-Trace", "1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !called {
+		t.Error("expected the wrapped handler to run when the force-sample header is set")
+	}
+}
+
+func TestTracingHandler_ZeroSampleRateStillServesRequest(t *testing.T) {
+	spec := tracingSpec(apidef.TracingOptions{Enabled: true, SampleRate: 0})
+
+	called := false
+	h := tracingHandler(spec, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if !called {
+		t.Error("expected the wrapped handler to run even when SampleRate is 0")
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// parseBaggageHeader decodes a W3C "baggage" header into its member
+// key/value pairs, discarding any trailing properties
+// (";key=value" segments) which carry metadata we don't act on.
+// See https://www.w3.org/TR/baggage/.
+func parseBaggageHeader(header string) map[string]string {
+	baggage := map[string]string{}
+	if header == "" {
+		return baggage
+	}
+
+	for _, member := range strings.Split(header, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+
+		// Strip any properties after the first ";".
+		if i := strings.Index(member, ";"); i != -1 {
+			member = member[:i]
+		}
+
+		kv := strings.SplitN(member, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(kv[0])
+		val, err := url.QueryUnescape(strings.TrimSpace(kv[1]))
+		if err != nil {
+			val = strings.TrimSpace(kv[1])
+		}
+
+		if key != "" {
+			baggage[key] = val
+		}
+	}
+
+	return baggage
+}
+
+// parseTracestateHeader decodes a W3C "tracestate" header into its
+// vendor key/value entries, so a specific upstream vendor's experiment
+// or routing hint can be read the same way as baggage.
+// See https://www.w3.org/TR/trace-context/#tracestate-header.
+func parseTracestateHeader(header string) map[string]string {
+	tracestate := map[string]string{}
+	if header == "" {
+		return tracestate
+	}
+
+	for _, member := range strings.Split(header, ",") {
+		member = strings.TrimSpace(member)
+		kv := strings.SplitN(member, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(kv[0])
+		if key != "" {
+			tracestate[key] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	return tracestate
+}
+
+// addTracingBaggageToContextData exposes W3C baggage and tracestate members
+// as request-context variables (baggage_<key> / tracestate_<key>), so
+// routing triggers and templates initiated upstream in the call chain can
+// influence gateway behaviour without a bespoke header per experiment.
+func addTracingBaggageToContextData(r *http.Request, contextDataObject map[string]interface{}) {
+	for key, val := range parseBaggageHeader(r.Header.Get("baggage")) {
+		contextDataObject["baggage_"+key] = val
+	}
+
+	for key, val := range parseTracestateHeader(r.Header.Get("tracestate")) {
+		contextDataObject["tracestate_"+key] = val
+	}
+}
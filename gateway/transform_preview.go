@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/clbanning/mxj"
+	"github.com/gorilla/mux"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// transformPreviewRequest is the payload accepted by apiTransformPreviewHandler.
+//
+// swagger:model
+type transformPreviewRequest struct {
+	// Method is the HTTP method used to match the path's transform config.
+	// Defaults to GET.
+	Method string `json:"method"`
+	// Path is the version-relative request path, e.g. "/get".
+	Path string `json:"path"`
+	// RequestBody is the sample payload run through the path's request
+	// transform, if one is configured.
+	RequestBody string `json:"request_body"`
+	// ResponseBody is the sample payload run through the path's response
+	// transform, if one is configured. Omit to skip the response check.
+	ResponseBody string `json:"response_body,omitempty"`
+}
+
+// transformPreviewResult carries the rendered output of a single transform,
+// or the error it failed with, so a template mistake never has to be
+// diagnosed by firing live traffic through the gateway.
+//
+// swagger:model
+type transformPreviewResult struct {
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// transformPreviewResponse is only populated for the transform directions
+// that are actually configured on the requested path - a path with no
+// response transform simply omits "response" from the reply.
+//
+// swagger:model
+type transformPreviewResponse struct {
+	Request  *transformPreviewResult `json:"request,omitempty"`
+	Response *transformPreviewResult `json:"response,omitempty"`
+}
+
+// renderTransformTemplate mirrors the body-decoding and template execution
+// done by transformBody and ResponseTransformMiddleware.HandleResponse, but
+// works against a supplied sample body instead of a live request/response,
+// so it can be reused to preview either direction offline.
+func renderTransformTemplate(tmeta *TransformSpec, body []byte) ([]byte, error) {
+	bodyData := make(map[string]interface{})
+
+	switch tmeta.TemplateData.Input {
+	case apidef.RequestXML:
+		if len(body) == 0 {
+			body = []byte("<_/>")
+		}
+		mxj.XmlCharsetReader = WrappedCharsetReader
+		var err error
+		bodyData, err = mxj.NewMapXml(body)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling XML: %v", err)
+		}
+	case apidef.RequestJSON:
+		if len(body) == 0 {
+			body = []byte("{}")
+		}
+		var tempBody interface{}
+		if err := json.Unmarshal(body, &tempBody); err != nil {
+			return nil, fmt.Errorf("error unmarshalling JSON: %v", err)
+		}
+		switch tempBody.(type) {
+		case []interface{}:
+			bodyData["array"] = tempBody
+		case map[string]interface{}:
+			bodyData = tempBody.(map[string]interface{})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported input type: %v", tmeta.TemplateData.Input)
+	}
+
+	var bodyBuffer bytes.Buffer
+	if err := tmeta.Template.Execute(&bodyBuffer, bodyData); err != nil {
+		return nil, fmt.Errorf("failed to apply template: %v", err)
+	}
+	return bodyBuffer.Bytes(), nil
+}
+
+// previewTransform looks up the transform configured for mode on path/method
+// and, if one is found, renders it against sampleBody.
+func previewTransform(spec *APISpec, method, path string, mode URLStatus, sampleBody string) *transformPreviewResult {
+	fakeReq, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return &transformPreviewResult{Error: err.Error()}
+	}
+
+	_, versionPaths, _, _ := spec.Version(fakeReq)
+	found, meta := spec.CheckSpecMatchesStatus(fakeReq, versionPaths, mode)
+	if !found {
+		return nil
+	}
+
+	out, err := renderTransformTemplate(meta.(*TransformSpec), []byte(sampleBody))
+	if err != nil {
+		return &transformPreviewResult{Error: err.Error()}
+	}
+	return &transformPreviewResult{Output: string(out)}
+}
+
+// apiTransformPreviewHandler implements POST /apis/{apiID}/transform-test. It
+// runs a supplied sample payload through the request and/or response
+// transforms configured for a path, and returns the rendered output (or the
+// template error) for each direction that's configured - so integration
+// engineers can iterate on templates without deploying the API or firing
+// live traffic at it.
+func apiTransformPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := mux.Vars(r)["apiID"]
+	spec := getApiSpec(apiID)
+	if spec == nil {
+		doJSONWrite(w, http.StatusNotFound, apiError("API not found"))
+		return
+	}
+
+	var testReq transformPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&testReq); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Request malformed"))
+		return
+	}
+
+	method := testReq.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	path := spec.Proxy.ListenPath + strings.TrimPrefix(testReq.Path, "/")
+
+	result := transformPreviewResponse{
+		Request: previewTransform(spec, method, path, Transformed, testReq.RequestBody),
+	}
+	if testReq.ResponseBody != "" {
+		result.Response = previewTransform(spec, method, path, TransformedResponse, testReq.ResponseBody)
+	}
+
+	doJSONWrite(w, http.StatusOK, result)
+}
@@ -0,0 +1,47 @@
+package gateway
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+const defaultDrainConnectionsTimeout = 60 * time.Second
+
+// idleConnectionCloser is implemented by *http.Transport and by our own
+// WSDialer, and is the smallest interface drainTransport needs.
+type idleConnectionCloser interface {
+	CloseIdleConnections()
+}
+
+// drainOldTransports is called whenever a reload replaces spec with a new
+// *APISpec, so spec's own HTTPTransport/WSTransport don't just get dropped
+// and left for the garbage collector - which never proactively closes the
+// idle connections they're holding open, and would abruptly cut off any
+// request still in flight on them if something did close them synchronously
+// here. Instead they're kept alive for DrainConnectionsTimeout so in-flight
+// requests can finish, then have their idle connections closed.
+func drainOldTransports(spec *APISpec) {
+	grace := time.Duration(config.Global().DrainConnectionsTimeout) * time.Second
+	if grace == 0 {
+		grace = defaultDrainConnectionsTimeout
+	}
+
+	spec.Lock()
+	httpTransport := spec.HTTPTransport
+	wsTransport := spec.WSTransport
+	spec.Unlock()
+
+	drainTransport(httpTransport, grace)
+	drainTransport(wsTransport, grace)
+}
+
+func drainTransport(rt http.RoundTripper, grace time.Duration) {
+	closer, ok := rt.(idleConnectionCloser)
+	if !ok {
+		return
+	}
+
+	time.AfterFunc(grace, closer.CloseIdleConnections)
+}
@@ -0,0 +1,232 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// WebSocket frame opcodes, as defined by RFC 6455 section 5.2.
+const (
+	wsOpcodeContinuation byte = 0x0
+	wsOpcodeText         byte = 0x1
+	wsOpcodeBinary       byte = 0x2
+)
+
+// maxWSFramePayload caps how large a single frame's declared payload length
+// may be, so a malicious or buggy client can't make relayWSMessages try to
+// buffer an unbounded amount of memory before it's even read the payload.
+const maxWSFramePayload = 10 << 20 // 10MB
+
+// wsFrame is a single parsed WebSocket frame. Payload is already unmasked,
+// if it was masked on the wire - see readWSFrame/writeWSFrame.
+type wsFrame struct {
+	fin     bool
+	opcode  byte
+	masked  bool
+	maskKey [4]byte
+	payload []byte
+}
+
+// readWSFrame reads and unmasks a single WebSocket frame from r.
+func readWSFrame(r io.Reader) (wsFrame, error) {
+	var f wsFrame
+
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return f, err
+	}
+	f.fin = head[0]&0x80 != 0
+	f.opcode = head[0] & 0x0f
+	f.masked = head[1]&0x80 != 0
+
+	length := uint64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return f, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return f, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if length > maxWSFramePayload {
+		return f, errors.New("websocket frame payload too large")
+	}
+
+	if f.masked {
+		if _, err := io.ReadFull(r, f.maskKey[:]); err != nil {
+			return f, err
+		}
+	}
+
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return f, err
+	}
+
+	if f.masked {
+		for i := range f.payload {
+			f.payload[i] ^= f.maskKey[i%4]
+		}
+	}
+
+	return f, nil
+}
+
+// writeWSFrame re-serializes f, re-masking its payload with its original
+// mask key if it was masked on the way in.
+func writeWSFrame(w io.Writer, f wsFrame) error {
+	var head bytes.Buffer
+
+	b0 := f.opcode
+	if f.fin {
+		b0 |= 0x80
+	}
+	head.WriteByte(b0)
+
+	maskBit := byte(0)
+	if f.masked {
+		maskBit = 0x80
+	}
+
+	length := len(f.payload)
+	switch {
+	case length <= 125:
+		head.WriteByte(maskBit | byte(length))
+	case length <= 0xffff:
+		head.WriteByte(maskBit | 126)
+		ext := make([]byte, 2)
+		binary.BigEndian.PutUint16(ext, uint16(length))
+		head.Write(ext)
+	default:
+		head.WriteByte(maskBit | 127)
+		ext := make([]byte, 8)
+		binary.BigEndian.PutUint64(ext, uint64(length))
+		head.Write(ext)
+	}
+
+	if f.masked {
+		head.Write(f.maskKey[:])
+	}
+
+	if _, err := w.Write(head.Bytes()); err != nil {
+		return err
+	}
+
+	if !f.masked {
+		_, err := w.Write(f.payload)
+		return err
+	}
+
+	masked := make([]byte, length)
+	for i, b := range f.payload {
+		masked[i] = b ^ f.maskKey[i%4]
+	}
+	_, err := w.Write(masked)
+	return err
+}
+
+// wsMessageLimiter is a simple per-connection token bucket, refilled at
+// ratePerSecond tokens/sec, used to cap how many messages a single
+// WebSocket connection may send upstream per second.
+type wsMessageLimiter struct {
+	mu         sync.Mutex
+	ratePerSec int
+	tokens     float64
+	last       time.Time
+}
+
+func newWSMessageLimiter(ratePerSec int) *wsMessageLimiter {
+	return &wsMessageLimiter{ratePerSec: ratePerSec, tokens: float64(ratePerSec), last: time.Now()}
+}
+
+func (l *wsMessageLimiter) allow() bool {
+	if l.ratePerSec <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * float64(l.ratePerSec)
+	if l.tokens > float64(l.ratePerSec) {
+		l.tokens = float64(l.ratePerSec)
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// applyWSMessageTransform runs an API's WSMessageTemplate against a text
+// message payload, exposing it to the template as .Message.
+func applyWSMessageTransform(spec *APISpec, payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := spec.WSMessageTemplate.Execute(&buf, map[string]string{"Message": string(payload)}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// relayWSMessages replaces the plain io.Copy used for the client->upstream
+// direction of a proxied WebSocket connection when
+// APIDefinition.WebSocketHooks.Enabled is set, so each message frame can be
+// rate limited, transformed and/or turned into a WebSocketMessage event
+// before being relayed on to the upstream.
+//
+// Only the client->upstream direction is intercepted: these hooks exist to
+// police and inspect what clients send, not what upstreams reply with.
+func relayWSMessages(dst io.Writer, src io.Reader, spec *APISpec, errc chan<- error) {
+	limiter := newWSMessageLimiter(spec.WebSocketHooks.MessageRateLimitPerSecond)
+	r := bufio.NewReader(src)
+
+	for {
+		frame, err := readWSFrame(r)
+		if err != nil {
+			errc <- err
+			return
+		}
+
+		if frame.opcode == wsOpcodeText || frame.opcode == wsOpcodeBinary {
+			if !limiter.allow() {
+				errc <- errors.New("websocket message rate limit exceeded")
+				return
+			}
+
+			if frame.opcode == wsOpcodeText && spec.WSMessageTemplate != nil {
+				if transformed, err := applyWSMessageTransform(spec, frame.payload); err != nil {
+					log.WithError(err).Error("Failed to apply websocket payload transform")
+				} else {
+					frame.payload = transformed
+				}
+			}
+
+			if spec.WebSocketHooks.FireEvents {
+				spec.FireEvent(EventWebSocketMessage, EventWebSocketMessageMeta{
+					EventMetaDefault: EventMetaDefault{Message: "WebSocket message received"},
+					APIID:            spec.APIID,
+					Payload:          string(frame.payload),
+				})
+			}
+		}
+
+		if err := writeWSFrame(dst, frame); err != nil {
+			errc <- err
+			return
+		}
+	}
+}
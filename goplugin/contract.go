@@ -0,0 +1,109 @@
+package goplugin
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// APIVersionV2 is the contract version implemented by this file. Plugins
+// built against it must return this value from PluginV2.APIVersion() so the
+// gateway can refuse to load a plugin built against an incompatible version
+// of the contract instead of failing in less obvious ways deeper in the
+// request path.
+const APIVersionV2 = "v2"
+
+// TypedRequest exposes the inbound request to a v2 plugin through typed
+// accessors instead of requiring the plugin to reach into net/http fields
+// directly, so the contract can evolve without breaking source
+// compatibility with existing plugins.
+type TypedRequest struct {
+	*http.Request
+}
+
+// Header returns the first value of the named request header.
+func (r *TypedRequest) Header(name string) string {
+	return r.Request.Header.Get(name)
+}
+
+// Query returns the first value of the named query string parameter.
+func (r *TypedRequest) Query(name string) string {
+	return r.Request.URL.Query().Get(name)
+}
+
+// TypedResponseWriter exposes the outbound response to a v2 plugin. Unlike
+// the raw http.ResponseWriter passed to a v1 plugin function, writing
+// through it is optional: if a plugin never calls WriteHeader or Write, the
+// gateway treats the request as unhandled and lets it continue down the
+// middleware chain.
+type TypedResponseWriter struct {
+	http.ResponseWriter
+}
+
+// WriteHeader sends the given status code and headers, and marks the
+// response as handled by the plugin.
+func (w *TypedResponseWriter) WriteHeader(statusCode int) {
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// SetHeader sets a response header. It must be called before WriteHeader or
+// Write.
+func (w *TypedResponseWriter) SetHeader(name, value string) {
+	w.ResponseWriter.Header().Set(name, value)
+}
+
+// KVStore is a small Redis-backed key/value helper made available to v2
+// plugins, so plugins that need to keep state across requests don't each
+// need to open their own Redis connection or resolve gateway internals.
+type KVStore interface {
+	Get(key string) (string, error)
+	Set(key, value string, ttlSeconds int64) error
+	Delete(key string) error
+}
+
+// PluginContext is passed to PluginV2.HandleRequest and bundles everything a
+// v2 plugin needs: the typed request/response pair, the caller's session
+// (nil for keyless APIs), a request-scoped logger, and a KV helper -
+// instead of a plugin resolving these by reaching into gateway internals by
+// symbol name, as v1 plugins have to.
+type PluginContext struct {
+	Req     *TypedRequest
+	Resp    *TypedResponseWriter
+	Session *user.SessionState
+	Logger  *logrus.Entry
+	KV      KVStore
+}
+
+// PluginResult is returned by HandleRequest to tell the gateway what to do
+// next.
+type PluginResult struct {
+	// Continue, when true, lets the request carry on down the middleware
+	// chain. When false, the plugin has already written the response
+	// (via ctx.Resp) and the gateway should stop processing.
+	Continue bool
+	// Err, when non-nil with Continue false, is reported to the gateway's
+	// standard error handler and analytics instead of the response
+	// already written via ctx.Resp.
+	Err error
+}
+
+// PluginV2 is the typed Go-plugin contract. A plugin exports a symbol
+// implementing this interface (instead of a bare
+// func(http.ResponseWriter, *http.Request)) so the gateway can check
+// APIVersion() at load time and refuse to run a plugin built against a
+// contract it no longer understands.
+type PluginV2 interface {
+	// APIVersion must return APIVersionV2.
+	APIVersion() string
+	HandleRequest(ctx *PluginContext) PluginResult
+}
+
+// ResponseHookFunc is the symbol signature a Go plugin exports to run as a
+// response hook (a MiddlewareSection.Response entry with Driver ==
+// "goplugin"). It gets the upstream response, the original request and the
+// caller's session (nil for keyless APIs) and can mutate the response in
+// place; a non-nil error aborts the response chain the same way a returning
+// custom middleware response hook does.
+type ResponseHookFunc func(res *http.Response, req *http.Request, session *user.SessionState) error
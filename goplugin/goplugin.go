@@ -1,11 +1,15 @@
+//go:build goplugin
 // +build goplugin
 
 package goplugin
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"plugin"
+
+	"github.com/TykTechnologies/tyk/user"
 )
 
 func GetHandler(path string, symbol string) (http.HandlerFunc, error) {
@@ -29,3 +33,51 @@ func GetHandler(path string, symbol string) (http.HandlerFunc, error) {
 
 	return pluginHandler, nil
 }
+
+// GetHandlerV2 loads a plugin built against the typed PluginV2 contract and
+// checks its declared APIVersion before handing it back, so a plugin built
+// against an incompatible contract version fails to load with a clear
+// error instead of misbehaving once it's already in the request path.
+func GetHandlerV2(path, symbol string) (PluginV2, error) {
+	loadedPlugin, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	funcSymbol, err := loadedPlugin.Lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	impl, ok := funcSymbol.(PluginV2)
+	if !ok {
+		return nil, errors.New("could not cast plugin symbol to goplugin.PluginV2")
+	}
+
+	if impl.APIVersion() != APIVersionV2 {
+		return nil, fmt.Errorf("plugin was built against contract version %q, gateway expects %q", impl.APIVersion(), APIVersionV2)
+	}
+
+	return impl, nil
+}
+
+// GetResponseHandler loads a plugin built for the response-hook contract:
+// a symbol implementing ResponseHookFunc.
+func GetResponseHandler(path, symbol string) (ResponseHookFunc, error) {
+	loadedPlugin, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	funcSymbol, err := loadedPlugin.Lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	responseHandler, ok := funcSymbol.(func(*http.Response, *http.Request, *user.SessionState) error)
+	if !ok {
+		return nil, errors.New("could not cast function symbol to goplugin.ResponseHookFunc")
+	}
+
+	return responseHandler, nil
+}
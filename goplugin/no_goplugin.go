@@ -1,3 +1,4 @@
+//go:build !goplugin
 // +build !goplugin
 
 package goplugin
@@ -10,3 +11,11 @@ import (
 func GetHandler(path string, symbol string) (http.HandlerFunc, error) {
 	return nil, fmt.Errorf("goplugin.GetHandler is disabled, please disable build flag 'nogoplugin'")
 }
+
+func GetHandlerV2(path, symbol string) (PluginV2, error) {
+	return nil, fmt.Errorf("goplugin.GetHandlerV2 is disabled, please disable build flag 'nogoplugin'")
+}
+
+func GetResponseHandler(path, symbol string) (ResponseHookFunc, error) {
+	return nil, fmt.Errorf("goplugin.GetResponseHandler is disabled, please disable build flag 'nogoplugin'")
+}
@@ -14,6 +14,7 @@ const (
 	Expires                 = "Expires"
 	Connection              = "Connection"
 	WWWAuthenticate         = "WWW-Authenticate"
+	TransferEncoding        = "Transfer-Encoding"
 )
 
 const (
@@ -44,4 +45,16 @@ const (
 	XRateLimitLimit     = "X-RateLimit-Limit"
 	XRateLimitRemaining = "X-RateLimit-Remaining"
 	XRateLimitReset     = "X-RateLimit-Reset"
+
+	// Per-request cost/latency budget headers, added when an API turns on
+	// EnableRequestBudgetHeaders.
+	XTykGatewayTimeMs   = "X-Tyk-Gateway-Time-Ms"
+	XTykUpstreamTimeMs  = "X-Tyk-Upstream-Time-Ms"
+	XTykAppliedPolicies = "X-Tyk-Applied-Policies"
+	XTykCacheStatus     = "X-Tyk-Cache-Status"
+
+	// ServerTiming is the standard header (W3C Server-Timing) added when an
+	// API turns on EnableServerTimingHeader, so browser devtools and APM
+	// agents can attribute gateway latency without custom instrumentation.
+	ServerTiming = "Server-Timing"
 )
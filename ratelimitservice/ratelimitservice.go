@@ -0,0 +1,111 @@
+// Package ratelimitservice is a hand-written client for the subset of the
+// Envoy RLS gRPC protocol (envoy.service.ratelimit.v3.RateLimitService) that
+// the gateway needs in order to ask an external rate limit service for a
+// decision. It is not generated by protoc - the .proto sources for that
+// service aren't vendored in this repository - but the message field
+// numbers and the RPC path below match the public envoy.service.ratelimit.v3
+// definitions, so it's wire-compatible with a real Envoy RLS server.
+package ratelimitservice
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+)
+
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// RateLimitDescriptor_Entry is a single key/value pair within a
+// RateLimitDescriptor.
+type RateLimitDescriptor_Entry struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RateLimitDescriptor_Entry) Reset()         { *m = RateLimitDescriptor_Entry{} }
+func (m *RateLimitDescriptor_Entry) String() string { return proto.CompactTextString(m) }
+func (*RateLimitDescriptor_Entry) ProtoMessage()    {}
+
+// RateLimitDescriptor is one descriptor to be matched against the RLS
+// server's configured rate limit rules.
+type RateLimitDescriptor struct {
+	Entries              []*RateLimitDescriptor_Entry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *RateLimitDescriptor) Reset()         { *m = RateLimitDescriptor{} }
+func (m *RateLimitDescriptor) String() string { return proto.CompactTextString(m) }
+func (*RateLimitDescriptor) ProtoMessage()    {}
+
+// RateLimitRequest asks the RLS server whether Descriptors are within limit
+// for Domain.
+type RateLimitRequest struct {
+	Domain               string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Descriptors          []*RateLimitDescriptor `protobuf:"bytes,2,rep,name=descriptors,proto3" json:"descriptors,omitempty"`
+	HitsAddend           uint32                 `protobuf:"varint,3,opt,name=hits_addend,json=hitsAddend,proto3" json:"hits_addend,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *RateLimitRequest) Reset()         { *m = RateLimitRequest{} }
+func (m *RateLimitRequest) String() string { return proto.CompactTextString(m) }
+func (*RateLimitRequest) ProtoMessage()    {}
+
+// RateLimitResponse_Code is the overall verdict for a RateLimitRequest.
+type RateLimitResponse_Code int32
+
+const (
+	RateLimitResponse_UNKNOWN    RateLimitResponse_Code = 0
+	RateLimitResponse_OK         RateLimitResponse_Code = 1
+	RateLimitResponse_OVER_LIMIT RateLimitResponse_Code = 2
+)
+
+// RateLimitResponse is the RLS server's verdict for a RateLimitRequest. Only
+// OverallCode is decoded here - the real response also carries per-descriptor
+// statuses and response headers, which this trimmed client doesn't need.
+type RateLimitResponse struct {
+	OverallCode          RateLimitResponse_Code `protobuf:"varint,1,opt,name=overall_code,json=overallCode,proto3,enum=envoy.service.ratelimit.v3.RateLimitResponse_Code" json:"overall_code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *RateLimitResponse) Reset()         { *m = RateLimitResponse{} }
+func (m *RateLimitResponse) String() string { return proto.CompactTextString(m) }
+func (*RateLimitResponse) ProtoMessage()    {}
+
+// RateLimitServiceClient is the client API for the RateLimitService,
+// trimmed down to the one RPC the gateway calls.
+type RateLimitServiceClient interface {
+	ShouldRateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error)
+}
+
+type rateLimitServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+// NewRateLimitServiceClient wraps cc for calls to the RLS server's
+// ShouldRateLimit RPC.
+func NewRateLimitServiceClient(cc *grpc.ClientConn) RateLimitServiceClient {
+	return &rateLimitServiceClient{cc}
+}
+
+func (c *rateLimitServiceClient) ShouldRateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error) {
+	out := new(RateLimitResponse)
+	err := c.cc.Invoke(ctx, "/envoy.service.ratelimit.v3.RateLimitService/ShouldRateLimit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -8,6 +8,13 @@ import (
 	"github.com/TykTechnologies/tyk/headers"
 )
 
+// TrustedProxyDepth is the number of trusted reverse proxies sitting in
+// front of the gateway. When 0 (the default), RealIP keeps the historical
+// behaviour of trusting the left-most X-Forwarded-For entry. When set, the
+// entry that many hops in from the right is used instead, since only that
+// many hops are known to be trustworthy in a chain of proxies.
+var TrustedProxyDepth int
+
 // RealIP takes a request object, and returns the real Client IP address.
 func RealIP(r *http.Request) string {
 
@@ -20,13 +27,24 @@ func RealIP(r *http.Request) string {
 	}
 
 	if fw := r.Header.Get(headers.XForwardFor); fw != "" {
-		// X-Forwarded-For has no port
-		if i := strings.IndexByte(fw, ','); i >= 0 {
+		if TrustedProxyDepth <= 0 {
+			// X-Forwarded-For has no port
+			if i := strings.IndexByte(fw, ','); i >= 0 {
+				return fw[:i]
+			}
+
+			return fw
+		}
 
-			return fw[:i]
+		hops := strings.Split(fw, ",")
+		idx := len(hops) - TrustedProxyDepth
+		if idx < 0 {
+			idx = 0
+		} else if idx >= len(hops) {
+			idx = len(hops) - 1
 		}
 
-		return fw
+		return strings.TrimSpace(hops[idx])
 	}
 
 	// From net/http.Request.RemoteAddr:
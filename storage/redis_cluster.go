@@ -882,3 +882,20 @@ func (r *RedisCluster) RemoveSortedSetRange(keyName, scoreFrom, scoreTo string)
 
 	return nil
 }
+
+// RemoveFromSortedSet removes a single value from sorted set identified by keyName
+func (r *RedisCluster) RemoveFromSortedSet(keyName, value string) error {
+	fixedKey := r.fixKey(keyName)
+	logEntry := logrus.Fields{
+		"keyName":  keyName,
+		"fixedKey": fixedKey,
+	}
+	log.WithFields(logEntry).Debug("Removing value from sorted set")
+
+	if _, err := r.singleton().Do("ZREM", fixedKey, value); err != nil {
+		log.WithFields(logEntry).WithError(err).Error("ZREM command failed")
+		return err
+	}
+
+	return nil
+}
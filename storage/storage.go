@@ -55,6 +55,7 @@ type Handler interface {
 	AddToSortedSet(string, string, float64)
 	GetSortedSetRange(string, string, string) ([]string, []float64, error)
 	RemoveSortedSetRange(string, string, string) error
+	RemoveFromSortedSet(string, string) error
 }
 
 const defaultHashAlgorithm = "murmur64"
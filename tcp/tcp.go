@@ -68,6 +68,20 @@ type Proxy struct {
 	SyncStats func(Stat)
 	// Duration in which connection stats will be flushed. Defaults to one second.
 	StatsSyncInterval time.Duration
+
+	// UpstreamHealthCheckInterval, when non-zero, periodically probes the
+	// upstream target with a short-lived dial for as long as a connection
+	// is being proxied to it, so a dead upstream socket is detected
+	// instead of lingering until the client gives up on its own timeout.
+	UpstreamHealthCheckInterval time.Duration
+	// UpstreamHealthCheckTimeout bounds each health probe dial. Defaults
+	// to UpstreamHealthCheckInterval when unset.
+	UpstreamHealthCheckTimeout time.Duration
+	// CloseClientOnUpstreamDown closes both ends of a proxied connection
+	// as soon as a health probe fails, so the client can reconnect (and
+	// get load-balanced to a healthy upstream) right away rather than
+	// waiting on its own read/write timeout.
+	CloseClientOnUpstreamDown bool
 }
 
 func (p *Proxy) AddDomainHandler(domain, target string, modifier *Modifier) {
@@ -168,6 +182,40 @@ func (p *Proxy) getTargetConfig(conn net.Conn) (*targetConfig, error) {
 	return nil, errors.New("Can't detect service configuration")
 }
 
+// watchUpstreamHealth periodically probes host with a fresh short-lived
+// dial while a connection is proxied to it. If a probe fails and
+// CloseClientOnUpstreamDown is set, it tears down both ends of the
+// proxied connection so the client fails fast and can reconnect, instead
+// of relying on its own read/write timeout. It stops as soon as ctx is
+// cancelled, which happens when the proxied connection itself closes.
+func (p *Proxy) watchUpstreamHealth(ctx context.Context, host string, conn, rconn net.Conn) {
+	timeout := p.UpstreamHealthCheckTimeout
+	if timeout == 0 {
+		timeout = p.UpstreamHealthCheckInterval
+	}
+
+	ticker := time.NewTicker(p.UpstreamHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			probe, err := net.DialTimeout("tcp", host, timeout)
+			if err != nil {
+				log.WithError(err).WithField("host", host).Warning("Upstream health ping failed")
+				if p.CloseClientOnUpstreamDown {
+					conn.Close()
+					rconn.Close()
+				}
+				return
+			}
+			probe.Close()
+		}
+	}
+}
+
 func (p *Proxy) handleConn(conn net.Conn) error {
 	stat := Stat{}
 
@@ -233,6 +281,11 @@ func (p *Proxy) handleConn(conn net.Conn) error {
 		conn.Close()
 		return err
 	}
+
+	if p.UpstreamHealthCheckInterval > 0 {
+		go p.watchUpstreamHealth(ctx, u.Host, conn, rconn)
+	}
+
 	r := func(src, dst net.Conn, data []byte) ([]byte, error) {
 		atomic.AddInt64(&stat.BytesIn, int64(len(data)))
 		h := config.modifier.ModifyRequest
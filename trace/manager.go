@@ -10,6 +10,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/request"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 )
 
 var ErrManagerDisabled = errors.New("trace: trace is diabled")
@@ -261,3 +262,12 @@ func Inject(service string, span opentracing.Span, h http.Header) error {
 func InjectFromContext(ctx context.Context, span opentracing.Span, h http.Header) error {
 	return Inject(GetServiceID(ctx), span, h)
 }
+
+// ForceSample marks span with the standard OpenTracing sampling.priority
+// tag, overriding the tracer's own sampler so the span is kept even if it
+// wasn't picked up by chance - e.g. so a caller can retroactively decide to
+// keep a span once it knows the request ended in an error, without having
+// to know ahead of time whether the underlying tracer would have sampled it.
+func ForceSample(span opentracing.Span) {
+	ext.SamplingPriority.Set(span, 1)
+}
@@ -19,8 +19,21 @@ type Policy struct {
 	IsInactive         bool                        `bson:"is_inactive" json:"is_inactive"`
 	Tags               []string                    `bson:"tags" json:"tags"`
 	KeyExpiresIn       int64                       `bson:"key_expires_in" json:"key_expires_in"`
-	Partitions         PolicyPartitions            `bson:"partitions" json:"partitions"`
-	LastUpdated        string                      `bson:"last_updated" json:"last_updated"`
+	// IdleTimeout, when set, lets a key granted this policy renew its TTL
+	// on every active request instead of expiring on a fixed schedule,
+	// for low-risk internal tokens that should only die from disuse.
+	IdleTimeout int64 `bson:"idle_timeout" json:"idle_timeout"`
+	// MaxLifetime caps how long IdleTimeout can keep sliding a key's
+	// expiry forward, measured from the key's creation. 0 means no cap.
+	MaxLifetime int64            `bson:"max_lifetime" json:"max_lifetime"`
+	Partitions  PolicyPartitions `bson:"partitions" json:"partitions"`
+	LastUpdated string           `bson:"last_updated" json:"last_updated"`
+	// Priority is this policy's QoS class, used by the gateway's load
+	// shedding to decide which requests keep flowing when an upstream
+	// signals distress. Higher values are more important; the default of
+	// 0 is the lowest priority. When a session carries several policies,
+	// the highest Priority among them applies.
+	Priority int `bson:"priority" json:"priority"`
 }
 
 type PolicyPartitions struct {
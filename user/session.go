@@ -34,7 +34,16 @@ type APILimit struct {
 	QuotaRenews        int64   `json:"quota_renews" msg:"quota_renews"`
 	QuotaRemaining     int64   `json:"quota_remaining" msg:"quota_remaining"`
 	QuotaRenewalRate   int64   `json:"quota_renewal_rate" msg:"quota_renewal_rate"`
-	SetBy              string  `json:"-" msg:"-"`
+	// QuotaCalendarAligned makes the quota period reset on a fixed clock
+	// boundary (e.g. midnight UTC for a 24h renewal rate) instead of
+	// sliding forward from whenever the key first got used.
+	QuotaCalendarAligned bool `json:"quota_calendar_aligned" msg:"quota_calendar_aligned"`
+	// QuotaOverageAllowance lets a key make this many additional requests
+	// once QuotaMax is reached, instead of being cut off immediately, so
+	// pay-as-you-go policies can bill for overage rather than hard-stopping
+	// traffic. 0 (the default) preserves the existing hard-cutoff behaviour.
+	QuotaOverageAllowance int64  `json:"quota_overage_allowance" msg:"quota_overage_allowance"`
+	SetBy                 string `json:"-" msg:"-"`
 }
 
 // AccessDefinition defines which versions of an API a key has access to
@@ -43,7 +52,20 @@ type AccessDefinition struct {
 	APIID       string       `json:"api_id" msg:"api_id"`
 	Versions    []string     `json:"versions" msg:"versions"`
 	AllowedURLs []AccessSpec `bson:"allowed_urls" json:"allowed_urls" msg:"allowed_urls"` // mapped string MUST be a valid regex
-	Limit       *APILimit    `json:"limit" msg:"limit"`
+	// DeniedURLs subtracts from AllowedURLs (or from otherwise unrestricted
+	// access, if AllowedURLs is empty): a path/method match here is always
+	// blocked, regardless of what any policy's AllowedURLs grants. This
+	// lets a broad base policy carve out exceptions instead of every
+	// policy having to enumerate the full set of allowed paths.
+	DeniedURLs []AccessSpec `bson:"denied_urls" json:"denied_urls" msg:"denied_urls"`
+	// AllowedGRPCMethods whitelists individual gRPC methods for APIs with
+	// Protocol "grpc", in "package.Service/Method" form (as found on the
+	// HTTP/2 ":path" pseudo-header, which net/http surfaces as
+	// r.URL.Path). "package.Service/*" whitelists every method on that
+	// service. Empty means no gRPC-specific restriction is applied - access
+	// is governed by Versions alone, same as for a regular HTTP API.
+	AllowedGRPCMethods []string  `bson:"allowed_grpc_methods" json:"allowed_grpc_methods" msg:"allowed_grpc_methods"`
+	Limit              *APILimit `json:"limit" msg:"limit"`
 
 	AllowanceScope string `json:"allowance_scope" msg:"allowance_scope"`
 }
@@ -53,24 +75,25 @@ type AccessDefinition struct {
 //
 // swagger:model
 type SessionState struct {
-	LastCheck          int64                       `json:"last_check" msg:"last_check"`
-	Allowance          float64                     `json:"allowance" msg:"allowance"`
-	Rate               float64                     `json:"rate" msg:"rate"`
-	Per                float64                     `json:"per" msg:"per"`
-	ThrottleInterval   float64                     `json:"throttle_interval" msg:"throttle_interval"`
-	ThrottleRetryLimit int                         `json:"throttle_retry_limit" msg:"throttle_retry_limit"`
-	DateCreated        time.Time                   `json:"date_created" msg:"date_created"`
-	Expires            int64                       `json:"expires" msg:"expires"`
-	QuotaMax           int64                       `json:"quota_max" msg:"quota_max"`
-	QuotaRenews        int64                       `json:"quota_renews" msg:"quota_renews"`
-	QuotaRemaining     int64                       `json:"quota_remaining" msg:"quota_remaining"`
-	QuotaRenewalRate   int64                       `json:"quota_renewal_rate" msg:"quota_renewal_rate"`
-	AccessRights       map[string]AccessDefinition `json:"access_rights" msg:"access_rights"`
-	OrgID              string                      `json:"org_id" msg:"org_id"`
-	OauthClientID      string                      `json:"oauth_client_id" msg:"oauth_client_id"`
-	OauthKeys          map[string]string           `json:"oauth_keys" msg:"oauth_keys"`
-	Certificate        string                      `json:"certificate" msg:"certificate"`
-	BasicAuthData      struct {
+	LastCheck            int64                       `json:"last_check" msg:"last_check"`
+	Allowance            float64                     `json:"allowance" msg:"allowance"`
+	Rate                 float64                     `json:"rate" msg:"rate"`
+	Per                  float64                     `json:"per" msg:"per"`
+	ThrottleInterval     float64                     `json:"throttle_interval" msg:"throttle_interval"`
+	ThrottleRetryLimit   int                         `json:"throttle_retry_limit" msg:"throttle_retry_limit"`
+	DateCreated          time.Time                   `json:"date_created" msg:"date_created"`
+	Expires              int64                       `json:"expires" msg:"expires"`
+	QuotaMax             int64                       `json:"quota_max" msg:"quota_max"`
+	QuotaRenews          int64                       `json:"quota_renews" msg:"quota_renews"`
+	QuotaRemaining       int64                       `json:"quota_remaining" msg:"quota_remaining"`
+	QuotaRenewalRate     int64                       `json:"quota_renewal_rate" msg:"quota_renewal_rate"`
+	QuotaCalendarAligned bool                        `json:"quota_calendar_aligned" msg:"quota_calendar_aligned"`
+	AccessRights         map[string]AccessDefinition `json:"access_rights" msg:"access_rights"`
+	OrgID                string                      `json:"org_id" msg:"org_id"`
+	OauthClientID        string                      `json:"oauth_client_id" msg:"oauth_client_id"`
+	OauthKeys            map[string]string           `json:"oauth_keys" msg:"oauth_keys"`
+	Certificate          string                      `json:"certificate" msg:"certificate"`
+	BasicAuthData        struct {
 		Password string   `json:"password" msg:"password"`
 		Hash     HashType `json:"hash_type" msg:"hash_type"`
 	} `json:"basic_auth_data" msg:"basic_auth_data"`
@@ -93,11 +116,55 @@ type SessionState struct {
 	LastUpdated             string                 `json:"last_updated" msg:"last_updated"`
 	IdExtractorDeadline     int64                  `json:"id_extractor_deadline" msg:"id_extractor_deadline"`
 	SessionLifetime         int64                  `bson:"session_lifetime" json:"session_lifetime"`
+	// IdleTimeout, when set, slides Expires forward by this many seconds
+	// on every successful request, instead of Expires being fixed at
+	// creation time. Capped by MaxLifetime.
+	IdleTimeout int64 `json:"idle_timeout" msg:"idle_timeout"`
+	// MaxLifetime is the maximum number of seconds after DateCreated that
+	// IdleTimeout is allowed to extend Expires to. 0 means no cap.
+	MaxLifetime int64 `json:"max_lifetime" msg:"max_lifetime"`
+
+	// Priority is this session's QoS class, populated from the highest
+	// Priority among its applied policies. The gateway's load shedding
+	// uses it to decide which requests keep flowing when an upstream
+	// signals distress.
+	Priority int `json:"priority" msg:"priority"`
+
+	// SchemaVersion is the version of the SessionState shape this session
+	// was last written with. It defaults to 0 for every session stored
+	// before this field existed, and is brought up to
+	// CurrentSessionSchemaVersion by Migrate.
+	SchemaVersion int `json:"schema_version" msg:"schema_version"`
 
 	// Used to store token hash
 	keyHash string
 }
 
+// CurrentSessionSchemaVersion is the SessionState schema version written by
+// this build. Bump it and add a case to Migrate whenever a change to
+// SessionState's fields needs one-time normalisation of sessions that were
+// stored by an older version of the gateway.
+const CurrentSessionSchemaVersion = 1
+
+// Migrate brings a session up to CurrentSessionSchemaVersion in place and
+// reports whether anything changed. It is called wherever a session is read
+// back out of storage, so a gateway upgrade that changes SessionState's
+// fields normalises old sessions transparently on their next read instead
+// of leaving them in a stale or inconsistent shape.
+func (s *SessionState) Migrate() bool {
+	migrated := false
+
+	if s.SchemaVersion < 1 {
+		// Versions before 1 predate QuotaOverageAllowance; its zero
+		// value is already the correct "no overage" default, so
+		// nothing but the version marker itself needs to change.
+		s.SchemaVersion = 1
+		migrated = true
+	}
+
+	return migrated
+}
+
 func (s *SessionState) MD5Hash() string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%+v", s))))
 }
@@ -131,6 +198,30 @@ func (s *SessionState) Lifetime(fallback int64) int64 {
 	return 0
 }
 
+// SlideExpiry extends Expires by IdleTimeout seconds from now, capped at
+// MaxLifetime seconds after DateCreated, and reports whether Expires was
+// changed. It is a no-op if IdleTimeout isn't set, so hard-expiring keys
+// are unaffected.
+func (s *SessionState) SlideExpiry() bool {
+	if s.IdleTimeout <= 0 {
+		return false
+	}
+
+	newExpires := time.Now().Unix() + s.IdleTimeout
+	if s.MaxLifetime > 0 {
+		if maxExpires := s.DateCreated.Unix() + s.MaxLifetime; newExpires > maxExpires {
+			newExpires = maxExpires
+		}
+	}
+
+	if newExpires <= s.Expires {
+		return false
+	}
+
+	s.Expires = newExpires
+	return true
+}
+
 // PolicyIDs returns the IDs of all the policies applied to this
 // session. For backwards compatibility reasons, this falls back to
 // ApplyPolicyID if ApplyPolicies is empty.